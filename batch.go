@@ -0,0 +1,199 @@
+package bn
+
+import (
+	"context"
+
+	"github.com/libsv/go-bc"
+	"github.com/libsv/go-bn/models"
+	"github.com/libsv/go-bn/rpc"
+	"github.com/libsv/go-bt/v2"
+)
+
+// BatchClient interfaces sending many calls to a bitcoin node in a single request.
+type BatchClient interface {
+	Batch(ctx context.Context, b *Batch) error
+}
+
+// NewBatchClient returns a client only capable of sending batched calls to a bitcoin node.
+func NewBatchClient(oo ...BitcoinClientOptFunc) BatchClient {
+	return NewNodeClient(oo...)
+}
+
+// Batch a collection of calls to be sent to the node in a single JSON-RPC request.
+//
+// Each queued call returns a handle, whose result is available once the batch
+// has been sent:
+//
+//	b := bn.NewBatch()
+//	txCall := b.RawTransaction(txID)
+//	outCall := b.Output(txID, 0, nil)
+//	if err := c.Batch(ctx, b); err != nil {}
+//	tx, err := txCall.Result()
+type Batch struct {
+	calls []*rpc.Call
+}
+
+// NewBatch returns an empty batch.
+func NewBatch() *Batch {
+	return &Batch{}
+}
+
+// Len the number of calls queued in the batch.
+func (b *Batch) Len() int {
+	return len(b.calls)
+}
+
+// BatchCall a call queued within a batch.
+type BatchCall struct {
+	call *rpc.Call
+}
+
+// Err the error returned by the node for this call, if any.
+func (b *BatchCall) Err() error {
+	return b.call.Err
+}
+
+// BatchString a batched call with a string result.
+type BatchString struct {
+	BatchCall
+	resp string
+}
+
+// Result the result of the call.
+func (b *BatchString) Result() (string, error) {
+	return b.resp, b.call.Err
+}
+
+// BatchTx a batched call with a transaction result.
+type BatchTx struct {
+	BatchCall
+	resp bt.Tx
+}
+
+// Result the result of the call.
+func (b *BatchTx) Result() (*bt.Tx, error) {
+	return &b.resp, b.call.Err
+}
+
+// BatchOutput a batched call with an output result.
+type BatchOutput struct {
+	BatchCall
+	resp models.Output
+}
+
+// Result the result of the call.
+func (b *BatchOutput) Result() (*models.Output, error) {
+	return &b.resp, b.call.Err
+}
+
+// BatchBlock a batched call with a block result.
+type BatchBlock struct {
+	BatchCall
+	resp models.Block
+}
+
+// Result the result of the call.
+func (b *BatchBlock) Result() (*models.Block, error) {
+	return &b.resp, b.call.Err
+}
+
+// BatchBlockHeader a batched call with a block header result.
+type BatchBlockHeader struct {
+	BatchCall
+	resp models.BlockHeader
+}
+
+// Result the result of the call.
+func (b *BatchBlockHeader) Result() (*models.BlockHeader, error) {
+	return &b.resp, b.call.Err
+}
+
+// BatchMempoolEntry a batched call with a mempool entry result.
+type BatchMempoolEntry struct {
+	BatchCall
+	resp models.MempoolEntry
+}
+
+// Result the result of the call.
+func (b *BatchMempoolEntry) Result() (*models.MempoolEntry, error) {
+	return &b.resp, b.call.Err
+}
+
+// Call queue a call to an arbitrary method, decoding its result into out.
+func (b *Batch) Call(method string, out interface{}, args ...interface{}) *BatchCall {
+	return &BatchCall{call: b.add(method, out, args...)}
+}
+
+// BestBlockHash queue a `getbestblockhash` call.
+func (b *Batch) BestBlockHash() *BatchString {
+	var r BatchString
+	r.call = b.add("getbestblockhash", &r.resp)
+	return &r
+}
+
+// BlockHash queue a `getblockhash` call.
+func (b *Batch) BlockHash(height int) *BatchString {
+	var r BatchString
+	r.call = b.add("getblockhash", &r.resp, height)
+	return &r
+}
+
+// BlockHex queue a `getblock` call, returning the raw block hex.
+func (b *Batch) BlockHex(hash string) *BatchString {
+	var r BatchString
+	r.call = b.add("getblock", &r.resp, hash, models.VerbosityRawBlock)
+	return &r
+}
+
+// Block queue a `getblock` call, returning the decoded block.
+func (b *Batch) Block(hash string) *BatchBlock {
+	r := BatchBlock{resp: models.Block{BlockHeader: models.BlockHeader{BlockHeader: &bc.BlockHeader{}}}}
+	r.call = b.add("getblock", &r.resp, hash, models.VerbosityDecodeTransactions)
+	return &r
+}
+
+// BlockHeader queue a `getblockheader` call.
+func (b *Batch) BlockHeader(hash string) *BatchBlockHeader {
+	r := BatchBlockHeader{resp: models.BlockHeader{BlockHeader: &bc.BlockHeader{}}}
+	r.call = b.add("getblockheader", &r.resp, hash, true)
+	return &r
+}
+
+// MempoolEntry queue a `getmempoolentry` call.
+func (b *Batch) MempoolEntry(txID string) *BatchMempoolEntry {
+	var r BatchMempoolEntry
+	r.call = b.add("getmempoolentry", &r.resp, txID)
+	return &r
+}
+
+// RawTransaction queue a `getrawtransaction` call.
+func (b *Batch) RawTransaction(txID string) *BatchTx {
+	var r BatchTx
+	r.call = b.add("getrawtransaction", &r.resp, txID, true)
+	return &r
+}
+
+// Output queue a `gettxout` call.
+func (b *Batch) Output(txID string, n int, opts *models.OptsOutput) *BatchOutput {
+	r := BatchOutput{resp: models.Output{Output: &bt.Output{}}}
+	args := []interface{}{txID, n}
+	if opts != nil {
+		args = append(args, opts.Args()...)
+	}
+	r.call = b.add("gettxout", &r.resp, args...)
+	return &r
+}
+
+func (b *Batch) add(method string, out interface{}, args ...interface{}) *rpc.Call {
+	c := &rpc.Call{
+		Method: method,
+		Args:   args,
+		Out:    out,
+	}
+	b.calls = append(b.calls, c)
+	return c
+}
+
+func (c *client) Batch(ctx context.Context, b *Batch) error {
+	return rpc.DoBatch(ctx, c.rpc, b.calls...)
+}
@@ -0,0 +1,73 @@
+package bn_test
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/libsv/go-bn"
+	"github.com/libsv/go-bn/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBatchClient_Batch(t *testing.T) {
+	t.Parallel()
+
+	rawTx, err := ioutil.ReadFile("./testing/data/getrawtx.json")
+	assert.NoError(t, err)
+	var rawTxResp struct {
+		Result json.RawMessage `json:"result"`
+	}
+	assert.NoError(t, json.Unmarshal(rawTx, &rawTxResp))
+
+	svr := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var reqs []models.Request
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&reqs))
+		assert.Equal(t, 3, len(reqs))
+		assert.Equal(t, "getrawtransaction", reqs[0].Method)
+		assert.Equal(t, "getblockhash", reqs[1].Method)
+		assert.Equal(t, "getrawtransaction", reqs[2].Method)
+
+		resp := []map[string]interface{}{{
+			"id":     reqs[0].ID,
+			"result": rawTxResp.Result,
+		}, {
+			"id":     reqs[1].ID,
+			"result": "00000000000000000a36c5ba8b39d82e3f0c3f7e43a1fc3b73f1be5e46ab3b21",
+		}, {
+			"id":     reqs[2].ID,
+			"result": nil,
+			"error": map[string]interface{}{
+				"code":    -5,
+				"message": "No such mempool or blockchain transaction.",
+			},
+		}}
+		assert.NoError(t, json.NewEncoder(w).Encode(resp))
+	}))
+	defer svr.Close()
+
+	c := bn.NewNodeClient(bn.WithHost(svr.URL))
+
+	b := bn.NewBatch()
+	txCall := b.RawTransaction("c98f2b1187c569d98e32f69cff4f09c8548208b0281661742f68af3ac877b8fb")
+	hashCall := b.BlockHash(100)
+	missingCall := b.RawTransaction("a98f2b1187c569d98e32f69cff4f09c8548208b0281661742f68af3ac877b8fa")
+	assert.Equal(t, 3, b.Len())
+
+	assert.NoError(t, c.Batch(context.TODO(), b))
+
+	tx, err := txCall.Result()
+	assert.NoError(t, err)
+	assert.Equal(t, "c98f2b1187c569d98e32f69cff4f09c8548208b0281661742f68af3ac877b8fb", tx.TxID())
+
+	hash, err := hashCall.Result()
+	assert.NoError(t, err)
+	assert.Equal(t, "00000000000000000a36c5ba8b39d82e3f0c3f7e43a1fc3b73f1be5e46ab3b21", hash)
+
+	_, err = missingCall.Result()
+	assert.EqualError(t, err, "-5: No such mempool or blockchain transaction.")
+	assert.Equal(t, err, missingCall.Err())
+}
@@ -17,6 +17,7 @@ type BlockChainClient interface {
 	BlockDecodeHeaderByHeight(ctx context.Context, height int) (*models.BlockDecodeHeader, error)
 	Block(ctx context.Context, hash string) (*models.Block, error)
 	BlockByHeight(ctx context.Context, height int) (*models.Block, error)
+	BlockStream(ctx context.Context, hash string) *BlockStream
 	ChainInfo(ctx context.Context) (*models.ChainInfo, error)
 	BlockCount(ctx context.Context) (uint32, error)
 	BlockHash(ctx context.Context, height int) (string, error)
@@ -30,6 +31,7 @@ type BlockChainClient interface {
 	MerkleProof(ctx context.Context, blockHash, txID string, opts *models.OptsMerkleProof) (*bc.MerkleProof, error)
 	LegacyMerkleProof(ctx context.Context, txID string,
 		opts *models.OptsLegacyMerkleProof) (*models.LegacyMerkleProof, error)
+	VerifyMerkleProof(ctx context.Context, proof *bc.MerkleProof, txID string) error
 	RawMempool(ctx context.Context) (models.MempoolTxs, error)
 	RawMempoolIDs(ctx context.Context) ([]string, error)
 	RawNonFinalMempool(ctx context.Context) ([]string, error)
@@ -38,7 +40,10 @@ type BlockChainClient interface {
 	MempoolAncestorIDs(ctx context.Context, txID string) ([]string, error)
 	MempoolDescendants(ctx context.Context, txID string) (models.MempoolTxs, error)
 	MempoolDescendantIDs(ctx context.Context, txID string) ([]string, error)
+	MempoolGraph(ctx context.Context, txID string) (*MempoolGraph, error)
 	Output(ctx context.Context, txID string, n int, opts *models.OptsOutput) (*models.Output, error)
+	CheckOutpoints(ctx context.Context, outpoints []models.Outpoint,
+		includeMempool bool) ([]*models.OutpointCheck, error)
 	OutputSetInfo(ctx context.Context) (*models.OutputSetInfo, error)
 	PreciousBlock(ctx context.Context, blockHash string) error
 	PruneChain(ctx context.Context, height int) (uint32, error)
@@ -187,7 +192,7 @@ func (c *client) MerkleProof(ctx context.Context, blockHash, txID string,
 
 func (c *client) LegacyMerkleProof(ctx context.Context, txID string,
 	opts *models.OptsLegacyMerkleProof) (*models.LegacyMerkleProof, error) {
-	var resp models.LegacyMerkleProof
+	resp := models.LegacyMerkleProof{Target: models.BlockHeader{BlockHeader: &bc.BlockHeader{}}}
 	return &resp, c.rpc.Do(ctx, "getmerkleproof", &resp, c.argsFor(opts, txID)...)
 }
 
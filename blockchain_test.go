@@ -1 +1,84 @@
-package bn
+package bn_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/libsv/go-bn"
+	"github.com/libsv/go-bn/models"
+	"github.com/libsv/go-bn/testing/util"
+	"github.com/libsv/go-bt/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBlockChainClient_BlockHeader(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		testFile         string
+		hash             string
+		expRequest       models.Request
+		expConfirmations int64
+		expNextBlockHash string
+	}{
+		"active block": {
+			testFile:         "getblockheader",
+			hash:             "0000000000000000000000000000000000000000000000000000000000000001",
+			expConfirmations: 3,
+			expNextBlockHash: "0000000000000000000000000000000000000000000000000000000000000002",
+			expRequest: models.Request{
+				ID:      "go-bn",
+				JSONRpc: "1.0",
+				Method:  "getblockheader",
+				Params:  []interface{}{"0000000000000000000000000000000000000000000000000000000000000001", true},
+			},
+		},
+		"stale block": {
+			testFile:         "getblockheader_stale",
+			hash:             "0000000000000000000000000000000000000000000000000000000000000003",
+			expConfirmations: -1,
+			expRequest: models.Request{
+				ID:      "go-bn",
+				JSONRpc: "1.0",
+				Method:  "getblockheader",
+				Params:  []interface{}{"0000000000000000000000000000000000000000000000000000000000000003", true},
+			},
+		},
+	}
+
+	for name, test := range tests {
+		test := test
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			svr, cls := util.TestServer(t, &test.expRequest, test.testFile)
+			defer cls()
+
+			c := bn.NewBlockChainClient(bn.WithHost(svr.URL))
+
+			header, err := c.BlockHeader(context.TODO(), test.hash)
+			assert.NoError(t, err)
+			assert.Equal(t, test.hash, header.Hash)
+			assert.Equal(t, test.expConfirmations, header.Confirmations)
+			assert.Equal(t, uint64(100), header.Height)
+			assert.Equal(t, test.expNextBlockHash, header.NextBlockHash)
+			assert.Equal(t, "1111111111111111111111111111111111111111111111111111111111111111", header.HashPrevBlockStr())
+		})
+	}
+}
+
+func TestBlockChainClient_Output_Spent(t *testing.T) {
+	t.Parallel()
+	svr, cls := util.TestServer(t, &models.Request{
+		ID:      "go-bn",
+		JSONRpc: "1.0",
+		Method:  "gettxout",
+		Params:  []interface{}{"c98f2b1187c569d98e32f69cff4f09c8548208b0281661742f68af3ac877b8fb", float64(0)},
+	}, "gettxout_spent")
+	defer cls()
+
+	c := bn.NewBlockChainClient(bn.WithHost(svr.URL))
+
+	out, err := c.Output(context.TODO(), "c98f2b1187c569d98e32f69cff4f09c8548208b0281661742f68af3ac877b8fb", 0, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, &models.Output{Output: &bt.Output{}}, out)
+}
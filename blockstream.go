@@ -0,0 +1,140 @@
+package bn
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"io"
+
+	"github.com/libsv/go-bc"
+	"github.com/libsv/go-bn/models"
+	"github.com/libsv/go-bn/rpc"
+	"github.com/libsv/go-bt/v2"
+)
+
+// blockStreamBufferSize the size of the buffer txs are read through.
+const blockStreamBufferSize = 64 * 1024
+
+// BlockStream iterates the txs of a block as they are read from the node, without
+// holding the whole block in memory:
+//
+//	s := c.BlockStream(ctx, hash)
+//	defer s.Close()
+//	for s.Next() {
+//		tx := s.Tx()
+//	}
+//	if err := s.Err(); err != nil {}
+//
+// The block is requested raw and decoded as it streams, so only one tx need be held
+// at a time.
+type BlockStream struct {
+	txs    chan *bt.Tx
+	cancel context.CancelFunc
+
+	header  *bc.BlockHeader
+	numTxs  uint64
+	tx      *bt.Tx
+	err     error
+	closed  bool
+	drained bool
+}
+
+func (c *client) BlockStream(ctx context.Context, hash string) *BlockStream {
+	ctx, cancel := context.WithCancel(ctx)
+	s := &BlockStream{
+		txs:    make(chan *bt.Tx),
+		cancel: cancel,
+	}
+
+	go func() {
+		defer close(s.txs)
+		s.err = rpc.DoStream(ctx, c.rpc, "getblock", func(r io.Reader) error {
+			return s.read(ctx, bufio.NewReaderSize(r, blockStreamBufferSize))
+		}, hash, models.VerbosityRawBlock)
+	}()
+
+	return s
+}
+
+func (s *BlockStream) read(ctx context.Context, r io.Reader) error {
+	hb := make([]byte, 80)
+	if _, err := io.ReadFull(r, hb); err != nil {
+		return err
+	}
+	header, err := bc.NewBlockHeaderFromBytes(hb)
+	if err != nil {
+		return err
+	}
+
+	var n bt.VarInt
+	if _, err = n.ReadFrom(r); err != nil {
+		return err
+	}
+	s.header = header
+	s.numTxs = uint64(n)
+
+	for i := uint64(0); i < s.numTxs; i++ {
+		tx := bt.NewTx()
+		if _, err = tx.ReadFrom(r); err != nil {
+			return err
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case s.txs <- tx:
+		}
+	}
+
+	return nil
+}
+
+// Next advance to the next tx, returning false once there are no more txs or the
+// stream has failed.
+func (s *BlockStream) Next() bool {
+	if s.drained {
+		return false
+	}
+	tx, ok := <-s.txs
+	if !ok {
+		s.drained = true
+		s.tx = nil
+		return false
+	}
+	s.tx = tx
+	return true
+}
+
+// Tx the current tx.
+func (s *BlockStream) Tx() *bt.Tx {
+	return s.tx
+}
+
+// Header the block's header, available once Next has been called.
+func (s *BlockStream) Header() *bc.BlockHeader {
+	return s.header
+}
+
+// NumTxs the number of txs in the block, available once Next has been called.
+func (s *BlockStream) NumTxs() uint64 {
+	return s.numTxs
+}
+
+// Err the error which ended the stream, if any, available once Next has returned false.
+func (s *BlockStream) Err() error {
+	if !s.drained {
+		return nil
+	}
+	if s.closed && errors.Is(s.err, context.Canceled) {
+		return nil
+	}
+	return s.err
+}
+
+// Close stop streaming the block, releasing the connection to the node.
+func (s *BlockStream) Close() {
+	s.closed = true
+	s.cancel()
+	for !s.drained {
+		s.Next()
+	}
+}
@@ -0,0 +1,102 @@
+package bn_test
+
+import (
+	"context"
+	"encoding/hex"
+	"errors"
+	"testing"
+
+	"github.com/libsv/go-bn"
+	"github.com/libsv/go-bn/models"
+	"github.com/libsv/go-bn/testing/util"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBlockChainClient_BlockStream(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		testFile   string
+		hash       string
+		take       int
+		expRequest models.Request
+		expHeader  string
+		expNumTxs  uint64
+		expTxIDs   []string
+		expErr     error
+	}{
+		"successful stream": {
+			testFile:  "getblock_raw",
+			hash:      "0000000000000000000000000000000000000000000000000000000000000001",
+			take:      -1,
+			expHeader: "010000001111111111111111111111111111111111111111111111111111111111111111222222222222222222222222222222222222222222222222222222222222222229ab5f49ffff001d1dac2b7c",
+			expNumTxs: 2,
+			expTxIDs: []string{
+				"c98f2b1187c569d98e32f69cff4f09c8548208b0281661742f68af3ac877b8fb",
+				"13603923fecfea75e1cea6c769c44ca7b1e19510018bb6a63f4bd6ddc9813379",
+			},
+			expRequest: models.Request{
+				ID:      "go-bn",
+				JSONRpc: "1.0",
+				Method:  "getblock",
+				Params:  []interface{}{"0000000000000000000000000000000000000000000000000000000000000001", "RAW_BLOCK"},
+			},
+		},
+		"stream closed early": {
+			testFile:  "getblock_raw",
+			hash:      "0000000000000000000000000000000000000000000000000000000000000001",
+			take:      1,
+			expHeader: "010000001111111111111111111111111111111111111111111111111111111111111111222222222222222222222222222222222222222222222222222222222222222229ab5f49ffff001d1dac2b7c",
+			expNumTxs: 2,
+			expTxIDs: []string{
+				"c98f2b1187c569d98e32f69cff4f09c8548208b0281661742f68af3ac877b8fb",
+			},
+			expRequest: models.Request{
+				ID:      "go-bn",
+				JSONRpc: "1.0",
+				Method:  "getblock",
+				Params:  []interface{}{"0000000000000000000000000000000000000000000000000000000000000001", "RAW_BLOCK"},
+			},
+		},
+		"error is reported": {
+			testFile: "getblock_notfound",
+			hash:     "0000000000000000000000000000000000000000000000000000000000000002",
+			take:     -1,
+			expRequest: models.Request{
+				ID:      "go-bn",
+				JSONRpc: "1.0",
+				Method:  "getblock",
+				Params:  []interface{}{"0000000000000000000000000000000000000000000000000000000000000002", "RAW_BLOCK"},
+			},
+			expErr: errors.New("-5: Block not found"),
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			svr, cls := util.TestServer(t, &test.expRequest, test.testFile)
+			defer cls()
+
+			c := bn.NewBlockChainClient(bn.WithHost(svr.URL))
+
+			s := c.BlockStream(context.TODO(), test.hash)
+			var txIDs []string
+			for (test.take < 0 || len(txIDs) < test.take) && s.Next() {
+				txIDs = append(txIDs, s.Tx().TxID())
+			}
+			s.Close()
+
+			if test.expErr != nil {
+				assert.Error(t, s.Err())
+				assert.EqualError(t, s.Err(), test.expErr.Error())
+				assert.True(t, errors.Is(s.Err(), models.ErrNotFound))
+				return
+			}
+
+			assert.NoError(t, s.Err())
+			assert.Equal(t, test.expHeader, hex.EncodeToString(s.Header().Bytes()))
+			assert.Equal(t, test.expNumTxs, s.NumTxs())
+			assert.Equal(t, test.expTxIDs, txIDs)
+		})
+	}
+}
@@ -0,0 +1,148 @@
+package bn
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/libsv/go-bn/models"
+)
+
+// BlockWalker fetches ranges of blocks by height, several at a time, delivering
+// them strictly in height order:
+//
+//	w := bn.NewBlockWalker(c, bn.WalkerWorkers(8))
+//	err := w.Walk(ctx, checkpoint+1, tip, func(b *models.Block) error {
+//		if err := index(b); err != nil {
+//			return err
+//		}
+//		checkpoint = int(b.Height)
+//		return nil
+//	})
+//
+// Should a walk stop early, every block below the one it stopped at has been
+// delivered and none above it, so the height of the last block delivered is a
+// checkpoint from which to resume.
+type BlockWalker struct {
+	c       BlockChainClient
+	workers int
+	window  int
+}
+
+// BlockWalkerOptFunc for setting block walker options.
+type BlockWalkerOptFunc func(w *BlockWalker)
+
+// WalkerWorkers set the number of blocks fetched at once. Defaults to 4.
+func WalkerWorkers(n int) BlockWalkerOptFunc {
+	return func(w *BlockWalker) {
+		w.workers = n
+	}
+}
+
+// WalkerWindow set the number of blocks fetched ahead of the block being delivered,
+// bounding those held in memory whilst delivery is slower than fetching. Defaults
+// to 4 per worker, and is never less than the number of workers.
+func WalkerWindow(n int) BlockWalkerOptFunc {
+	return func(w *BlockWalker) {
+		w.window = n
+	}
+}
+
+// NewBlockWalker returns a walker of the blocks of the node c is a client of.
+func NewBlockWalker(c BlockChainClient, oo ...BlockWalkerOptFunc) *BlockWalker {
+	w := &BlockWalker{c: c, workers: 4}
+	for _, o := range oo {
+		o(w)
+	}
+	if w.workers < 1 {
+		w.workers = 1
+	}
+	if w.window == 0 {
+		w.window = w.workers * 4
+	}
+	if w.window < w.workers {
+		w.window = w.workers
+	}
+	return w
+}
+
+type walkResult struct {
+	block *models.Block
+	err   error
+}
+
+type walkJob struct {
+	height int
+	result chan walkResult
+}
+
+// Walk deliver the blocks from height from to height to, inclusive, to fn in
+// height order. The walk stops at the first error fetching a block or returned
+// by fn, or once ctx is done, returning that error once all fetches have stopped.
+func (w *BlockWalker) Walk(ctx context.Context, from, to int, fn func(b *models.Block) error) error {
+	ctx, cancel := context.WithCancel(ctx)
+	var wg sync.WaitGroup
+	defer wg.Wait()
+	defer cancel()
+
+	jobs := make(chan walkJob)
+	pending := make(chan chan walkResult, w.window)
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		defer close(pending)
+		defer close(jobs)
+		for h := from; h <= to; h++ {
+			job := walkJob{height: h, result: make(chan walkResult, 1)}
+			select {
+			case pending <- job.result:
+			case <-ctx.Done():
+				return
+			}
+			select {
+			case jobs <- job:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	for i := 0; i < w.workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for job := range jobs {
+				b, err := w.fetch(ctx, job.height)
+				job.result <- walkResult{block: b, err: err}
+			}
+		}()
+	}
+
+	for result := range pending {
+		var r walkResult
+		select {
+		case r = <-result:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+		if r.err != nil {
+			return r.err
+		}
+		if err := fn(r.block); err != nil {
+			return err
+		}
+	}
+	return ctx.Err()
+}
+
+func (w *BlockWalker) fetch(ctx context.Context, height int) (*models.Block, error) {
+	hash, err := w.c.BlockHash(ctx, height)
+	if err != nil {
+		return nil, fmt.Errorf("block %d: %w", height, err)
+	}
+	b, err := w.c.Block(ctx, hash)
+	if err != nil {
+		return nil, fmt.Errorf("block %d: %w", height, err)
+	}
+	return b, nil
+}
@@ -0,0 +1,167 @@
+package bn_test
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/libsv/go-bn"
+	bnmocks "github.com/libsv/go-bn/mocks"
+	"github.com/libsv/go-bn/models"
+	"github.com/stretchr/testify/assert"
+)
+
+// walkerClient a client of a chain of blocks, each fetched after a random delay.
+func walkerClient(failAt int) (*bnmocks.BlockChainClientMock, func() int) {
+	var mu sync.Mutex
+	var inFlight, maxInFlight int
+	c := &bnmocks.BlockChainClientMock{
+		BlockHashFunc: func(ctx context.Context, height int) (string, error) {
+			if height == failAt {
+				return "", &models.Error{Code: models.ErrInvalidParameter.Code, Message: "Block height out of range"}
+			}
+			return strconv.Itoa(height), nil
+		},
+		BlockFunc: func(ctx context.Context, hash string) (*models.Block, error) {
+			mu.Lock()
+			inFlight++
+			if inFlight > maxInFlight {
+				maxInFlight = inFlight
+			}
+			mu.Unlock()
+			defer func() {
+				mu.Lock()
+				inFlight--
+				mu.Unlock()
+			}()
+
+			time.Sleep(time.Duration(rand.Intn(500)) * time.Microsecond) // nolint:gosec // test jitter
+			height, _ := strconv.Atoi(hash)
+			return &models.Block{BlockHeader: models.BlockHeader{Hash: hash, Height: uint64(height)}}, nil
+		},
+	}
+	return c, func() int {
+		mu.Lock()
+		defer mu.Unlock()
+		return maxInFlight
+	}
+}
+
+func TestBlockWalker_Walk(t *testing.T) {
+	t.Parallel()
+	errStop := errors.New("stop")
+	tests := map[string]struct {
+		from      int
+		to        int
+		workers   int
+		failAt    int
+		stopAt    int
+		expHeight []int
+		expErr    error
+	}{
+		"blocks are delivered in order": {
+			from:      0,
+			to:        99,
+			workers:   8,
+			failAt:    -1,
+			stopAt:    -1,
+			expHeight: heights(0, 99),
+		},
+		"walk resumes from a checkpoint": {
+			from:      50,
+			to:        99,
+			workers:   8,
+			failAt:    -1,
+			stopAt:    -1,
+			expHeight: heights(50, 99),
+		},
+		"single worker": {
+			from:      0,
+			to:        9,
+			workers:   1,
+			failAt:    -1,
+			stopAt:    -1,
+			expHeight: heights(0, 9),
+		},
+		"fetch error stops the walk": {
+			from:      0,
+			to:        99,
+			workers:   8,
+			failAt:    40,
+			stopAt:    -1,
+			expHeight: heights(0, 39),
+			expErr:    models.ErrInvalidParameter,
+		},
+		"callback error stops the walk": {
+			from:      0,
+			to:        99,
+			workers:   8,
+			failAt:    -1,
+			stopAt:    20,
+			expHeight: heights(0, 20),
+			expErr:    errStop,
+		},
+		"empty range": {
+			from:   10,
+			to:     9,
+			failAt: -1,
+			stopAt: -1,
+		},
+	}
+
+	for name, test := range tests {
+		test := test
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			c, maxInFlight := walkerClient(test.failAt)
+
+			var delivered []int
+			err := bn.NewBlockWalker(c, bn.WalkerWorkers(test.workers)).Walk(context.Background(), test.from, test.to,
+				func(b *models.Block) error {
+					delivered = append(delivered, int(b.Height))
+					if int(b.Height) == test.stopAt {
+						return errStop
+					}
+					return nil
+				})
+			if test.expErr != nil {
+				assert.True(t, errors.Is(err, test.expErr), err)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, test.expHeight, delivered)
+			assert.LessOrEqual(t, maxInFlight(), test.workers)
+		})
+	}
+}
+
+func TestBlockWalker_Walk_Cancel(t *testing.T) {
+	t.Parallel()
+	c, _ := walkerClient(-1)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var delivered int
+	err := bn.NewBlockWalker(c).Walk(ctx, 0, 1000, func(b *models.Block) error {
+		assert.Equal(t, uint64(delivered), b.Height)
+		delivered++
+		if delivered == 10 {
+			cancel()
+		}
+		return nil
+	})
+	assert.True(t, errors.Is(err, context.Canceled))
+	assert.Less(t, delivered, 1000)
+}
+
+func heights(from, to int) []int {
+	hh := make([]int, 0, to-from+1)
+	for h := from; h <= to; h++ {
+		hh = append(hh, h)
+	}
+	return hh
+}
@@ -0,0 +1,74 @@
+// Package cassette records the rpc calls made by a node client to a JSONL cassette,
+// and replays them without a node, allowing regression suites to be built from a
+// real session:
+//
+//	f, _ := os.Create("testdata/session.jsonl")
+//	rec := cassette.NewRecorder(f)
+//	c := bn.NewNodeClient(bn.WithHost(host), bn.WithMiddleware(rec.Middleware))
+//
+// and later:
+//
+//	f, _ := os.Open("testdata/session.jsonl")
+//	r, _ := cassette.NewReplayer(f, cassette.MatchExact)
+//	c := bn.NewNodeClient(bn.WithCustomRPC(r))
+package cassette
+
+import (
+	"encoding/json"
+	"errors"
+
+	"github.com/libsv/go-bn/models"
+)
+
+// Interaction a recorded call and the node's response.
+type Interaction struct {
+	Method string          `json:"method"`
+	Args   json.RawMessage `json:"args"`
+	Result json.RawMessage `json:"result,omitempty"`
+	// Error the error returned by the node, if any.
+	Error *models.Error `json:"error,omitempty"`
+	// Status the http status the node responded with, should it have responded
+	// with no JSON-RPC error.
+	Status *models.StatusError `json:"status,omitempty"`
+	// Failure the message of the error should it be more than the node error or
+	// status, such as it having been wrapped, or should the call have failed for any
+	// other reason, such as the node being unreachable.
+	Failure string `json:"failure,omitempty"`
+}
+
+// err the error the call failed with, nil should it have succeeded. Node errors and
+// statuses are rebuilt, so may be matched with errors.Is and errors.As as they were
+// when recorded.
+func (i *Interaction) err() error {
+	var err error
+	switch {
+	case i.Error != nil:
+		e := *i.Error
+		err = &e
+	case i.Status != nil:
+		e := *i.Status
+		err = &e
+	}
+	switch {
+	case i.Failure == "":
+		return err
+	case err == nil:
+		return errors.New(i.Failure)
+	}
+	return &failure{msg: i.Failure, err: err}
+}
+
+// failure a recorded error wrapping the node error or status it was caused by.
+type failure struct {
+	msg string
+	err error
+}
+
+func (f *failure) Error() string {
+	return f.msg
+}
+
+// Unwrap the node error or status.
+func (f *failure) Unwrap() error {
+	return f.err
+}
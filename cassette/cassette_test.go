@@ -0,0 +1,195 @@
+package cassette_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/libsv/go-bn"
+	"github.com/libsv/go-bn/cassette"
+	"github.com/libsv/go-bn/models"
+	"github.com/libsv/go-bn/rpc"
+	"github.com/stretchr/testify/assert"
+)
+
+const rawTx = "0200000001c9059cca32a90834a9ea6e989446edb4282e91bba486f4512477052214b185df0000000048473044022056e7348677c69dbcba776fbe0c270116c2a3eaf0bead0c1ccdbd9c083b73a08e022062da00341e54a28bb83b28dfd772c9504f5aace3452e762dc30dff249a378c0a41feffffff0240101024010000001976a914316230517501a16e2837465ec28c157fa61cabec88ac00e1f505000000001976a914beb20631d5271a6e150231e625bccff55a58cbea88ac70000000" // nolint:lll // test data
+
+func record(t *testing.T) string {
+	svr := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req models.Request
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+
+		resp := map[string]interface{}{"id": req.ID, "error": nil}
+		switch req.Method {
+		case "getblockcount":
+			resp["result"] = 101
+		case "getblockhash":
+			resp["result"] = strings.Repeat("0", 63) + "1"
+		case "getrawtransaction":
+			if req.Params[0] == "missing" {
+				resp["result"] = nil
+				resp["error"] = models.Error{Code: -5, Message: "No such mempool or blockchain transaction"}
+				w.WriteHeader(http.StatusInternalServerError)
+				break
+			}
+			resp["result"] = map[string]interface{}{"hex": rawTx}
+		}
+		assert.NoError(t, json.NewEncoder(w).Encode(resp))
+	}))
+	defer svr.Close()
+
+	var buf bytes.Buffer
+	rec := cassette.NewRecorder(&buf)
+	c := bn.NewNodeClient(bn.WithHost(svr.URL), bn.WithMiddleware(rec.Middleware))
+
+	ctx := context.TODO()
+	_, err := c.BlockCount(ctx)
+	assert.NoError(t, err)
+	_, err = c.BlockHash(ctx, 1)
+	assert.NoError(t, err)
+	_, err = c.RawTransaction(ctx, "missing")
+	assert.Error(t, err)
+	_, err = c.RawTransaction(ctx, "c98f2b1187c569d98e32f69cff4f09c8548208b0281661742f68af3ac877b8fb")
+	assert.NoError(t, err)
+	assert.NoError(t, rec.Err())
+
+	return buf.String()
+}
+
+func TestReplayer_Do(t *testing.T) {
+	t.Parallel()
+
+	tape := record(t)
+
+	tests := map[string]struct {
+		match     cassette.Match
+		play      func(ctx context.Context, c bn.NodeClient) error
+		expErr    error
+		expPlayed int
+	}{
+		"exact replays all in any order": {
+			match: cassette.MatchExact,
+			play: func(ctx context.Context, c bn.NodeClient) error {
+				tx, err := c.RawTransaction(ctx, "c98f2b1187c569d98e32f69cff4f09c8548208b0281661742f68af3ac877b8fb")
+				if err != nil {
+					return err
+				}
+				assert.Equal(t, rawTx, tx.String())
+				_, err = c.RawTransaction(ctx, "missing")
+				assert.True(t, errors.Is(err, models.ErrNotFound))
+				n, err := c.BlockCount(ctx)
+				assert.Equal(t, uint32(101), n)
+				return err
+			},
+			expPlayed: 3,
+		},
+		"exact fails on differing args": {
+			match: cassette.MatchExact,
+			play: func(ctx context.Context, c bn.NodeClient) error {
+				_, err := c.BlockHash(ctx, 2)
+				return err
+			},
+			expErr: cassette.ErrNoInteraction,
+		},
+		"method ignores args": {
+			match: cassette.MatchMethod,
+			play: func(ctx context.Context, c bn.NodeClient) error {
+				hash, err := c.BlockHash(ctx, 2)
+				assert.Equal(t, strings.Repeat("0", 63)+"1", hash)
+				return err
+			},
+			expPlayed: 1,
+		},
+		"each interaction plays once": {
+			match: cassette.MatchMethod,
+			play: func(ctx context.Context, c bn.NodeClient) error {
+				if _, err := c.BlockCount(ctx); err != nil {
+					return err
+				}
+				_, err := c.BlockCount(ctx)
+				return err
+			},
+			expErr: cassette.ErrNoInteraction,
+		},
+		"sequence plays in order": {
+			match: cassette.MatchSequence,
+			play: func(ctx context.Context, c bn.NodeClient) error {
+				if _, err := c.BlockCount(ctx); err != nil {
+					return err
+				}
+				_, err := c.BlockHash(ctx, 7)
+				return err
+			},
+			expPlayed: 2,
+		},
+		"sequence fails out of order": {
+			match: cassette.MatchSequence,
+			play: func(ctx context.Context, c bn.NodeClient) error {
+				_, err := c.BlockHash(ctx, 1)
+				return err
+			},
+			expErr: cassette.ErrNoInteraction,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			r, err := cassette.NewReplayer(strings.NewReader(tape), test.match)
+			assert.NoError(t, err)
+
+			err = test.play(context.TODO(), bn.NewNodeClient(bn.WithCustomRPC(r)))
+			if test.expErr != nil {
+				assert.True(t, errors.Is(err, test.expErr), err)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Len(t, r.Unplayed(), 4-test.expPlayed)
+		})
+	}
+}
+
+func TestReplayer_Do_Errors(t *testing.T) {
+	t.Parallel()
+
+	errs := map[string]error{
+		"getblock":      &models.Error{Code: -5, Message: "Block not found"},
+		"getblockcount": fmt.Errorf("reading height: %w", &models.Error{Code: -28, Message: "Loading block index..."}),
+		"getinfo":       &models.StatusError{StatusCode: http.StatusServiceUnavailable, Body: "Work queue depth exceeded"},
+		"getpeerinfo":   errors.New("connection refused"),
+	}
+
+	var buf bytes.Buffer
+	rec := cassette.NewRecorder(&buf)
+	next := rec.Middleware(rpc.Func(func(ctx context.Context, method string, out interface{}, args ...interface{}) error {
+		return errs[method]
+	}))
+	for method := range errs {
+		assert.Error(t, next.Do(context.TODO(), method, nil))
+	}
+	assert.NoError(t, rec.Err())
+
+	r, err := cassette.NewReplayer(&buf, cassette.MatchMethod)
+	assert.NoError(t, err)
+	for method, expErr := range errs {
+		err := r.Do(context.TODO(), method, nil)
+		assert.EqualError(t, err, expErr.Error())
+
+		var nodeErr *models.Error
+		if errors.As(expErr, &nodeErr) {
+			assert.True(t, errors.Is(err, nodeErr))
+			assert.True(t, errors.As(err, new(*models.Error)))
+		}
+		var statusErr *models.StatusError
+		if errors.As(expErr, &statusErr) {
+			assert.True(t, errors.As(err, &statusErr))
+			assert.Equal(t, http.StatusServiceUnavailable, statusErr.StatusCode)
+		}
+	}
+}
@@ -0,0 +1,124 @@
+package cassette
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"sync"
+
+	"github.com/libsv/go-bn/internal/service"
+	"github.com/libsv/go-bn/models"
+	"github.com/libsv/go-bn/rpc"
+)
+
+// Recorder records each call passing through it as a line of JSON.
+//
+// The RPC wrapped must decode results as JSON, as the http transport does, since
+// the raw result is requested from it in order to be recorded.
+type Recorder struct {
+	mu  sync.Mutex
+	enc *json.Encoder
+	err error
+}
+
+// NewRecorder returns a recorder writing interactions to w.
+func NewRecorder(w io.Writer) *Recorder {
+	return &Recorder{enc: json.NewEncoder(w)}
+}
+
+// Err the first error met writing to the cassette, if any.
+func (r *Recorder) Err() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.err
+}
+
+type recorder struct {
+	r    *Recorder
+	next rpc.RPC
+}
+
+// Middleware an rpc.Middleware recording each call passing through it.
+func (r *Recorder) Middleware(next rpc.RPC) rpc.RPC {
+	return &recorder{r: r, next: next}
+}
+
+// Do the call, recording its result.
+func (r *recorder) Do(ctx context.Context, method string, out interface{}, args ...interface{}) error {
+	var raw json.RawMessage
+	err := r.next.Do(ctx, method, &raw, args...)
+	r.r.record(method, args, raw, err)
+	if err != nil {
+		return err
+	}
+
+	return service.DecodeResult(raw, out)
+}
+
+// DoBatch the calls as a batch, recording each.
+func (r *recorder) DoBatch(ctx context.Context, calls ...*rpc.Call) error {
+	raws := make([]json.RawMessage, len(calls))
+	outs := make([]interface{}, len(calls))
+	for i, c := range calls {
+		outs[i] = c.Out
+		c.Out = &raws[i]
+	}
+
+	err := rpc.DoBatch(ctx, r.next, calls...)
+	for i, c := range calls {
+		c.Out = outs[i]
+		if err != nil {
+			continue
+		}
+		r.r.record(c.Method, c.Args, raws[i], c.Err)
+		if c.Err == nil {
+			c.Err = service.DecodeResult(raws[i], c.Out)
+		}
+	}
+
+	return err
+}
+
+func (r *Recorder) record(method string, args []interface{}, result json.RawMessage, err error) {
+	i := Interaction{
+		Method: method,
+		Result: result,
+	}
+	if args == nil {
+		args = []interface{}{}
+	}
+	var marshalErr error
+	if i.Args, marshalErr = json.Marshal(args); marshalErr != nil {
+		r.fail(marshalErr)
+		return
+	}
+	if err != nil {
+		i.Result = nil
+		var nodeErr *models.Error
+		var statusErr *models.StatusError
+		switch {
+		case errors.As(err, &nodeErr):
+			i.Error = nodeErr
+		case errors.As(err, &statusErr):
+			i.Status = statusErr
+		}
+		if i.err() == nil || i.err().Error() != err.Error() {
+			i.Failure = err.Error()
+		}
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err := r.enc.Encode(i); err != nil && r.err == nil {
+		r.err = err
+	}
+}
+
+func (r *Recorder) fail(err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.err == nil {
+		r.err = err
+	}
+}
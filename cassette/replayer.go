@@ -0,0 +1,149 @@
+package cassette
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+
+	"github.com/libsv/go-bn/internal/service"
+)
+
+// ErrNoInteraction error when the cassette holds no unplayed interaction matching a call.
+var ErrNoInteraction = errors.New("no matching interaction in cassette")
+
+// Match how calls are matched to recorded interactions.
+type Match int
+
+// Match modes.
+const (
+	// MatchExact match the next unplayed interaction of the same method and args.
+	MatchExact Match = iota
+	// MatchMethod match the next unplayed interaction of the same method, whatever its args.
+	MatchMethod
+	// MatchSequence match interactions strictly in the order recorded, failing
+	// should the call's method differ.
+	MatchSequence
+)
+
+// Replayer an rpc.RPC serving calls from a cassette, without a node. Each
+// interaction is played once.
+type Replayer struct {
+	match Match
+
+	mu           sync.Mutex
+	interactions []Interaction
+	played       []bool
+	next         int
+}
+
+// NewReplayer returns a replayer serving the interactions read from r.
+func NewReplayer(r io.Reader, match Match) (*Replayer, error) {
+	var ii []Interaction
+	sc := bufio.NewScanner(r)
+	sc.Buffer(make([]byte, 64*1024), 1<<30)
+	for line := 1; sc.Scan(); line++ {
+		if len(bytes.TrimSpace(sc.Bytes())) == 0 {
+			continue
+		}
+		var i Interaction
+		if err := json.Unmarshal(sc.Bytes(), &i); err != nil {
+			return nil, fmt.Errorf("cassette line %d: %w", line, err)
+		}
+		if i.Args, _ = compact(i.Args); i.Args == nil {
+			i.Args = json.RawMessage("[]")
+		}
+		ii = append(ii, i)
+	}
+	if err := sc.Err(); err != nil {
+		return nil, err
+	}
+
+	return &Replayer{
+		match:        match,
+		interactions: ii,
+		played:       make([]bool, len(ii)),
+	}, nil
+}
+
+// Do serve the call from the cassette.
+func (r *Replayer) Do(ctx context.Context, method string, out interface{}, args ...interface{}) error {
+	i, err := r.take(method, args)
+	if err != nil {
+		return err
+	}
+	if err := i.err(); err != nil {
+		return err
+	}
+
+	return service.DecodeResult(i.Result, out)
+}
+
+// Unplayed the interactions not yet served.
+func (r *Replayer) Unplayed() []Interaction {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var ii []Interaction
+	for n, i := range r.interactions {
+		if !r.played[n] {
+			ii = append(ii, i)
+		}
+	}
+	return ii
+}
+
+func (r *Replayer) take(method string, args []interface{}) (*Interaction, error) {
+	if args == nil {
+		args = []interface{}{}
+	}
+	bb, err := json.Marshal(args)
+	if err != nil {
+		return nil, err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.match == MatchSequence {
+		if r.next >= len(r.interactions) {
+			return nil, fmt.Errorf("%w: %s", ErrNoInteraction, method)
+		}
+		i := &r.interactions[r.next]
+		if i.Method != method {
+			return nil, fmt.Errorf("%w: expected %s, got %s", ErrNoInteraction, i.Method, method)
+		}
+		r.played[r.next] = true
+		r.next++
+		return i, nil
+	}
+
+	for n := range r.interactions {
+		i := &r.interactions[n]
+		if r.played[n] || i.Method != method {
+			continue
+		}
+		if r.match == MatchExact && !bytes.Equal(i.Args, bb) {
+			continue
+		}
+		r.played[n] = true
+		return i, nil
+	}
+
+	return nil, fmt.Errorf("%w: %s %s", ErrNoInteraction, method, bb)
+}
+
+func compact(bb json.RawMessage) (json.RawMessage, error) {
+	if len(bb) == 0 {
+		return nil, nil
+	}
+	var buf bytes.Buffer
+	if err := json.Compact(&buf, bb); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,269 @@
+package bn
+
+import (
+	"context"
+	"time"
+
+	"github.com/libsv/go-bn/models"
+	"github.com/libsv/go-bn/zmq"
+)
+
+// ChainEvent a change to the node's best chain, either a BlockConnected or a
+// BlockDisconnected.
+type ChainEvent interface {
+	chainEvent()
+}
+
+// BlockConnected event when a block joins the best chain.
+type BlockConnected struct {
+	Header *models.BlockHeader
+}
+
+// BlockDisconnected event when a block leaves the best chain during a reorg.
+type BlockDisconnected struct {
+	Header *models.BlockHeader
+}
+
+func (BlockConnected) chainEvent()    {}
+func (BlockDisconnected) chainEvent() {}
+
+// ChainFollower follows the node's best chain, keeping a window of its most recent
+// headers and emitting an event for each block joining or leaving it. Upon a reorg,
+// the blocks of the old chain are disconnected from its tip down to the fork point,
+// before those of the new chain are connected from the fork point up:
+//
+//	f := bn.NewChainFollower(c, bn.FollowerNodeMQ(mq))
+//	err := f.Run(ctx, func(e bn.ChainEvent) error {
+//		switch e := e.(type) {
+//		case bn.BlockConnected:
+//			return index(e.Header)
+//		case bn.BlockDisconnected:
+//			return unindex(e.Header)
+//		}
+//		return nil
+//	})
+//
+// The follower keeps its place between calls to Run, so should Run return an
+// error, calling it again resumes from the last header handled.
+type ChainFollower struct {
+	c        BlockChainClient
+	mq       zmq.NodeMQ
+	interval time.Duration
+	window   int
+	from     string
+
+	headers []*models.BlockHeader
+	byHash  map[string]*models.BlockHeader
+}
+
+// ChainFollowerOptFunc for setting chain follower options.
+type ChainFollowerOptFunc func(f *ChainFollower)
+
+// FollowerPollInterval set how often the node's best block is checked. Defaults to a second.
+func FollowerPollInterval(d time.Duration) ChainFollowerOptFunc {
+	return func(f *ChainFollower) {
+		f.interval = d
+	}
+}
+
+// FollowerNodeMQ check the node's best block as soon as it announces a block, rather
+// than waiting for the next poll. The follower subscribes to `hashblock` whilst
+// running, through zmq.Shared, so sharing the topic with other subscribers of mq.
+func FollowerNodeMQ(mq zmq.NodeMQ) ChainFollowerOptFunc {
+	return func(f *ChainFollower) {
+		f.mq = mq
+	}
+}
+
+// FollowerWindow set the number of recent headers kept. Reorgs deeper than the
+// window are still followed, the headers beyond it being looked up. Defaults to 100.
+func FollowerWindow(n int) ChainFollowerOptFunc {
+	return func(f *ChainFollower) {
+		f.window = n
+	}
+}
+
+// FollowerFrom start following from the block with the hash, such as a checkpoint
+// saved by a previous run, rather than the node's best block. Each block connected
+// since is emitted, as is a disconnect should the block have since been reorged out.
+func FollowerFrom(hash string) ChainFollowerOptFunc {
+	return func(f *ChainFollower) {
+		f.from = hash
+	}
+}
+
+// NewChainFollower returns a follower of the best chain of the node c is a client of.
+func NewChainFollower(c BlockChainClient, oo ...ChainFollowerOptFunc) *ChainFollower {
+	f := &ChainFollower{
+		c:        c,
+		interval: time.Second,
+		window:   100,
+		byHash:   make(map[string]*models.BlockHeader),
+	}
+	for _, o := range oo {
+		o(f)
+	}
+	if f.window < 1 {
+		f.window = 1
+	}
+	return f
+}
+
+// Run follow the chain, passing each event to fn, until ctx is done or an error
+// is returned by the node or fn.
+func (f *ChainFollower) Run(ctx context.Context, fn func(e ChainEvent) error) error {
+	announced := make(chan struct{}, 1)
+	if f.mq != nil {
+		mq := zmq.Shared(f.mq)
+		if err := mq.SubscribeHashBlock(func(context.Context, string) {
+			select {
+			case announced <- struct{}{}:
+			default:
+			}
+		}); err != nil {
+			return err
+		}
+		defer mq.Unsubscribe(zmq.TopicHashBlock) // nolint:errcheck // best effort
+	}
+
+	ticker := time.NewTicker(f.interval)
+	defer ticker.Stop()
+	for {
+		if err := f.sync(ctx, fn); err != nil {
+			return err
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		case <-announced:
+		}
+	}
+}
+
+// tip the header of the best block handled, nil before the follower has first run.
+func (f *ChainFollower) tip() *models.BlockHeader {
+	if len(f.headers) == 0 {
+		return nil
+	}
+	return f.headers[len(f.headers)-1]
+}
+
+// sync bring the follower up to the node's best block.
+func (f *ChainFollower) sync(ctx context.Context, fn func(e ChainEvent) error) error {
+	if f.tip() == nil {
+		if err := f.start(ctx); err != nil {
+			return err
+		}
+	}
+
+	best, err := f.c.BestBlockHash(ctx)
+	if err != nil {
+		return err
+	}
+	if best == f.tip().Hash {
+		return nil
+	}
+	header, err := f.c.BlockHeader(ctx, best)
+	if err != nil {
+		return err
+	}
+
+	ancestor, disconnected, connected, err := f.fork(ctx, header)
+	if err != nil {
+		return err
+	}
+	for i, h := range disconnected {
+		if err = fn(BlockDisconnected{Header: h}); err != nil {
+			return err
+		}
+		parent := ancestor
+		if i+1 < len(disconnected) {
+			parent = disconnected[i+1]
+		}
+		f.pop(parent)
+	}
+	for i := len(connected) - 1; i >= 0; i-- {
+		if err = fn(BlockConnected{Header: connected[i]}); err != nil {
+			return err
+		}
+		f.push(connected[i])
+	}
+	return nil
+}
+
+// start seed the window with the header the follower starts from.
+func (f *ChainFollower) start(ctx context.Context) error {
+	hash := f.from
+	if hash == "" {
+		var err error
+		if hash, err = f.c.BestBlockHash(ctx); err != nil {
+			return err
+		}
+	}
+	header, err := f.c.BlockHeader(ctx, hash)
+	if err != nil {
+		return err
+	}
+	f.push(header)
+	return nil
+}
+
+// fork walk back from the tip and the header to their common ancestor, returning
+// it and the headers to disconnect and connect, each tip first.
+func (f *ChainFollower) fork(ctx context.Context, header *models.BlockHeader) (
+	ancestor *models.BlockHeader, disconnect, connect []*models.BlockHeader, err error) {
+	oldHeader, newHeader := f.tip(), header
+	for newHeader.Height > oldHeader.Height {
+		connect = append(connect, newHeader)
+		if newHeader, err = f.parent(ctx, newHeader); err != nil {
+			return nil, nil, nil, err
+		}
+	}
+	for oldHeader.Height > newHeader.Height {
+		disconnect = append(disconnect, oldHeader)
+		if oldHeader, err = f.parent(ctx, oldHeader); err != nil {
+			return nil, nil, nil, err
+		}
+	}
+	for oldHeader.Hash != newHeader.Hash {
+		connect = append(connect, newHeader)
+		disconnect = append(disconnect, oldHeader)
+		if newHeader, err = f.parent(ctx, newHeader); err != nil {
+			return nil, nil, nil, err
+		}
+		if oldHeader, err = f.parent(ctx, oldHeader); err != nil {
+			return nil, nil, nil, err
+		}
+	}
+	return oldHeader, disconnect, connect, nil
+}
+
+// parent the header of the block before h, from the window if held.
+func (f *ChainFollower) parent(ctx context.Context, h *models.BlockHeader) (*models.BlockHeader, error) {
+	hash := h.HashPrevBlockStr()
+	if header, ok := f.byHash[hash]; ok {
+		return header, nil
+	}
+	return f.c.BlockHeader(ctx, hash)
+}
+
+// push add h to the tip of the window, dropping the oldest header should it be full.
+func (f *ChainFollower) push(h *models.BlockHeader) {
+	f.headers = append(f.headers, h)
+	f.byHash[h.Hash] = h
+	if len(f.headers) > f.window {
+		delete(f.byHash, f.headers[0].Hash)
+		f.headers = f.headers[1:]
+	}
+}
+
+// pop remove the tip from the window, its parent taking its place should a deep
+// reorg empty the window.
+func (f *ChainFollower) pop(parent *models.BlockHeader) {
+	delete(f.byHash, f.tip().Hash)
+	f.headers = f.headers[:len(f.headers)-1]
+	if len(f.headers) == 0 {
+		f.push(parent)
+	}
+}
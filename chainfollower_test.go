@@ -0,0 +1,204 @@
+package bn_test
+
+import (
+	"context"
+	"encoding/hex"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/libsv/go-bc"
+	"github.com/libsv/go-bn"
+	bnmocks "github.com/libsv/go-bn/mocks"
+	"github.com/libsv/go-bn/models"
+	"github.com/libsv/go-bn/zmq"
+	"github.com/stretchr/testify/assert"
+)
+
+// testChain a block tree of two branches, a and b, which fork after the block
+// at height forkAt, with blocks named by branch and height, such as a3.
+type testChain struct {
+	mu      sync.Mutex
+	headers map[string]*models.BlockHeader
+	names   map[string]string
+	tips    []string
+}
+
+func newTestChain(height, forkAt, forkHeight int) *testChain {
+	c := &testChain{
+		headers: make(map[string]*models.BlockHeader),
+		names:   make(map[string]string),
+	}
+	prev := c.add("a", 0, "")
+	forkPrev := prev
+	for h := 1; h <= height; h++ {
+		prev = c.add("a", h, prev)
+		if h == forkAt {
+			forkPrev = prev
+		}
+	}
+	for h := forkAt + 1; h <= forkHeight; h++ {
+		forkPrev = c.add("b", h, forkPrev)
+	}
+	return c
+}
+
+func (c *testChain) add(branch string, height int, prev string) string {
+	hash := fmt.Sprintf("%x%062x", branch, height)
+	prevBytes, _ := hex.DecodeString(prev)
+	c.headers[hash] = &models.BlockHeader{
+		BlockHeader: &bc.BlockHeader{HashPrevBlock: prevBytes},
+		Hash:        hash,
+		Height:      uint64(height),
+	}
+	c.names[hash] = fmt.Sprintf("%s%d", branch, height)
+	return hash
+}
+
+func (c *testChain) hash(name string) string {
+	for hash, n := range c.names {
+		if n == name {
+			return hash
+		}
+	}
+	return ""
+}
+
+// client a client of the chain, whose best block is each of the tips in turn.
+func (c *testChain) client(tips ...string) *bnmocks.BlockChainClientMock {
+	for _, tip := range tips {
+		c.tips = append(c.tips, c.hash(tip))
+	}
+	return &bnmocks.BlockChainClientMock{
+		BestBlockHashFunc: func(ctx context.Context) (string, error) {
+			c.mu.Lock()
+			defer c.mu.Unlock()
+			tip := c.tips[0]
+			if len(c.tips) > 1 {
+				c.tips = c.tips[1:]
+			}
+			return tip, nil
+		},
+		BlockHeaderFunc: func(ctx context.Context, hash string) (*models.BlockHeader, error) {
+			h, ok := c.headers[hash]
+			if !ok {
+				return nil, &models.Error{Code: models.ErrNotFound.Code, Message: "Block not found"}
+			}
+			return h, nil
+		},
+	}
+}
+
+// follow run f until it has emitted n events, returning them by block name.
+func (c *testChain) follow(t *testing.T, f *bn.ChainFollower, n int) []string {
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	var events []string
+	err := f.Run(ctx, func(e bn.ChainEvent) error {
+		switch e := e.(type) {
+		case bn.BlockConnected:
+			events = append(events, "+"+c.names[e.Header.Hash])
+		case bn.BlockDisconnected:
+			events = append(events, "-"+c.names[e.Header.Hash])
+		}
+		if len(events) == n {
+			cancel()
+		}
+		return nil
+	})
+	assert.ErrorIs(t, err, context.Canceled)
+	return events
+}
+
+func TestChainFollower_Run(t *testing.T) {
+	t.Parallel()
+	tests := map[string]struct {
+		chain     *testChain
+		from      string
+		tips      []string
+		window    int
+		expEvents []string
+	}{
+		"blocks are connected in order": {
+			chain:     newTestChain(5, 0, 0),
+			from:      "a1",
+			tips:      []string{"a3", "a5"},
+			expEvents: []string{"+a2", "+a3", "+a4", "+a5"},
+		},
+		"single block reorg": {
+			chain:     newTestChain(3, 2, 3),
+			from:      "a3",
+			tips:      []string{"a3", "b3"},
+			expEvents: []string{"-a3", "+b3"},
+		},
+		"multi block reorg to a longer chain": {
+			chain:     newTestChain(5, 2, 7),
+			from:      "a2",
+			tips:      []string{"a5", "b7"},
+			expEvents: []string{"+a3", "+a4", "+a5", "-a5", "-a4", "-a3", "+b3", "+b4", "+b5", "+b6", "+b7"},
+		},
+		"multi block reorg to a shorter chain": {
+			chain:     newTestChain(5, 2, 4),
+			from:      "a5",
+			tips:      []string{"b4"},
+			expEvents: []string{"-a5", "-a4", "-a3", "+b3", "+b4"},
+		},
+		"reorg deeper than the window": {
+			chain:     newTestChain(5, 1, 6),
+			from:      "a2",
+			tips:      []string{"a5", "b6"},
+			window:    2,
+			expEvents: []string{"+a3", "+a4", "+a5", "-a5", "-a4", "-a3", "-a2", "+b2", "+b3", "+b4", "+b5", "+b6"},
+		},
+		"checkpoint since reorged out": {
+			chain:     newTestChain(4, 2, 5),
+			from:      "a4",
+			tips:      []string{"b5"},
+			expEvents: []string{"-a4", "-a3", "+b3", "+b4", "+b5"},
+		},
+	}
+
+	for name, test := range tests {
+		test := test
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			f := bn.NewChainFollower(test.chain.client(test.tips...),
+				bn.FollowerFrom(test.chain.hash(test.from)),
+				bn.FollowerWindow(test.window),
+				bn.FollowerPollInterval(time.Millisecond))
+
+			assert.Equal(t, test.expEvents, test.chain.follow(t, f, len(test.expEvents)))
+		})
+	}
+}
+
+func TestChainFollower_Run_NodeMQ(t *testing.T) {
+	t.Parallel()
+	chain := newTestChain(2, 0, 0)
+	var unsubscribed zmq.Topic
+	mq := &bnmocks.NodeMQMock{
+		SubscribeFunc: func(topic zmq.Topic, fn zmq.MessageFunc) error {
+			// Announce the block once the follower has started from a1.
+			hash, err := hex.DecodeString(chain.hash("a2"))
+			assert.NoError(t, err)
+			go func() {
+				time.Sleep(10 * time.Millisecond)
+				fn(context.Background(), [][]byte{[]byte(topic), hash})
+			}()
+			return nil
+		},
+		UnsubscribeFunc: func(topic zmq.Topic) error {
+			unsubscribed = topic
+			return nil
+		},
+	}
+	f := bn.NewChainFollower(chain.client("a1", "a2"),
+		bn.FollowerFrom(chain.hash("a1")),
+		bn.FollowerNodeMQ(mq),
+		bn.FollowerPollInterval(time.Hour))
+
+	assert.Equal(t, []string{"+a2"}, chain.follow(t, f, 1))
+	assert.Equal(t, zmq.TopicHashBlock, unsubscribed)
+}
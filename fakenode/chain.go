@@ -0,0 +1,510 @@
+package fakenode
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"sort"
+
+	"github.com/libsv/go-bc"
+	"github.com/libsv/go-bk/crypto"
+	"github.com/libsv/go-bn/models"
+	"github.com/libsv/go-bt/v2"
+	"github.com/libsv/go-bt/v2/bscript"
+)
+
+const (
+	blockVersion    = 0x20000000
+	halvingInterval = 150
+	initialSubsidy  = 50 * 1e8
+)
+
+// regtestBits the regtest proof of work limit, so a valid nonce takes a couple of tries.
+var regtestBits = []byte{0x20, 0x7f, 0xff, 0xff}
+
+type block struct {
+	*bc.Block
+	hash      string
+	height    int
+	chainwork *big.Int
+}
+
+func (n *Node) mine(lockingScript *bscript.Script) *block {
+	height := len(n.chain)
+
+	txs := make([]*bt.Tx, 1, len(n.order)+1)
+	var fees uint64
+	for _, id := range n.order {
+		e := n.mempool[id]
+		txs = append(txs, e.tx)
+		fees += e.fee
+	}
+	txs[0] = coinbase(height, subsidy(height)+fees, lockingScript)
+
+	b := &block{
+		Block:     &bc.Block{BlockHeader: n.header(txs), Txs: txs},
+		height:    height,
+		chainwork: work(regtestBits),
+	}
+	b.hash = hex.EncodeToString(bt.ReverseBytes(crypto.Sha256d(b.BlockHeader.Bytes())))
+	if height > 0 {
+		b.chainwork.Add(b.chainwork, n.tip().chainwork)
+	}
+
+	n.connect(b)
+	return b
+}
+
+func (n *Node) header(txs []*bt.Tx) *bc.BlockHeader {
+	ids := make([]string, len(txs))
+	for i, tx := range txs {
+		ids[i] = tx.TxID()
+	}
+	root, err := bc.BuildMerkleRoot(ids)
+	if err != nil {
+		panic(err)
+	}
+	merkleRoot, err := hex.DecodeString(root)
+	if err != nil {
+		panic(err)
+	}
+
+	h := &bc.BlockHeader{
+		Version:        blockVersion,
+		Time:           uint32(n.now().Unix()),
+		HashPrevBlock:  make([]byte, 32),
+		HashMerkleRoot: merkleRoot,
+		Bits:           regtestBits,
+	}
+	if len(n.chain) > 0 {
+		prev := n.tip()
+		h.HashPrevBlock, _ = hex.DecodeString(prev.hash)
+		if h.Time <= prev.BlockHeader.Time {
+			h.Time = prev.BlockHeader.Time + 1
+		}
+	}
+	for !h.Valid() {
+		h.Nonce++
+	}
+
+	return h
+}
+
+// connect add the block to the tip of the chain, moving its transactions out of
+// the mempool and updating the utxo set.
+func (n *Node) connect(b *block) {
+	for i, tx := range b.Txs {
+		txID := tx.TxID()
+		if i > 0 {
+			for _, in := range tx.Inputs {
+				delete(n.utxos, outpoint{txID: in.PreviousTxIDStr(), vout: in.PreviousTxOutIndex})
+			}
+		}
+		n.txs[txID] = b
+		if b.height == 0 {
+			continue
+		}
+		for vout, o := range tx.Outputs {
+			if o.LockingScript.IsData() {
+				continue
+			}
+			n.utxos[outpoint{txID: txID, vout: uint32(vout)}] = &coin{
+				output:   o,
+				height:   b.height,
+				coinbase: i == 0,
+			}
+		}
+	}
+
+	n.chain = append(n.chain, b)
+	n.blocks[b.hash] = b
+	n.mempool = map[string]*entry{}
+	n.order = nil
+	n.spends = map[outpoint]string{}
+}
+
+// disconnect remove the tip from the chain, leaving it stale, and restore the
+// outputs its transactions spent to the utxo set.
+func (n *Node) disconnect() {
+	b := n.tip()
+	n.chain = n.chain[:len(n.chain)-1]
+	for i := len(b.Txs) - 1; i >= 0; i-- {
+		tx := b.Txs[i]
+		txID := tx.TxID()
+		for vout := range tx.Outputs {
+			delete(n.utxos, outpoint{txID: txID, vout: uint32(vout)})
+		}
+		delete(n.txs, txID)
+		if i == 0 {
+			continue
+		}
+		for _, in := range tx.Inputs {
+			prev := n.txs[in.PreviousTxIDStr()]
+			prevTx := prev.txByID(in.PreviousTxIDStr())
+			n.utxos[outpoint{txID: in.PreviousTxIDStr(), vout: in.PreviousTxOutIndex}] = &coin{
+				output:   prevTx.Outputs[in.PreviousTxOutIndex],
+				height:   prev.height,
+				coinbase: prevTx.IsCoinbase(),
+			}
+		}
+	}
+}
+
+// active whether the block is on the best chain, rather than stale.
+func (n *Node) active(b *block) bool {
+	return b.height < len(n.chain) && n.chain[b.height] == b
+}
+
+func coinbase(height int, satoshis uint64, lockingScript *bscript.Script) *bt.Tx {
+	// BIP34 height, minimally encoded.
+	hb := make([]byte, 0, 4)
+	for h := height; h > 0; h >>= 8 {
+		hb = append(hb, byte(h))
+	}
+	if len(hb) > 0 && hb[len(hb)-1]&0x80 != 0 {
+		hb = append(hb, 0)
+	}
+	unlockingScript := &bscript.Script{}
+	if err := unlockingScript.AppendPushData(hb); err != nil {
+		panic(err)
+	}
+
+	in := &bt.Input{
+		UnlockingScript:    unlockingScript,
+		PreviousTxOutIndex: 0xffffffff,
+		SequenceNumber:     0xffffffff,
+	}
+	if err := in.PreviousTxIDAdd(make([]byte, 32)); err != nil {
+		panic(err)
+	}
+
+	tx := bt.NewTx()
+	tx.Inputs = append(tx.Inputs, in)
+	tx.AddOutput(&bt.Output{Satoshis: satoshis, LockingScript: lockingScript})
+	return tx
+}
+
+func subsidy(height int) uint64 {
+	halvings := height / halvingInterval
+	if halvings >= 64 {
+		return 0
+	}
+	return initialSubsidy >> halvings
+}
+
+func work(bits []byte) *big.Int {
+	target, err := bc.ExpandTargetFromAsInt(hex.EncodeToString(bits))
+	if err != nil {
+		panic(err)
+	}
+	w := new(big.Int).Lsh(big.NewInt(1), 256)
+	return w.Div(w, target.Add(target, big.NewInt(1)))
+}
+
+func (n *Node) generate(pp params) (interface{}, error) {
+	blocks, err := pp.int(0, 0)
+	if err != nil {
+		return nil, err
+	}
+	k, err := n.newKey()
+	if err != nil {
+		return nil, err
+	}
+	return n.generateTo(blocks, k.lockingScript())
+}
+
+func (n *Node) generateToAddress(pp params) (interface{}, error) {
+	blocks, err := pp.int(0, 0)
+	if err != nil {
+		return nil, err
+	}
+	addr, err := pp.str(1)
+	if err != nil {
+		return nil, err
+	}
+	s, err := bscript.NewP2PKHFromAddress(addr)
+	if err != nil {
+		return nil, nodeError(models.ErrNotFound, "Error: Invalid address")
+	}
+	return n.generateTo(blocks, s)
+}
+
+func (n *Node) generateTo(blocks int, lockingScript *bscript.Script) ([]string, error) {
+	hashes := make([]string, blocks)
+	for i := range hashes {
+		hashes[i] = n.mine(lockingScript).hash
+	}
+	return hashes, nil
+}
+
+// invalidateBlock disconnect the block and those built on it, leaving them stale, so
+// the blocks next mined fork from its parent. The mempool is cleared, rather than the
+// transactions of the blocks disconnected being returned to it.
+func (n *Node) invalidateBlock(pp params) (interface{}, error) {
+	hash, err := pp.str(0)
+	if err != nil {
+		return nil, err
+	}
+	b, ok := n.blocks[hash]
+	if !ok {
+		return nil, nodeError(models.ErrNotFound, "Block not found")
+	}
+	if b.height == 0 {
+		return nil, nodeError(models.ErrMisc, "Cannot invalidate the genesis block")
+	}
+	if !n.active(b) {
+		return nil, nil
+	}
+	for len(n.chain) > b.height {
+		n.disconnect()
+	}
+	n.mempool = map[string]*entry{}
+	n.order = nil
+	n.spends = map[outpoint]string{}
+	return nil, nil
+}
+
+func (n *Node) bestBlockHash(params) (interface{}, error) {
+	return n.tip().hash, nil
+}
+
+func (n *Node) blockCount(params) (interface{}, error) {
+	return n.tip().height, nil
+}
+
+func (n *Node) blockHash(pp params) (interface{}, error) {
+	height, err := pp.int(0, -1)
+	if err != nil {
+		return nil, err
+	}
+	if height < 0 || height >= len(n.chain) {
+		return nil, nodeError(models.ErrInvalidParameter, "Block height out of range")
+	}
+	return n.chain[height].hash, nil
+}
+
+func (n *Node) block(pp params) (interface{}, error) {
+	hash, err := pp.str(0)
+	if err != nil {
+		return nil, err
+	}
+	b, ok := n.blocks[hash]
+	if !ok {
+		return nil, nodeError(models.ErrNotFound, "Block not found")
+	}
+	verbosity, err := pp.verbosity(1)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := n.headerJSON(b)
+	switch verbosity {
+	case string(models.VerbosityRawBlock):
+		return b.String(), nil
+	case string(models.VerbosityDecodeHeader):
+		ids := make([]string, len(b.Txs))
+		for i, tx := range b.Txs {
+			ids[i] = tx.TxID()
+		}
+		resp["tx"] = ids
+	case string(models.VerbosityDecodeTransactions):
+		txs := bt.Txs(b.Txs)
+		resp["tx"] = txs.NodeJSON()
+	case string(models.VerbosityDecodeHeaderAndCoinbase):
+		txs := bt.Txs(b.Txs[:1])
+		resp["tx"] = txs.NodeJSON()
+	default:
+		return nil, nodeError(models.ErrInvalidParameter, fmt.Sprintf("Invalid verbosity %s", verbosity))
+	}
+	return resp, nil
+}
+
+func (n *Node) blockHeader(pp params) (interface{}, error) {
+	hash, err := pp.str(0)
+	if err != nil {
+		return nil, err
+	}
+	b, ok := n.blocks[hash]
+	if !ok {
+		return nil, nodeError(models.ErrNotFound, "Block not found")
+	}
+	verbose, err := pp.bool(1, true)
+	if err != nil {
+		return nil, err
+	}
+	if !verbose {
+		return b.BlockHeader.String(), nil
+	}
+	return n.headerJSON(b), nil
+}
+
+// verbosity read a getblock verbosity, given either by name, level or, as older
+// nodes took it, bool.
+func (pp params) verbosity(i int) (string, error) {
+	levels := []string{
+		string(models.VerbosityRawBlock),
+		string(models.VerbosityDecodeHeader),
+		string(models.VerbosityDecodeTransactions),
+		string(models.VerbosityDecodeHeaderAndCoinbase),
+	}
+	if !pp.has(i) {
+		return levels[1], nil
+	}
+	switch v := pp[i].(type) {
+	case string:
+		return v, nil
+	case bool:
+		if v {
+			return levels[1], nil
+		}
+		return levels[0], nil
+	case float64:
+		if v >= 0 && int(v) < len(levels) {
+			return levels[int(v)], nil
+		}
+	}
+	return "", nodeError(models.ErrInvalidParameter, fmt.Sprintf("Invalid verbosity %v", pp[i]))
+}
+
+func (n *Node) headerJSON(b *block) map[string]interface{} {
+	times := make([]int, 0, 11)
+	for p := b; p != nil && len(times) < cap(times); p = n.blocks[p.BlockHeader.HashPrevBlockStr()] {
+		times = append(times, int(p.BlockHeader.Time))
+	}
+	sort.Ints(times)
+	difficulty, _ := bc.DifficultyFromBits(b.BlockHeader.Bits)
+
+	resp := map[string]interface{}{
+		"hash":          b.hash,
+		"confirmations": n.blockConfirmations(b),
+		"size":          len(b.Bytes()),
+		"height":        b.height,
+		"version":       b.BlockHeader.Version,
+		"versionHex":    fmt.Sprintf("%08x", b.BlockHeader.Version),
+		"merkleroot":    b.BlockHeader.HashMerkleRootStr(),
+		"num_tx":        len(b.Txs),
+		"time":          b.BlockHeader.Time,
+		"mediantime":    times[len(times)/2],
+		"nonce":         b.BlockHeader.Nonce,
+		"bits":          b.BlockHeader.BitsStr(),
+		"difficulty":    difficulty,
+		"chainwork":     fmt.Sprintf("%064x", b.chainwork),
+	}
+	if b.height > 0 {
+		resp["previousblockhash"] = b.BlockHeader.HashPrevBlockStr()
+	}
+	if n.active(b) && b.height < n.tip().height {
+		resp["nextblockhash"] = n.chain[b.height+1].hash
+	}
+	return resp
+}
+
+func (n *Node) rawTransaction(pp params) (interface{}, error) {
+	txID, err := pp.str(0)
+	if err != nil {
+		return nil, err
+	}
+	verbose, err := pp.bool(1, false)
+	if err != nil {
+		return nil, err
+	}
+
+	var tx *bt.Tx
+	b, confirmed := n.txs[txID]
+	if confirmed {
+		tx = b.txByID(txID)
+	} else if e, ok := n.mempool[txID]; ok {
+		tx = e.tx
+	} else {
+		return nil, nodeError(models.ErrNotFound,
+			"No such mempool or blockchain transaction. Use gettransaction for wallet transactions.")
+	}
+	if !verbose {
+		return tx.String(), nil
+	}
+
+	bb, err := json.Marshal(tx.NodeJSON())
+	if err != nil {
+		return nil, err
+	}
+	var resp map[string]interface{}
+	if err = json.Unmarshal(bb, &resp); err != nil {
+		return nil, err
+	}
+	if confirmed {
+		resp["blockhash"] = b.hash
+		resp["confirmations"] = n.confirmations(b.height)
+		resp["time"] = b.BlockHeader.Time
+		resp["blocktime"] = b.BlockHeader.Time
+	}
+	return resp, nil
+}
+
+func (b *block) txByID(txID string) *bt.Tx {
+	for _, tx := range b.Txs {
+		if tx.TxID() == txID {
+			return tx
+		}
+	}
+	return nil
+}
+
+func (n *Node) txOut(pp params) (interface{}, error) {
+	txID, err := pp.str(0)
+	if err != nil {
+		return nil, err
+	}
+	vout, err := pp.int(1, -1)
+	if err != nil {
+		return nil, err
+	}
+	includeMempool, err := pp.bool(2, true)
+	if err != nil {
+		return nil, err
+	}
+
+	op := outpoint{txID: txID, vout: uint32(vout)}
+	c, ok := n.coin(op, includeMempool)
+	if vout < 0 || !ok {
+		return nil, nil
+	}
+
+	bb, err := json.Marshal(c.output.NodeJSON())
+	if err != nil {
+		return nil, err
+	}
+	var resp map[string]interface{}
+	if err = json.Unmarshal(bb, &resp); err != nil {
+		return nil, err
+	}
+	delete(resp, "n")
+	resp["bestblock"] = n.tip().hash
+	resp["confirmations"] = 0
+	if c.height > 0 {
+		resp["confirmations"] = n.confirmations(c.height)
+	}
+	resp["coinbase"] = c.coinbase
+	return resp, nil
+}
+
+// coin the unspent output at op, including those created by mempool txs and
+// excluding those spent by them, should includeMempool be set. Mempool coins
+// have a height of 0.
+func (n *Node) coin(op outpoint, includeMempool bool) (*coin, bool) {
+	if !includeMempool {
+		c, ok := n.utxos[op]
+		return c, ok
+	}
+	if _, spent := n.spends[op]; spent {
+		return nil, false
+	}
+	if c, ok := n.utxos[op]; ok {
+		return c, true
+	}
+	e, ok := n.mempool[op.txID]
+	if !ok || int(op.vout) >= len(e.tx.Outputs) {
+		return nil, false
+	}
+	return &coin{output: e.tx.Outputs[op.vout]}, true
+}
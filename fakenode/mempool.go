@@ -0,0 +1,162 @@
+package fakenode
+
+import (
+	"bytes"
+
+	"github.com/libsv/go-bk/bec"
+	"github.com/libsv/go-bk/crypto"
+	"github.com/libsv/go-bn/models"
+	"github.com/libsv/go-bt/v2"
+	"github.com/libsv/go-bt/v2/bscript"
+	"github.com/libsv/go-bt/v2/sighash"
+)
+
+func (n *Node) sendRawTransaction(pp params) (interface{}, error) {
+	tx, err := pp.tx(0)
+	if err != nil {
+		return nil, err
+	}
+	dontCheckFee, err := pp.bool(2, false)
+	if err != nil {
+		return nil, err
+	}
+
+	txID := tx.TxID()
+	if _, ok := n.txs[txID]; ok {
+		return nil, nodeError(models.ErrAlreadyInChain, "Transaction already in block chain")
+	}
+	if _, ok := n.mempool[txID]; ok {
+		return nil, nodeError(models.ErrRejected, "257: txn-already-known")
+	}
+	if tx.IsCoinbase() {
+		return nil, nodeError(models.ErrRejected, "16: coinbase")
+	}
+
+	fee, err := n.checkInputs(tx)
+	if err != nil {
+		return nil, err
+	}
+	if !dontCheckFee && fee < n.fee(tx.Size()) {
+		return nil, nodeError(models.ErrRejected, "66: mempool min fee not met")
+	}
+
+	n.mempool[txID] = &entry{tx: tx, fee: fee, time: n.now().Unix()}
+	n.order = append(n.order, txID)
+	for _, in := range tx.Inputs {
+		n.spends[outpoint{txID: in.PreviousTxIDStr(), vout: in.PreviousTxOutIndex}] = txID
+	}
+	return txID, nil
+}
+
+func (n *Node) rawMempool(pp params) (interface{}, error) {
+	verbose, err := pp.bool(0, false)
+	if err != nil {
+		return nil, err
+	}
+	if !verbose {
+		return append([]string{}, n.order...), nil
+	}
+	resp := make(map[string]interface{}, len(n.order))
+	for _, txID := range n.order {
+		resp[txID] = n.entryJSON(txID)
+	}
+	return resp, nil
+}
+
+func (n *Node) mempoolEntry(pp params) (interface{}, error) {
+	txID, err := pp.str(0)
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := n.mempool[txID]; !ok {
+		return nil, nodeError(models.ErrNotFound, "Transaction not in mempool")
+	}
+	return n.entryJSON(txID), nil
+}
+
+// entryJSON the mempool entry of the tx with txID, as returned by getmempoolentry.
+func (n *Node) entryJSON(txID string) map[string]interface{} {
+	e := n.mempool[txID]
+	depends := []string{}
+	for _, in := range e.tx.Inputs {
+		parent := in.PreviousTxIDStr()
+		if _, ok := n.mempool[parent]; ok && !contains(depends, parent) {
+			depends = append(depends, parent)
+		}
+	}
+	return map[string]interface{}{
+		"size":        e.tx.Size(),
+		"fee":         float64(e.fee) / 1e8,
+		"modifiedfee": float64(e.fee) / 1e8,
+		"time":        e.time,
+		"height":      n.tip().height,
+		"depends":     depends,
+	}
+}
+
+// checkInputs check the tx's inputs spend unspent, mature outputs, with valid
+// signatures should they be p2pkh, and cover its outputs, returning its fee.
+func (n *Node) checkInputs(tx *bt.Tx) (uint64, error) {
+	var in uint64
+	for i, input := range tx.Inputs {
+		op := outpoint{txID: input.PreviousTxIDStr(), vout: input.PreviousTxOutIndex}
+		if _, spent := n.spends[op]; spent {
+			return 0, nodeError(models.ErrRejected, "258: txn-mempool-conflict")
+		}
+		c, ok := n.coin(op, true)
+		if !ok {
+			return 0, nodeError(models.ErrMissingInputs, "Missing inputs")
+		}
+		if c.coinbase && n.confirmations(c.height) < coinbaseMaturity {
+			return 0, nodeError(models.ErrRejected, "16: bad-txns-premature-spend-of-coinbase")
+		}
+		input.PreviousTxScript = c.output.LockingScript
+		input.PreviousTxSatoshis = c.output.Satoshis
+		if c.output.LockingScript.IsP2PKH() && !verify(tx, i) {
+			return 0, nodeError(models.ErrRejected,
+				"16: mandatory-script-verify-flag-failed (Signature must be zero for failed CHECK(MULTI)SIG operation)")
+		}
+		in += c.output.Satoshis
+	}
+
+	out := tx.TotalOutputSatoshis()
+	if in < out {
+		return 0, nodeError(models.ErrRejected, "16: bad-txns-in-belowout")
+	}
+	return in - out, nil
+}
+
+// verify verify the p2pkh signature of the tx's input i.
+func verify(tx *bt.Tx, i int) bool {
+	in := tx.Inputs[i]
+	if in.UnlockingScript == nil {
+		return false
+	}
+	parts, err := bscript.DecodeParts(*in.UnlockingScript)
+	if err != nil || len(parts) != 2 || len(parts[0]) == 0 {
+		return false
+	}
+	pkh, err := in.PreviousTxScript.PublicKeyHash()
+	if err != nil || !bytes.Equal(pkh, crypto.Hash160(parts[1])) {
+		return false
+	}
+
+	sigBytes := parts[0]
+	flag := sighash.Flag(sigBytes[len(sigBytes)-1])
+	if !flag.Has(sighash.ForkID) {
+		return false
+	}
+	sig, err := bec.ParseDERSignature(sigBytes[:len(sigBytes)-1], bec.S256())
+	if err != nil {
+		return false
+	}
+	pub, err := bec.ParsePubKey(parts[1], bec.S256())
+	if err != nil {
+		return false
+	}
+	hash, err := tx.CalcInputSignatureHash(uint32(i), flag)
+	if err != nil {
+		return false
+	}
+	return sig.Verify(hash, pub)
+}
@@ -0,0 +1,280 @@
+// Package fakenode provides an in-memory, stateful stand in for an SV node, for
+// integration style tests that would otherwise need a regtest node or a hand
+// written mock of the whole client:
+//
+//	node := fakenode.New()
+//	c := bn.NewNodeClient(bn.WithCustomRPC(node))
+//
+//	addr, _ := c.NewAddress(ctx, nil)
+//	_, _ = c.GenerateToAddress(ctx, 101, addr, nil)
+//
+// The node keeps a chain, mempool, utxo set and wallet, and serves the common
+// wallet and chain methods from them, building real transactions and blocks so
+// the results decode into the same bt, bc and models types as a real node's.
+// There is no proof of work difficulty, script interpreter or p2p; only p2pkh
+// spends have their signatures checked.
+package fakenode
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/libsv/go-bk/bec"
+	"github.com/libsv/go-bn/internal/service"
+	"github.com/libsv/go-bn/models"
+	"github.com/libsv/go-bt/v2"
+	"github.com/libsv/go-bt/v2/bscript"
+)
+
+const (
+	defaultFeeRate   = 500
+	coinbaseMaturity = 100
+)
+
+// NodeOptFunc for setting fake node options.
+type NodeOptFunc func(n *Node)
+
+// WithMainnet have the node's wallet hand out mainnet addresses. Defaults to testnet.
+func WithMainnet() NodeOptFunc {
+	return func(n *Node) {
+		n.mainnet = true
+	}
+}
+
+// WithFeeRate set the fee rate, in satoshis per kilobyte, used when funding
+// transactions and as the minimum for accepting them. Defaults to 500.
+func WithFeeRate(satsPerKB uint64) NodeOptFunc {
+	return func(n *Node) {
+		n.feeRate = satsPerKB
+	}
+}
+
+// WithClock set the clock used for block times. Defaults to time.Now.
+func WithClock(now func() time.Time) NodeOptFunc {
+	return func(n *Node) {
+		n.now = now
+	}
+}
+
+type outpoint struct {
+	txID string
+	vout uint32
+}
+
+type coin struct {
+	output   *bt.Output
+	height   int
+	coinbase bool
+}
+
+type entry struct {
+	tx   *bt.Tx
+	fee  uint64
+	time int64
+}
+
+type key struct {
+	priv *bec.PrivateKey
+	addr string
+}
+
+// Node an in-memory fake SV node, implementing rpc.RPC. It is safe for
+// concurrent use.
+type Node struct {
+	mainnet bool
+	feeRate uint64
+	now     func() time.Time
+
+	mu      sync.Mutex
+	chain   []*block
+	blocks  map[string]*block
+	txs     map[string]*block
+	utxos   map[outpoint]*coin
+	mempool map[string]*entry
+	order   []string
+	spends  map[outpoint]string
+	keys    map[string]*key
+}
+
+// New returns a fake node holding only a genesis block and an empty wallet.
+func New(oo ...NodeOptFunc) *Node {
+	n := &Node{
+		feeRate: defaultFeeRate,
+		now:     time.Now,
+		blocks:  map[string]*block{},
+		txs:     map[string]*block{},
+		utxos:   map[outpoint]*coin{},
+		mempool: map[string]*entry{},
+		spends:  map[outpoint]string{},
+		keys:    map[string]*key{},
+	}
+	for _, o := range oo {
+		o(n)
+	}
+
+	// The genesis coinbase is unspendable, so pay it to a key nobody holds.
+	priv, err := bec.NewPrivateKey(bec.S256())
+	if err != nil {
+		panic(err)
+	}
+	s, err := bscript.NewP2PKHFromPubKeyEC(priv.PubKey())
+	if err != nil {
+		panic(err)
+	}
+	n.mine(s)
+
+	return n
+}
+
+type handler func(n *Node, pp params) (interface{}, error)
+
+var handlers = map[string]handler{
+	"getbestblockhash":    (*Node).bestBlockHash,
+	"getblock":            (*Node).block,
+	"getblockcount":       (*Node).blockCount,
+	"getblockhash":        (*Node).blockHash,
+	"getblockheader":      (*Node).blockHeader,
+	"invalidateblock":     (*Node).invalidateBlock,
+	"getrawtransaction":   (*Node).rawTransaction,
+	"gettxout":            (*Node).txOut,
+	"generate":            (*Node).generate,
+	"generatetoaddress":   (*Node).generateToAddress,
+	"getnewaddress":       (*Node).newAddress,
+	"getrawchangeaddress": (*Node).newAddress,
+	"listunspent":         (*Node).listUnspent,
+	"fundrawtransaction":  (*Node).fundRawTransaction,
+	"signrawtransaction":  (*Node).signRawTransaction,
+	"sendrawtransaction":  (*Node).sendRawTransaction,
+	"getrawmempool":       (*Node).rawMempool,
+	"getmempoolentry":     (*Node).mempoolEntry,
+}
+
+// Do serve the call from the node's in-memory state. Methods the node does not
+// support fail with models.ErrMethodNotFound.
+func (n *Node) Do(ctx context.Context, method string, out interface{}, args ...interface{}) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	h, ok := handlers[method]
+	if !ok {
+		return &models.Error{Code: models.ErrMethodNotFound.Code, Message: "Method not found"}
+	}
+
+	// Round trip the args so handlers see them as the node would, whatever
+	// types the caller used.
+	var pp params
+	bb, err := json.Marshal(args)
+	if err != nil {
+		return err
+	}
+	if err = json.Unmarshal(bb, &pp); err != nil {
+		return err
+	}
+
+	n.mu.Lock()
+	resp, err := h(n, pp)
+	n.mu.Unlock()
+	if err != nil {
+		return err
+	}
+
+	result, err := json.Marshal(resp)
+	if err != nil {
+		return err
+	}
+	return service.DecodeResult(result, out)
+}
+
+func (n *Node) tip() *block {
+	return n.chain[len(n.chain)-1]
+}
+
+func (n *Node) confirmations(height int) int {
+	return n.tip().height - height + 1
+}
+
+// blockConfirmations the confirmations of the block, -1 should it be stale.
+func (n *Node) blockConfirmations(b *block) int {
+	if !n.active(b) {
+		return -1
+	}
+	return n.confirmations(b.height)
+}
+
+func (n *Node) fee(size int) uint64 {
+	return (uint64(size)*n.feeRate + 999) / 1000
+}
+
+func nodeError(err *models.Error, msg string) error {
+	return &models.Error{Code: err.Code, Message: msg}
+}
+
+type params []interface{}
+
+func (pp params) has(i int) bool {
+	return i < len(pp) && pp[i] != nil
+}
+
+func (pp params) str(i int) (string, error) {
+	if !pp.has(i) {
+		return "", nodeError(models.ErrMisc, fmt.Sprintf("missing parameter %d", i+1))
+	}
+	s, ok := pp[i].(string)
+	if !ok {
+		return "", nodeError(models.ErrType, fmt.Sprintf("Expected type string, got %T", pp[i]))
+	}
+	return s, nil
+}
+
+func (pp params) int(i, def int) (int, error) {
+	if !pp.has(i) {
+		return def, nil
+	}
+	f, ok := pp[i].(float64)
+	if !ok {
+		return 0, nodeError(models.ErrType, fmt.Sprintf("Expected type number, got %T", pp[i]))
+	}
+	return int(f), nil
+}
+
+func (pp params) bool(i int, def bool) (bool, error) {
+	if !pp.has(i) {
+		return def, nil
+	}
+	switch v := pp[i].(type) {
+	case bool:
+		return v, nil
+	case float64:
+		return v != 0, nil
+	}
+	return false, nodeError(models.ErrType, fmt.Sprintf("Expected type bool, got %T", pp[i]))
+}
+
+func (pp params) decode(i int, v interface{}) error {
+	if !pp.has(i) {
+		return nil
+	}
+	bb, err := json.Marshal(pp[i])
+	if err != nil {
+		return err
+	}
+	if err = json.Unmarshal(bb, v); err != nil {
+		return nodeError(models.ErrType, err.Error())
+	}
+	return nil
+}
+
+func (pp params) tx(i int) (*bt.Tx, error) {
+	s, err := pp.str(i)
+	if err != nil {
+		return nil, err
+	}
+	tx, err := bt.NewTxFromString(s)
+	if err != nil {
+		return nil, nodeError(models.ErrDeserialization, "TX decode failed")
+	}
+	return tx, nil
+}
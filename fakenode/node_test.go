@@ -0,0 +1,240 @@
+package fakenode_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/libsv/go-bc"
+	"github.com/libsv/go-bn"
+	"github.com/libsv/go-bn/fakenode"
+	"github.com/libsv/go-bn/models"
+	"github.com/libsv/go-bt/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNode_CreateAndSendTx(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	c := bn.NewNodeClient(bn.WithCustomRPC(fakenode.New()))
+
+	addr, err := c.NewAddress(ctx, nil)
+	assert.NoError(t, err)
+	hashes, err := c.GenerateToAddress(ctx, 101, addr, nil)
+	assert.NoError(t, err)
+	assert.Len(t, hashes, 101)
+
+	utxos, err := c.ListUnspent(ctx, nil)
+	assert.NoError(t, err)
+	assert.Len(t, utxos, 1)
+	assert.Equal(t, uint64(50*1e8), utxos[0].Satoshis)
+
+	payee, err := c.NewAddress(ctx, nil)
+	assert.NoError(t, err)
+	tx := bt.NewTx()
+	assert.NoError(t, tx.AddP2PKHOutputFromAddress(payee, 20000))
+
+	funded, err := c.FundRawTransaction(ctx, tx, nil)
+	assert.NoError(t, err)
+	assert.Len(t, funded.Tx.Inputs, 1)
+	assert.Len(t, funded.Tx.Outputs, 2)
+	assert.NotZero(t, funded.Fee)
+
+	signed, err := c.SignRawTransaction(ctx, funded.Tx, nil)
+	assert.NoError(t, err)
+	assert.True(t, signed.Complete)
+
+	txID, err := c.SendRawTransaction(ctx, signed.Tx, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, signed.Tx.TxID(), txID)
+
+	_, err = c.SendRawTransaction(ctx, signed.Tx, nil)
+	assert.True(t, errors.Is(err, models.ErrRejected))
+
+	got, err := c.RawTransaction(ctx, txID)
+	assert.NoError(t, err)
+	assert.Equal(t, signed.Tx.String(), got.String())
+
+	entry, err := c.MempoolEntry(ctx, txID)
+	assert.NoError(t, err)
+	assert.Equal(t, uint32(signed.Tx.Size()), entry.Size)
+	assert.Equal(t, funded.Fee, uint64(entry.Fee*1e8))
+	ids, err := c.RawMempoolIDs(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{txID}, ids)
+
+	out, err := c.Output(ctx, txID, 0, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(20000), out.Satoshis)
+	assert.Zero(t, out.Confirmations)
+
+	hashes, err = c.Generate(ctx, 1, nil)
+	assert.NoError(t, err)
+	block, err := c.Block(ctx, hashes[0])
+	assert.NoError(t, err)
+	assert.Len(t, block.Txs, 2)
+	assert.Equal(t, txID, block.Txs[1].TxID())
+	assert.Equal(t, uint64(102), block.Height)
+	assert.Equal(t, uint64(50*1e8)+funded.Fee, block.Txs[0].TotalOutputSatoshis())
+
+	out, err = c.Output(ctx, txID, 0, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, uint32(1), out.Confirmations)
+	utxos, err = c.ListUnspent(ctx, &models.OptsListUnspent{MinConf: 1, MaxConf: 9999999})
+	assert.NoError(t, err)
+	for _, u := range utxos {
+		assert.NotEqual(t, funded.Tx.Inputs[0].PreviousTxIDStr(), u.TxIDStr())
+	}
+
+	_, err = c.SendRawTransaction(ctx, signed.Tx, nil)
+	assert.True(t, errors.Is(err, models.ErrAlreadyInChain))
+}
+
+func TestNode_Block(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	c := bn.NewNodeClient(bn.WithCustomRPC(fakenode.New()))
+
+	hashes, err := c.Generate(ctx, 3, nil)
+	assert.NoError(t, err)
+
+	raw, err := c.BlockHex(ctx, hashes[1])
+	assert.NoError(t, err)
+	b, err := bc.NewBlockFromStr(raw)
+	assert.NoError(t, err)
+	assert.True(t, b.BlockHeader.Valid())
+	root, err := bc.BuildMerkleRoot([]string{b.Txs[0].TxID()})
+	assert.NoError(t, err)
+	assert.Equal(t, root, b.BlockHeader.HashMerkleRootStr())
+
+	header, err := c.BlockDecodeHeader(ctx, hashes[1])
+	assert.NoError(t, err)
+	assert.Equal(t, hashes[1], header.Hash)
+	assert.Equal(t, hashes[2], header.NextBlockHash)
+	assert.Equal(t, int64(2), header.Confirmations)
+	assert.Equal(t, hashes[0], header.HashPrevBlockStr())
+	blockHeader, err := c.BlockHeader(ctx, hashes[1])
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(2), blockHeader.Height)
+	assert.Equal(t, hashes[0], blockHeader.HashPrevBlockStr())
+	assert.Equal(t, b.BlockHeader.String(), blockHeader.String())
+
+	best, err := c.BestBlockHash(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, hashes[2], best)
+	count, err := c.BlockCount(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, uint32(3), count)
+	hash, err := c.BlockHash(ctx, 2)
+	assert.NoError(t, err)
+	assert.Equal(t, hashes[1], hash)
+
+	_, err = c.Block(ctx, "0000000000000000000000000000000000000000000000000000000000000000")
+	assert.True(t, errors.Is(err, models.ErrNotFound))
+}
+
+func TestNode_SendRawTransaction_Rejected(t *testing.T) {
+	t.Parallel()
+	tests := map[string]struct {
+		mutate func(tx *bt.Tx)
+		expErr error
+	}{
+		"missing input": {
+			mutate: func(tx *bt.Tx) {
+				_ = tx.Inputs[0].PreviousTxIDAddStr("0000000000000000000000000000000000000000000000000000000000000001")
+			},
+			expErr: models.ErrMissingInputs,
+		},
+		"bad signature": {
+			mutate: func(tx *bt.Tx) {
+				tx.Outputs[0].Satoshis++
+			},
+			expErr: models.ErrRejected,
+		},
+		"outputs exceed inputs": {
+			mutate: func(tx *bt.Tx) {
+				tx.Outputs[0].Satoshis = 100 * 1e8
+			},
+			expErr: models.ErrRejected,
+		},
+	}
+
+	for name, test := range tests {
+		test := test
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			ctx := context.Background()
+			c := bn.NewNodeClient(bn.WithCustomRPC(fakenode.New()))
+
+			addr, err := c.NewAddress(ctx, nil)
+			assert.NoError(t, err)
+			_, err = c.GenerateToAddress(ctx, 101, addr, nil)
+			assert.NoError(t, err)
+
+			tx := bt.NewTx()
+			assert.NoError(t, tx.AddP2PKHOutputFromAddress(addr, 1000))
+			funded, err := c.FundRawTransaction(ctx, tx, nil)
+			assert.NoError(t, err)
+			signed, err := c.SignRawTransaction(ctx, funded.Tx, nil)
+			assert.NoError(t, err)
+
+			test.mutate(signed.Tx)
+			_, err = c.SendRawTransaction(ctx, signed.Tx, nil)
+			assert.True(t, errors.Is(err, test.expErr), err)
+		})
+	}
+}
+
+func TestNode_InvalidateBlock(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	node := fakenode.New()
+	c := bn.NewNodeClient(bn.WithCustomRPC(node))
+
+	addr, err := c.NewAddress(ctx, nil)
+	assert.NoError(t, err)
+	_, err = c.GenerateToAddress(ctx, 101, addr, nil)
+	assert.NoError(t, err)
+	tx := bt.NewTx()
+	assert.NoError(t, tx.AddP2PKHOutputFromAddress(addr, 1000))
+	funded, err := c.FundRawTransaction(ctx, tx, nil)
+	assert.NoError(t, err)
+	signed, err := c.SignRawTransaction(ctx, funded.Tx, nil)
+	assert.NoError(t, err)
+	txID, err := c.SendRawTransaction(ctx, signed.Tx, nil)
+	assert.NoError(t, err)
+	stale, err := c.Generate(ctx, 2, nil)
+	assert.NoError(t, err)
+
+	assert.NoError(t, node.Do(ctx, "invalidateblock", nil, stale[0]))
+	count, err := c.BlockCount(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, uint32(101), count)
+	for _, hash := range stale {
+		header, err := c.BlockHeader(ctx, hash)
+		assert.NoError(t, err)
+		assert.Equal(t, int64(-1), header.Confirmations)
+		assert.Empty(t, header.NextBlockHash)
+	}
+	spent := signed.Tx.Inputs[0]
+	out, err := c.Output(ctx, spent.PreviousTxIDStr(), int(spent.PreviousTxOutIndex), nil)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(50*1e8), out.Satoshis)
+	_, err = c.RawTransaction(ctx, txID)
+	assert.True(t, errors.Is(err, models.ErrNotFound))
+
+	// The blocks next mined fork from the invalidated block's parent.
+	hashes, err := c.Generate(ctx, 3, nil)
+	assert.NoError(t, err)
+	header, err := c.BlockHeader(ctx, hashes[0])
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(102), header.Height)
+	assert.Equal(t, int64(3), header.Confirmations)
+	assert.NotEqual(t, stale[0], hashes[0])
+	header, err = c.BlockHeader(ctx, stale[0])
+	assert.NoError(t, err)
+	assert.Equal(t, int64(-1), header.Confirmations)
+
+	err = node.Do(ctx, "invalidateblock", nil, "0000000000000000000000000000000000000000000000000000000000000000")
+	assert.True(t, errors.Is(err, models.ErrNotFound))
+}
@@ -0,0 +1,429 @@
+package fakenode
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/libsv/go-bk/bec"
+	"github.com/libsv/go-bk/wif"
+	"github.com/libsv/go-bn/models"
+	"github.com/libsv/go-bt/v2"
+	"github.com/libsv/go-bt/v2/bscript"
+	"github.com/libsv/go-bt/v2/sighash"
+)
+
+const (
+	maxConf = 9999999
+	// p2pkhUnlockingSize the size of a p2pkh unlocking script.
+	p2pkhUnlockingSize = 107
+	p2pkhOutputSize    = 34
+)
+
+type unspent struct {
+	outpoint
+	*coin
+	addr          string
+	confirmations int
+}
+
+func (k *key) lockingScript() *bscript.Script {
+	s, err := bscript.NewP2PKHFromPubKeyEC(k.priv.PubKey())
+	if err != nil {
+		panic(err)
+	}
+	return s
+}
+
+func (n *Node) newKey() (*key, error) {
+	priv, err := bec.NewPrivateKey(bec.S256())
+	if err != nil {
+		return nil, err
+	}
+	addr, err := bscript.NewAddressFromPublicKey(priv.PubKey(), n.mainnet)
+	if err != nil {
+		return nil, err
+	}
+	k := &key{priv: priv, addr: addr.AddressString}
+	n.keys[k.lockingScript().String()] = k
+	return k, nil
+}
+
+func (n *Node) newAddress(params) (interface{}, error) {
+	k, err := n.newKey()
+	if err != nil {
+		return nil, err
+	}
+	return k.addr, nil
+}
+
+// unspents the wallet's coins, oldest first, spendable at confirmations within
+// [minConf, maxConf]. Coinbase coins are held back until they are mature.
+func (n *Node) unspents(minConf, maxConf int) []*unspent {
+	var uu []*unspent
+	add := func(op outpoint, c *coin) {
+		k, ok := n.keys[c.output.LockingScript.String()]
+		if !ok {
+			return
+		}
+		if _, spent := n.spends[op]; spent {
+			return
+		}
+		conf := 0
+		if c.height > 0 {
+			conf = n.confirmations(c.height)
+		}
+		if conf < minConf || conf > maxConf || (c.coinbase && conf <= coinbaseMaturity) {
+			return
+		}
+		uu = append(uu, &unspent{outpoint: op, coin: c, addr: k.addr, confirmations: conf})
+	}
+
+	for op, c := range n.utxos {
+		add(op, c)
+	}
+	for _, id := range n.order {
+		for vout, o := range n.mempool[id].tx.Outputs {
+			add(outpoint{txID: id, vout: uint32(vout)}, &coin{output: o})
+		}
+	}
+
+	sort.Slice(uu, func(i, j int) bool {
+		if uu[i].confirmations != uu[j].confirmations {
+			return uu[i].confirmations > uu[j].confirmations
+		}
+		if uu[i].txID != uu[j].txID {
+			return uu[i].txID < uu[j].txID
+		}
+		return uu[i].vout < uu[j].vout
+	})
+	return uu
+}
+
+func (n *Node) listUnspent(pp params) (interface{}, error) {
+	minConf, err := pp.int(0, 1)
+	if err != nil {
+		return nil, err
+	}
+	maxConf, err := pp.int(1, maxConf)
+	if err != nil {
+		return nil, err
+	}
+	var addrs []string
+	if err = pp.decode(2, &addrs); err != nil {
+		return nil, err
+	}
+
+	resp := []map[string]interface{}{}
+	for _, u := range n.unspents(minConf, maxConf) {
+		if len(addrs) > 0 && !contains(addrs, u.addr) {
+			continue
+		}
+		resp = append(resp, map[string]interface{}{
+			"txid":          u.txID,
+			"vout":          u.vout,
+			"address":       u.addr,
+			"scriptPubKey":  u.output.LockingScriptHexString(),
+			"amount":        float64(u.output.Satoshis) / 1e8,
+			"confirmations": u.confirmations,
+			"spendable":     true,
+			"solvable":      true,
+			"safe":          u.confirmations > 0,
+		})
+	}
+	return resp, nil
+}
+
+func (n *Node) fundRawTransaction(pp params) (interface{}, error) {
+	tx, err := pp.tx(0)
+	if err != nil {
+		return nil, err
+	}
+	var opts struct {
+		ChangeAddress  string  `json:"changeAddress"`
+		ChangePosition *int    `json:"changePosition"`
+		FeeRate        float64 `json:"feeRate"`
+	}
+	if err = pp.decode(1, &opts); err != nil {
+		return nil, err
+	}
+	feeRate := n.feeRate
+	if opts.FeeRate > 0 {
+		// The node takes the fee rate in BSV per kilobyte.
+		feeRate = uint64(opts.FeeRate * 1e8)
+	}
+
+	have, err := n.addInputs(tx, feeRate)
+	if err != nil {
+		return nil, err
+	}
+
+	fee := n.feeFor(tx, feeRate)
+	changePos := -1
+	if change := have - tx.TotalOutputSatoshis() - fee; change > 0 {
+		if changePos, err = n.addChange(tx, change, opts.ChangeAddress, opts.ChangePosition); err != nil {
+			return nil, err
+		}
+	}
+
+	return map[string]interface{}{
+		"hex":       tx.String(),
+		"fee":       float64(have-tx.TotalOutputSatoshis()) / 1e8,
+		"changepos": changePos,
+	}, nil
+}
+
+// addInputs add wallet coins to the tx until its inputs cover its outputs and
+// the fee, should it take change, returning the inputs' total.
+func (n *Node) addInputs(tx *bt.Tx, feeRate uint64) (uint64, error) {
+	var have uint64
+	used := map[outpoint]bool{}
+	for _, in := range tx.Inputs {
+		op := outpoint{txID: in.PreviousTxIDStr(), vout: in.PreviousTxOutIndex}
+		c, ok := n.coin(op, true)
+		if !ok {
+			return 0, nodeError(models.ErrWallet, "Insufficient funds")
+		}
+		have += c.output.Satoshis
+		used[op] = true
+	}
+
+	target := tx.TotalOutputSatoshis()
+	coins := n.unspents(1, maxConf)
+	for have < target+n.feeFor(tx, feeRate) {
+		if len(coins) == 0 {
+			return 0, nodeError(models.ErrWallet, "Insufficient funds")
+		}
+		u := coins[0]
+		coins = coins[1:]
+		if used[u.outpoint] {
+			continue
+		}
+		in := &bt.Input{
+			UnlockingScript:    &bscript.Script{},
+			PreviousTxOutIndex: u.vout,
+			SequenceNumber:     0xffffffff,
+		}
+		if err := in.PreviousTxIDAddStr(u.txID); err != nil {
+			return 0, err
+		}
+		tx.Inputs = append(tx.Inputs, in)
+		have += u.output.Satoshis
+	}
+	return have, nil
+}
+
+// feeFor the fee for the tx once signed and given a change output, assuming its
+// unsigned inputs are p2pkh.
+func (n *Node) feeFor(tx *bt.Tx, feeRate uint64) uint64 {
+	size := len(tx.Bytes()) + p2pkhOutputSize
+	for _, in := range tx.Inputs {
+		if in.UnlockingScript == nil || len(*in.UnlockingScript) == 0 {
+			size += p2pkhUnlockingSize
+		}
+	}
+	return (uint64(size)*feeRate + 999) / 1000
+}
+
+func (n *Node) addChange(tx *bt.Tx, satoshis uint64, addr string, pos *int) (int, error) {
+	var s *bscript.Script
+	if addr == "" {
+		k, err := n.newKey()
+		if err != nil {
+			return 0, err
+		}
+		s = k.lockingScript()
+	} else {
+		var err error
+		if s, err = bscript.NewP2PKHFromAddress(addr); err != nil {
+			return 0, nodeError(models.ErrNotFound, "changeAddress must be a valid bitcoin address")
+		}
+	}
+
+	i := len(tx.Outputs)
+	if pos != nil {
+		if *pos < 0 || *pos > len(tx.Outputs) {
+			return 0, nodeError(models.ErrInvalidParameter, "changePosition out of bounds")
+		}
+		i = *pos
+	}
+	tx.Outputs = append(tx.Outputs, nil)
+	copy(tx.Outputs[i+1:], tx.Outputs[i:])
+	tx.Outputs[i] = &bt.Output{Satoshis: satoshis, LockingScript: s}
+	return i, nil
+}
+
+type prevOut struct {
+	TxID         string  `json:"txid"`
+	Vout         uint32  `json:"vout"`
+	ScriptPubKey string  `json:"scriptPubKey"`
+	Amount       float64 `json:"amount"`
+}
+
+type signError struct {
+	TxID      string `json:"txid"`
+	Vout      uint32 `json:"vout"`
+	ScriptSig string `json:"scriptSig"`
+	Sequence  uint32 `json:"sequence"`
+	Error     string `json:"error"`
+}
+
+func (n *Node) signRawTransaction(pp params) (interface{}, error) {
+	tx, err := pp.tx(0)
+	if err != nil {
+		return nil, err
+	}
+	var prevOuts []prevOut
+	if err = pp.decode(1, &prevOuts); err != nil {
+		return nil, err
+	}
+	var wifs []string
+	if err = pp.decode(2, &wifs); err != nil {
+		return nil, err
+	}
+	flag := sighash.AllForkID
+	if pp.has(3) {
+		s, err := pp.str(3)
+		if err != nil {
+			return nil, err
+		}
+		if flag, err = parseSigHash(s); err != nil {
+			return nil, err
+		}
+	}
+
+	keys := n.keys
+	if len(wifs) > 0 {
+		// Given keys are used instead of the wallet's.
+		if keys, err = n.keysFromWIFs(wifs); err != nil {
+			return nil, err
+		}
+	}
+	if err = n.setPrevOuts(tx, prevOuts); err != nil {
+		return nil, err
+	}
+
+	errs := []signError{}
+	for i, in := range tx.Inputs {
+		if msg := sign(tx, i, keys, flag); msg != "" {
+			errs = append(errs, signError{
+				TxID:      in.PreviousTxIDStr(),
+				Vout:      in.PreviousTxOutIndex,
+				ScriptSig: in.UnlockingScript.String(),
+				Sequence:  in.SequenceNumber,
+				Error:     msg,
+			})
+		}
+	}
+
+	resp := map[string]interface{}{
+		"hex":      tx.String(),
+		"complete": len(errs) == 0,
+	}
+	if len(errs) > 0 {
+		resp["errors"] = errs
+	}
+	return resp, nil
+}
+
+func (n *Node) keysFromWIFs(wifs []string) (map[string]*key, error) {
+	keys := make(map[string]*key, len(wifs))
+	for _, s := range wifs {
+		w, err := wif.DecodeWIF(s)
+		if err != nil {
+			return nil, nodeError(models.ErrNotFound, "Invalid private key")
+		}
+		k := &key{priv: w.PrivKey}
+		keys[k.lockingScript().String()] = k
+	}
+	return keys, nil
+}
+
+// setPrevOuts set the previous output of each of the tx's inputs, from those
+// given or the node's utxo set and mempool.
+func (n *Node) setPrevOuts(tx *bt.Tx, prevOuts []prevOut) error {
+	given := make(map[outpoint]*bt.Output, len(prevOuts))
+	for _, p := range prevOuts {
+		s, err := bscript.NewFromHexString(p.ScriptPubKey)
+		if err != nil {
+			return nodeError(models.ErrDeserialization, "scriptPubKey must be hexadecimal")
+		}
+		given[outpoint{txID: p.TxID, vout: p.Vout}] = &bt.Output{
+			Satoshis:      uint64(p.Amount*1e8 + 0.5),
+			LockingScript: s,
+		}
+	}
+
+	for _, in := range tx.Inputs {
+		op := outpoint{txID: in.PreviousTxIDStr(), vout: in.PreviousTxOutIndex}
+		o, ok := given[op]
+		if !ok {
+			c, found := n.coin(op, true)
+			if !found {
+				continue
+			}
+			o = c.output
+		}
+		in.PreviousTxScript = o.LockingScript
+		in.PreviousTxSatoshis = o.Satoshis
+	}
+	return nil
+}
+
+// sign sign the tx's input i with the key its previous output pays to, returning
+// why it could not be should it fail.
+func sign(tx *bt.Tx, i int, keys map[string]*key, flag sighash.Flag) string {
+	in := tx.Inputs[i]
+	if in.PreviousTxScript == nil {
+		return "Input not found or already spent"
+	}
+	k, ok := keys[in.PreviousTxScript.String()]
+	if !ok || !in.PreviousTxScript.IsP2PKH() {
+		return "Unable to sign input, invalid stack size (possibly missing key)"
+	}
+
+	hash, err := tx.CalcInputSignatureHash(uint32(i), flag)
+	if err != nil {
+		return err.Error()
+	}
+	sig, err := k.priv.Sign(hash)
+	if err != nil {
+		return err.Error()
+	}
+	s, err := bscript.NewP2PKHUnlockingScript(k.priv.PubKey().SerialiseCompressed(), sig.Serialise(), flag)
+	if err != nil {
+		return err.Error()
+	}
+	in.UnlockingScript = s
+	return ""
+}
+
+// parseSigHash parse a sighash type such as ALL|FORKID. FORKID is always set, as
+// the node refuses signatures without it.
+func parseSigHash(s string) (sighash.Flag, error) {
+	var flag sighash.Flag
+	for _, part := range strings.Split(s, "|") {
+		switch part {
+		case "ALL":
+			flag |= sighash.All
+		case "NONE":
+			flag |= sighash.None
+		case "SINGLE":
+			flag |= sighash.Single
+		case "ANYONECANPAY":
+			flag |= sighash.AnyOneCanPay
+		case "FORKID":
+		default:
+			return 0, nodeError(models.ErrInvalidParameter, "Invalid sighash param")
+		}
+	}
+	return flag | sighash.ForkID, nil
+}
+
+func contains(ss []string, s string) bool {
+	for _, v := range ss {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
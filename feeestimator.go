@@ -0,0 +1,264 @@
+package bn
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/libsv/go-bn/internal/util"
+	"github.com/libsv/go-bn/models"
+	"github.com/libsv/go-bt/v2"
+	"golang.org/x/sync/singleflight"
+)
+
+// feeRefreshTimeout the time a fee estimator refresh, shared by its callers rather
+// than bound by any one of their contexts, may take.
+const feeRefreshTimeout = 30 * time.Second
+
+// FeeConfidence how sure a tx paying an estimated fee rate is to be mined promptly.
+type FeeConfidence int
+
+// Fee confidences. Low estimates from the minimum fee rates of recent blocks and the
+// cheapest tenth of the mempool, Medium from their medians, and High from the average
+// fee rates of recent blocks and the dearest tenth of the mempool.
+const (
+	FeeConfidenceLow FeeConfidence = iota
+	FeeConfidenceMedium
+	FeeConfidenceHigh
+)
+
+// feeConfidences the confidences, with the percentile of the mempool's fee rates,
+// weighted by size, each is estimated from.
+var feeConfidences = map[FeeConfidence]float64{
+	FeeConfidenceLow:    0.1,
+	FeeConfidenceMedium: 0.5,
+	FeeConfidenceHigh:   0.9,
+}
+
+// FeeEstimator estimates the fee rate a tx should pay, the node having no
+// `estimatefee`, from the fee rates of recent blocks and those of the txs in the
+// mempool, recommending the greater. Estimates are cached, being refreshed once
+// stale or on Refresh. Stale estimates are served whilst refreshed in the background,
+// only the first estimate being waited upon:
+//
+//	e := bn.NewFeeEstimator(c)
+//	fq, err := e.FeeQuote(ctx, bn.FeeConfidenceMedium)
+//	rate, err := e.FeeRate(ctx, bn.FeeConfidenceMedium)
+//	ok, err := c.SetTxFee(ctx, uint64(math.Ceil(rate*1000)))
+//
+// It is safe for concurrent use.
+type FeeEstimator struct {
+	c       BlockChainClient
+	window  int
+	ttl     time.Duration
+	minRate float64
+
+	g         singleflight.Group
+	mu        sync.Mutex
+	stats     map[int]*models.BlockStats
+	rates     map[FeeConfidence]float64
+	refreshed time.Time
+}
+
+// FeeEstimatorOptFunc for setting fee estimator options.
+type FeeEstimatorOptFunc func(e *FeeEstimator)
+
+// FeeEstimatorWindow set the number of recent blocks sampled. Defaults to 6.
+func FeeEstimatorWindow(n int) FeeEstimatorOptFunc {
+	return func(e *FeeEstimator) {
+		e.window = n
+	}
+}
+
+// FeeEstimatorTTL set how long an estimate is cached before being refreshed. Defaults
+// to a minute.
+func FeeEstimatorTTL(d time.Duration) FeeEstimatorOptFunc {
+	return func(e *FeeEstimator) {
+		e.ttl = d
+	}
+}
+
+// FeeEstimatorMinRate set the lowest fee rate estimated, in satoshis per byte, such as
+// the node's minimum relay fee rate. Defaults to 0.05.
+func FeeEstimatorMinRate(satsPerByte float64) FeeEstimatorOptFunc {
+	return func(e *FeeEstimator) {
+		e.minRate = satsPerByte
+	}
+}
+
+// NewFeeEstimator returns a fee estimator for the node c is a client of.
+func NewFeeEstimator(c BlockChainClient, oo ...FeeEstimatorOptFunc) *FeeEstimator {
+	e := &FeeEstimator{
+		c:       c,
+		window:  6,
+		ttl:     time.Minute,
+		minRate: 0.05,
+		stats:   make(map[int]*models.BlockStats),
+	}
+	for _, o := range oo {
+		o(e)
+	}
+	return e
+}
+
+// FeeRate the estimated fee rate, in satoshis per byte, for the confidence. Should
+// the estimate be stale it is refreshed in the background, the stale estimate being
+// returned meanwhile.
+func (e *FeeEstimator) FeeRate(ctx context.Context, conf FeeConfidence) (float64, error) {
+	if _, ok := feeConfidences[conf]; !ok {
+		return 0, fmt.Errorf("unknown fee confidence %d", conf)
+	}
+	e.mu.Lock()
+	rates, refreshed := e.rates, e.refreshed
+	e.mu.Unlock()
+
+	switch {
+	case rates == nil:
+		if err := e.Refresh(ctx); err != nil {
+			return 0, err
+		}
+		e.mu.Lock()
+		rates = e.rates
+		e.mu.Unlock()
+	case time.Since(refreshed) >= e.ttl:
+		e.g.DoChan("refresh", e.refresh)
+	}
+	return rates[conf], nil
+}
+
+// FeeQuote a fee quote of the estimated fee rate for the confidence, for both standard
+// and data fees, which expires when the estimate goes stale.
+func (e *FeeEstimator) FeeQuote(ctx context.Context, conf FeeConfidence) (*bt.FeeQuote, error) {
+	rate, err := e.FeeRate(ctx, conf)
+	if err != nil {
+		return nil, err
+	}
+	unit := bt.FeeUnit{Satoshis: int(math.Ceil(rate * 1000)), Bytes: 1000}
+	fq := bt.NewFeeQuote()
+	for _, ft := range []bt.FeeType{bt.FeeTypeStandard, bt.FeeTypeData} {
+		fq.AddQuote(ft, &bt.Fee{FeeType: ft, MiningFee: unit, RelayFee: unit})
+	}
+	e.mu.Lock()
+	fq.UpdateExpiry(e.refreshed.Add(e.ttl).UTC())
+	e.mu.Unlock()
+	return fq, nil
+}
+
+// Refresh the estimates, sampling the blocks mined, and the mempool, since last
+// refreshed. Concurrent refreshes are shared, ctx bounding only the wait for it.
+func (e *FeeEstimator) Refresh(ctx context.Context) error {
+	select {
+	case res := <-e.g.DoChan("refresh", e.refresh):
+		return res.Err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// refresh the estimates, swapping them in once sampled, so the estimates held are
+// served whilst the node is called.
+func (e *FeeEstimator) refresh() (interface{}, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), feeRefreshTimeout)
+	defer cancel()
+
+	tip, err := e.c.BlockCount(ctx)
+	if err != nil {
+		return nil, err
+	}
+	from := int(tip) - e.window + 1
+	if from < 1 {
+		from = 1
+	}
+	stats := make(map[int]*models.BlockStats, e.window)
+	e.mu.Lock()
+	for height, s := range e.stats {
+		if height >= from && height <= int(tip) {
+			stats[height] = s
+		}
+	}
+	e.mu.Unlock()
+	for height := from; height <= int(tip); height++ {
+		if _, ok := stats[height]; ok {
+			continue
+		}
+		s, err := e.c.BlockStatsByHeight(ctx, height, "minfeerate", "medianfeerate", "avgfeerate")
+		if err != nil {
+			return nil, fmt.Errorf("block %d: %w", height, err)
+		}
+		stats[height] = s
+	}
+
+	mempool, err := e.c.RawMempool(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	rates := make(map[FeeConfidence]float64, len(feeConfidences))
+	for conf, percentile := range feeConfidences {
+		rates[conf] = math.Max(e.minRate, math.Max(feeHistory(stats, conf), mempoolFeeRate(mempool, percentile)))
+	}
+	e.mu.Lock()
+	e.stats, e.rates, e.refreshed = stats, rates, time.Now()
+	e.mu.Unlock()
+	return nil, nil
+}
+
+// feeHistory the median, across the blocks sampled, of the fee rate of each block for
+// the confidence. Blocks without fee paying txs are skipped.
+func feeHistory(stats map[int]*models.BlockStats, conf FeeConfidence) float64 {
+	var rates []float64
+	for _, s := range stats {
+		rate := s.MedianFeeRate
+		switch conf {
+		case FeeConfidenceLow:
+			rate = s.MinFeeRate
+		case FeeConfidenceHigh:
+			rate = s.AvgFeeRate
+		}
+		if rate > 0 {
+			rates = append(rates, rate)
+		}
+	}
+	if len(rates) == 0 {
+		return 0
+	}
+	sort.Float64s(rates)
+	if n := len(rates); n%2 == 0 {
+		return (rates[n/2-1] + rates[n/2]) / 2
+	}
+	return rates[len(rates)/2]
+}
+
+// mempoolFeeRate the fee rate, in satoshis per byte, at the percentile of the txs in
+// the mempool, weighted by size.
+func mempoolFeeRate(mempool models.MempoolTxs, percentile float64) float64 {
+	type bucket struct {
+		rate float64
+		size uint64
+	}
+	buckets := make([]bucket, 0, len(mempool))
+	var total uint64
+	for _, entry := range mempool {
+		if entry.Size == 0 {
+			continue
+		}
+		buckets = append(buckets, bucket{
+			rate: float64(util.BSVToSatoshis(entry.Fee)) / float64(entry.Size),
+			size: uint64(entry.Size),
+		})
+		total += uint64(entry.Size)
+	}
+	sort.Slice(buckets, func(i, j int) bool { return buckets[i].rate < buckets[j].rate })
+
+	var size uint64
+	for _, b := range buckets {
+		size += b.size
+		if float64(size) >= percentile*float64(total) {
+			return b.rate
+		}
+	}
+	return 0
+}
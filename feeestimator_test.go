@@ -0,0 +1,133 @@
+package bn_test
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/libsv/go-bn"
+	bnmocks "github.com/libsv/go-bn/mocks"
+	"github.com/libsv/go-bn/models"
+	"github.com/libsv/go-bt/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFeeEstimator(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	tip := uint32(10)
+	var fetched []int
+	c := &bnmocks.BlockChainClientMock{
+		BlockCountFunc: func(ctx context.Context) (uint32, error) {
+			return tip, nil
+		},
+		BlockStatsByHeightFunc: func(ctx context.Context, height int, fields ...string) (*models.BlockStats, error) {
+			fetched = append(fetched, height)
+			return map[int]*models.BlockStats{
+				8:  {MinFeeRate: 0.1, MedianFeeRate: 0.5, AvgFeeRate: 1},
+				9:  {MinFeeRate: 0.2, MedianFeeRate: 0.5, AvgFeeRate: 2},
+				10: {},
+				11: {MinFeeRate: 0.3, MedianFeeRate: 0.5, AvgFeeRate: 3},
+			}[height], nil
+		},
+		RawMempoolFunc: func(ctx context.Context) (models.MempoolTxs, error) {
+			return models.MempoolTxs{
+				"a": {Size: 100, Fee: 0.0000001},
+				"b": {Size: 800, Fee: 0.000008},
+				"c": {Size: 100, Fee: 0.000003},
+			}, nil
+		},
+	}
+	e := bn.NewFeeEstimator(c, bn.FeeEstimatorWindow(3))
+
+	for conf, exp := range map[bn.FeeConfidence]float64{
+		bn.FeeConfidenceLow:    0.15,
+		bn.FeeConfidenceMedium: 1,
+		bn.FeeConfidenceHigh:   1.5,
+	} {
+		rate, err := e.FeeRate(ctx, conf)
+		assert.NoError(t, err)
+		assert.InDelta(t, exp, rate, 1e-9, conf)
+	}
+	assert.Equal(t, []int{8, 9, 10}, fetched)
+	assert.Len(t, c.BlockCountCalls(), 1)
+
+	fq, err := e.FeeQuote(ctx, bn.FeeConfidenceMedium)
+	assert.NoError(t, err)
+	assert.False(t, fq.Expired())
+	for _, ft := range []bt.FeeType{bt.FeeTypeStandard, bt.FeeTypeData} {
+		fee, err := fq.Fee(ft)
+		assert.NoError(t, err)
+		assert.Equal(t, bt.FeeUnit{Satoshis: 1000, Bytes: 1000}, fee.MiningFee)
+		assert.Equal(t, bt.FeeUnit{Satoshis: 1000, Bytes: 1000}, fee.RelayFee)
+	}
+
+	tip = 11
+	assert.NoError(t, e.Refresh(ctx))
+	assert.Equal(t, []int{8, 9, 10, 11}, fetched)
+	rate, err := e.FeeRate(ctx, bn.FeeConfidenceHigh)
+	assert.NoError(t, err)
+	assert.Equal(t, 2.5, rate)
+
+	_, err = e.FeeRate(ctx, bn.FeeConfidence(3))
+	assert.Error(t, err)
+}
+
+func TestFeeEstimator_MinRate(t *testing.T) {
+	t.Parallel()
+	c := &bnmocks.BlockChainClientMock{
+		BlockCountFunc: func(ctx context.Context) (uint32, error) {
+			return 0, nil
+		},
+		RawMempoolFunc: func(ctx context.Context) (models.MempoolTxs, error) {
+			return models.MempoolTxs{}, nil
+		},
+	}
+
+	rate, err := bn.NewFeeEstimator(c).FeeRate(context.Background(), bn.FeeConfidenceHigh)
+	assert.NoError(t, err)
+	assert.Equal(t, 0.05, rate)
+	rate, err = bn.NewFeeEstimator(c, bn.FeeEstimatorMinRate(0.5)).FeeRate(context.Background(), bn.FeeConfidenceLow)
+	assert.NoError(t, err)
+	assert.Equal(t, 0.5, rate)
+}
+
+func TestFeeEstimator_StaleWhileRefreshing(t *testing.T) {
+	t.Parallel()
+	var refreshes int32
+	release := make(chan struct{})
+	c := &bnmocks.BlockChainClientMock{
+		BlockCountFunc: func(ctx context.Context) (uint32, error) {
+			return 0, nil
+		},
+		RawMempoolFunc: func(ctx context.Context) (models.MempoolTxs, error) {
+			if atomic.AddInt32(&refreshes, 1) == 1 {
+				return models.MempoolTxs{}, nil
+			}
+			<-release
+			return models.MempoolTxs{"a": {Size: 100, Fee: 0.000001}}, nil
+		},
+	}
+	e := bn.NewFeeEstimator(c, bn.FeeEstimatorTTL(time.Nanosecond))
+	rate, err := e.FeeRate(context.Background(), bn.FeeConfidenceHigh)
+	assert.NoError(t, err)
+	assert.Equal(t, 0.05, rate)
+
+	// The stale rate is served whilst the node is slow to answer the refresh.
+	for i := 0; i < 3; i++ {
+		rate, err = e.FeeRate(context.Background(), bn.FeeConfidenceHigh)
+		assert.NoError(t, err)
+		assert.Equal(t, 0.05, rate)
+	}
+
+	// A caller giving up on the refresh does not fail it for the others.
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond)
+	defer cancel()
+	assert.ErrorIs(t, e.Refresh(ctx), context.DeadlineExceeded)
+	close(release)
+	assert.Eventually(t, func() bool {
+		rate, err := e.FeeRate(context.Background(), bn.FeeConfidenceHigh)
+		return err == nil && rate == 1
+	}, time.Second, time.Millisecond)
+}
@@ -0,0 +1,25 @@
+package config
+
+import (
+	"time"
+
+	"github.com/libsv/go-bn/zmq"
+)
+
+// Cache policies, being how long a method's results are cached for. Any positive
+// duration caches for that long.
+const (
+	CacheNever     time.Duration = 0
+	CacheImmutable time.Duration = -1
+)
+
+// Cache config.
+type Cache struct {
+	MaxEntries int
+	// TTL how long results of volatile methods, such as `getbestblockhash`, are cached for.
+	TTL time.Duration
+	// Methods policy overrides per method.
+	Methods map[string]time.Duration
+	// NodeMQ when set, cached results are evicted as blocks arrive and txs leave the mempool.
+	NodeMQ zmq.NodeMQ
+}
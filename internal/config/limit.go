@@ -0,0 +1,24 @@
+package config
+
+import "time"
+
+// Limit config.
+type Limit struct {
+	// Default the budget of methods whose class has no budget of its own.
+	Default LimitClass
+	// Classes budgets keyed by method class.
+	Classes map[string]LimitClass
+	// Methods assigns methods to classes, overriding the built-in classes.
+	Methods map[string]string
+	// BaseBackoff the pause after the node first answers 503, doubling with each
+	// consecutive 503 up to MaxBackoff.
+	BaseBackoff time.Duration
+	MaxBackoff  time.Duration
+}
+
+// LimitClass a budget of calls. Zero values are unlimited.
+type LimitClass struct {
+	MaxInFlight int
+	PerSecond   float64
+	Burst       int
+}
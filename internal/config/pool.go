@@ -0,0 +1,10 @@
+package config
+
+import "time"
+
+// Pool config. The first node is the primary.
+type Pool struct {
+	Nodes               []*RPC
+	MaxLag              uint32
+	HealthCheckInterval time.Duration
+}
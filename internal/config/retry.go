@@ -0,0 +1,12 @@
+package config
+
+import "time"
+
+// Retry config.
+type Retry struct {
+	MaxAttempts int
+	BaseDelay   time.Duration
+	MaxDelay    time.Duration
+	// Methods which are retried in addition to the idempotent reads.
+	Methods map[string]bool
+}
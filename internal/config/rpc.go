@@ -5,4 +5,10 @@ type RPC struct {
 	Host     string
 	Username string
 	Password string
+	// CookieFile the path to the node's .cookie file. When set, credentials are
+	// read from the file instead of Username and Password.
+	CookieFile string
+	// OnDedupe called when a call to the method is served by an identical call
+	// already in flight.
+	OnDedupe func(method string)
 }
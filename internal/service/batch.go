@@ -0,0 +1,13 @@
+package service
+
+import (
+	"context"
+
+	bnrpc "github.com/libsv/go-bn/rpc"
+)
+
+// DoBatch send the calls as a single batch if the RPC supports it, otherwise
+// send each call individually.
+func DoBatch(ctx context.Context, rpc RPC, calls ...*Call) error {
+	return bnrpc.DoBatch(ctx, rpc, calls...)
+}
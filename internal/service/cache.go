@@ -1,57 +1,369 @@
 package service
 
 import (
+	"container/list"
 	"context"
 	"encoding/json"
-	"reflect"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/libsv/go-bn/internal/config"
+	"github.com/libsv/go-bn/models"
+	"github.com/libsv/go-bn/zmq"
 )
 
+// immutableMethods methods whose results never change for the same args.
+var immutableMethods = map[string]bool{
+	"createmultisig":         true,
+	"getblockstats":          true,
+	"getmerkleproof2":        true,
+	"signmessagewithprivkey": true,
+	"verifymessage":          true,
+}
+
+// verboseMethods methods whose results never change for the same args in their raw,
+// hex form, but whose verbose form includes the confirmations, next block and, of a
+// tx, block hash, which change as blocks arrive. Verbose results are cached as those
+// of volatileMethods and tipMethods are.
+var verboseMethods = map[string]bool{
+	"getblock":          true,
+	"getblockheader":    true,
+	"getrawtransaction": true,
+}
+
+// volatileMethods methods which read state that changes as the node runs, and so
+// are cached briefly. Any method not listed here or in immutableMethods is never cached.
+var volatileMethods = map[string]bool{
+	"activezmqnotifications": true,
+	"dumpparameters":         true,
+	"getaddednodeinfo":       true,
+	"getbestblockhash":       true,
+	"getblockbyheight":       true,
+	"getblockchaininfo":      true,
+	"getblockcount":          true,
+	"getblockhash":           true,
+	"getblockstatsbyheight":  true,
+	"getchaintips":           true,
+	"getchaintxstats":        true,
+	"getconnectioncount":     true,
+	"getdifficulty":          true,
+	"getexcessiveblock":      true,
+	"getinfo":                true,
+	"getmempoolancestors":    true,
+	"getmempooldescendants":  true,
+	"getmempoolentry":        true,
+	"getmerkleproof":         true,
+	"getmininginfo":          true,
+	"getnettotals":           true,
+	"getnetworkhashps":       true,
+	"getnetworkinfo":         true,
+	"getpeerinfo":            true,
+	"getrawmempool":          true,
+	"getrawnonfinalmempool":  true,
+	"getsettings":            true,
+	"gettxout":               true,
+	"gettxoutsetinfo":        true,
+	"listbanned":             true,
+}
+
+// tipMethods methods whose results depend upon the chain tip, and so are evicted
+// when a block arrives.
+var tipMethods = map[string]bool{
+	"getbestblockhash":      true,
+	"getblockbyheight":      true,
+	"getblockchaininfo":     true,
+	"getblockcount":         true,
+	"getblockhash":          true,
+	"getblockstatsbyheight": true,
+	"getchaintips":          true,
+	"getchaintxstats":       true,
+	"getdifficulty":         true,
+	"getinfo":               true,
+	"getmempoolancestors":   true,
+	"getmempooldescendants": true,
+	"getmempoolentry":       true,
+	"getmerkleproof":        true,
+	"getmininginfo":         true,
+	"getnetworkhashps":      true,
+	"getrawmempool":         true,
+	"getrawnonfinalmempool": true,
+	"gettxout":              true,
+	"gettxoutsetinfo":       true,
+}
+
+// mempoolMethods methods whose results may include any tx in the mempool, and so
+// are evicted when a tx leaves the mempool.
+var mempoolMethods = map[string]bool{
+	"getmempoolancestors":   true,
+	"getmempooldescendants": true,
+	"getrawmempool":         true,
+	"getrawnonfinalmempool": true,
+}
+
+// txMethods methods whose results concern the tx given as their first arg.
+var txMethods = map[string]bool{
+	"getmempoolentry": true,
+	"gettxout":        true,
+}
+
+type cacheEntry struct {
+	key     string
+	method  string
+	txID    string
+	tip     bool
+	result  json.RawMessage
+	expires time.Time
+}
+
 type cache struct {
-	rpc   RPC
-	cache map[string]interface{}
+	rpc RPC
+	cfg *config.Cache
+
+	mu      sync.Mutex
+	lru     *list.List
+	entries map[string]*list.Element
+	stats   models.CacheStats
 }
 
-// NewCache returns a cache wrapper around an RPC service.
-func NewCache(rpc RPC) RPC {
+// NewCache returns a cache wrapper around an RPC service. Results are cached
+// according to the policy of their method and evicted, least recently used first,
+// once cfg.MaxEntries is reached.
+func NewCache(rpc RPC, cfg *config.Cache) RPC {
 	return &cache{
-		rpc:   rpc,
-		cache: make(map[string]interface{}),
+		rpc:     rpc,
+		cfg:     cfg,
+		lru:     list.New(),
+		entries: make(map[string]*list.Element),
+	}
+}
+
+// Subscribe evict cached results as the node announces new blocks and txs leaving
+// its mempool. The topics are subscribed to through zmq.Shared, so are shared with
+// other subscribers of mq. Should any subscription fail, those made are undone.
+func (c *cache) Subscribe(mq zmq.NodeMQ) error {
+	mq = zmq.Shared(mq)
+	onDiscard := func(_ context.Context, d *zmq.MempoolDiscard) {
+		c.InvalidateTx(d.TxID)
+	}
+	err := mq.SubscribeHashBlock(func(context.Context, string) {
+		c.InvalidateBlock()
+	})
+	if err == nil {
+		err = mq.SubscribeDiscardFromMempool(onDiscard)
+	}
+	if err == nil {
+		err = mq.SubscribeRemovedFromMempoolBlock(onDiscard)
+	}
+	if err != nil {
+		for _, t := range []zmq.Topic{
+			zmq.TopicHashBlock, zmq.TopicDiscardFromMempool, zmq.TopicRemovedFromMempoolBlock,
+		} {
+			_ = mq.Unsubscribe(t)
+		}
+	}
+	return err
+}
+
+// InvalidateBlock evict results which depend upon the chain tip. Results which
+// never change, such as raw blocks by hash, are kept.
+func (c *cache) InvalidateBlock() {
+	c.evict(func(e *cacheEntry) bool {
+		return e.tip
+	})
+}
+
+// InvalidateTx evict results listing the mempool, and those concerning the tx.
+func (c *cache) InvalidateTx(txID string) {
+	c.evict(func(e *cacheEntry) bool {
+		return mempoolMethods[e.method] || (txMethods[e.method] && e.txID == txID)
+	})
+}
+
+func (c *cache) evict(fn func(e *cacheEntry) bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for el := c.lru.Front(); el != nil; {
+		next := el.Next()
+		if fn(el.Value.(*cacheEntry)) {
+			c.remove(el)
+		}
+		el = next
 	}
 }
 
 // Do an RPC request with cache enabled.
 func (c *cache) Do(ctx context.Context, method string, out interface{}, args ...interface{}) error {
-	return c.do(ctx, request{method: method, args: args}, out)
+	return c.do(ctx, newRequest(ctx, method, args), out)
 }
 
 func (c *cache) do(ctx context.Context, r request, out interface{}) error {
-	if v, ok := c.cache[r.Key()]; ok && out != nil {
-		return c.write(v, out)
+	ttl := c.ttl(r)
+	if ttl == config.CacheNever || out == nil {
+		return c.rpc.Do(ctx, r.method, out, r.args...)
+	}
+
+	key := r.Key()
+	if result, ok := c.get(key); ok {
+		return DecodeResult(result, out)
 	}
-	if err := c.rpc.Do(ctx, r.method, out, r.args...); err != nil {
+	var result json.RawMessage
+	if err := c.rpc.Do(ctx, r.method, &result, r.args...); err != nil {
 		return err
 	}
 
-	c.cache[r.Key()] = out
+	c.set(r, result, ttl)
+	return DecodeResult(result, out)
+}
+
+// cacheMiss a batched call whose result is to be cached, sent in place of the caller's
+// call so as to read its raw result.
+type cacheMiss struct {
+	call   *Call
+	req    request
+	ttl    time.Duration
+	result json.RawMessage
+	sent   *Call
+}
+
+// DoBatch serve the calls from cache where possible, sending the remainder as a batch.
+func (c *cache) DoBatch(ctx context.Context, calls ...*Call) error {
+	sent := make([]*Call, 0, len(calls))
+	var misses []*cacheMiss
+	for _, call := range calls {
+		r := newRequest(ctx, call.Method, call.Args)
+		ttl := c.ttl(r)
+		if call.Out == nil || ttl == config.CacheNever {
+			sent = append(sent, call)
+			continue
+		}
+		if result, ok := c.get(r.Key()); ok {
+			call.Err = DecodeResult(result, call.Out)
+			continue
+		}
+		m := &cacheMiss{call: call, req: r, ttl: ttl}
+		m.sent = &Call{Method: call.Method, Args: call.Args, Out: &m.result}
+		sent = append(sent, m.sent)
+		misses = append(misses, m)
+	}
+
+	if err := DoBatch(ctx, c.rpc, sent...); err != nil {
+		return err
+	}
+
+	for _, m := range misses {
+		if m.sent.Err != nil {
+			m.call.Err = m.sent.Err
+			continue
+		}
+		c.set(m.req, m.result, m.ttl)
+		m.call.Err = DecodeResult(m.result, m.call.Out)
+	}
+
 	return nil
 }
 
-func (c *cache) write(dest, src interface{}) error {
-	drv := reflect.ValueOf(dest)
-	if drv.Kind() != reflect.Ptr || drv.IsNil() {
-		return &json.InvalidUnmarshalError{Type: reflect.TypeOf(dest)}
+// DoStream an RPC request streaming its result. Streamed results are never cached.
+func (c *cache) DoStream(ctx context.Context, method string, fn StreamFunc, args ...interface{}) error {
+	return DoStream(ctx, c.rpc, method, fn, args...)
+}
+
+// Stats the cache's hit, miss and eviction counts.
+func (c *cache) Stats() models.CacheStats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	stats := c.stats
+	stats.Entries = c.lru.Len()
+	return stats
+}
+
+// ttl how long results of the request are cached for.
+func (c *cache) ttl(r request) time.Duration {
+	if ttl, ok := c.cfg.Methods[r.method]; ok {
+		return ttl
+	}
+	if immutableMethods[r.method] || (verboseMethods[r.method] && raw(r)) {
+		return config.CacheImmutable
+	}
+	if volatileMethods[r.method] || verboseMethods[r.method] {
+		return c.cfg.TTL
+	}
+
+	return config.CacheNever
+}
+
+// raw whether the request, of one of verboseMethods, asks for the raw, hex form,
+// being the default only of getrawtransaction. The verbosity is given as a bool,
+// level or, of getblock, name.
+func raw(r request) bool {
+	if len(r.args) < 2 {
+		return r.method == "getrawtransaction"
+	}
+	switch fmt.Sprint(r.args[1]) {
+	case "false", "0", string(models.VerbosityRawBlock):
+		return true
 	}
+	return false
+}
+
+func (c *cache) get(key string) (json.RawMessage, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
 
-	for drv.Kind() == reflect.Ptr {
-		drv = reflect.Indirect(drv)
+	el, ok := c.entries[key]
+	if !ok {
+		c.stats.Misses++
+		return nil, false
 	}
 
-	srv := reflect.ValueOf(src)
-	for srv.Kind() == reflect.Ptr {
-		srv = reflect.Indirect(srv)
+	e := el.Value.(*cacheEntry)
+	if !e.expires.IsZero() && time.Now().After(e.expires) {
+		c.remove(el)
+		c.stats.Misses++
+		return nil, false
 	}
 
-	drv.Set(srv)
+	c.lru.MoveToFront(el)
+	c.stats.Hits++
+	return e.result, true
+}
 
-	return nil
+// set cache the raw result, which is decoded afresh for each hit so callers never
+// share the values decoded.
+func (c *cache) set(r request, result json.RawMessage, ttl time.Duration) {
+	e := &cacheEntry{
+		key:    r.Key(),
+		method: r.method,
+		tip:    tipMethods[r.method] || (verboseMethods[r.method] && !raw(r)),
+		result: result,
+	}
+	if len(r.args) > 0 {
+		e.txID, _ = r.args[0].(string)
+	}
+	if ttl > 0 {
+		e.expires = time.Now().Add(ttl)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if el, ok := c.entries[e.key]; ok {
+		el.Value = e
+		c.lru.MoveToFront(el)
+		return
+	}
+
+	c.entries[e.key] = c.lru.PushFront(e)
+	for c.cfg.MaxEntries > 0 && c.lru.Len() > c.cfg.MaxEntries {
+		c.remove(c.lru.Back())
+		c.stats.Evictions++
+	}
+}
+
+// remove an entry. The lock must be held.
+func (c *cache) remove(el *list.Element) {
+	c.lru.Remove(el)
+	delete(c.entries, el.Value.(*cacheEntry).key)
 }
@@ -0,0 +1,335 @@
+package service_test
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/libsv/go-bn/internal/config"
+	"github.com/libsv/go-bn/internal/mocks"
+	"github.com/libsv/go-bn/internal/service"
+	bnmocks "github.com/libsv/go-bn/mocks"
+	"github.com/libsv/go-bn/models"
+	bnrpc "github.com/libsv/go-bn/rpc"
+	"github.com/libsv/go-bn/zmq"
+	"github.com/stretchr/testify/assert"
+)
+
+type cacheStats interface {
+	Stats() models.CacheStats
+}
+
+type cacheSubscriber interface {
+	Subscribe(zmq.NodeMQ) error
+}
+
+// reply write the result to out as the http transport would.
+func reply(t *testing.T, out, result interface{}) error {
+	bb, err := json.Marshal(result)
+	assert.NoError(t, err)
+	return service.DecodeResult(bb, out)
+}
+
+func TestCache_Do(t *testing.T) {
+	t.Parallel()
+
+	type call struct {
+		method string
+		args   []interface{}
+		wallet string
+		wait   time.Duration
+	}
+	tests := map[string]struct {
+		cfg      config.Cache
+		calls    []call
+		expCalls int
+		expStats models.CacheStats
+	}{
+		"immutable method is cached": {
+			cfg: config.Cache{TTL: time.Minute},
+			calls: []call{
+				{method: "getrawtransaction", args: []interface{}{"abc"}},
+				{method: "getrawtransaction", args: []interface{}{"abc"}},
+			},
+			expCalls: 1,
+			expStats: models.CacheStats{Hits: 1, Misses: 1, Entries: 1},
+		},
+		"raw form of verbose method is cached": {
+			cfg: config.Cache{TTL: 10 * time.Millisecond},
+			calls: []call{
+				{method: "getblock", args: []interface{}{"a", "RAW_BLOCK"}},
+				{method: "getblock", args: []interface{}{"a", "RAW_BLOCK"}, wait: 20 * time.Millisecond},
+				{method: "getblockheader", args: []interface{}{"a", false}},
+				{method: "getblockheader", args: []interface{}{"a", false}, wait: 20 * time.Millisecond},
+			},
+			expCalls: 2,
+			expStats: models.CacheStats{Hits: 2, Misses: 2, Entries: 2},
+		},
+		"verbose form of verbose method expires": {
+			cfg: config.Cache{TTL: 10 * time.Millisecond},
+			calls: []call{
+				{method: "getrawtransaction", args: []interface{}{"abc", true}},
+				{method: "getrawtransaction", args: []interface{}{"abc", true}},
+				{method: "getrawtransaction", args: []interface{}{"abc", true}, wait: 20 * time.Millisecond},
+			},
+			expCalls: 2,
+			expStats: models.CacheStats{Hits: 1, Misses: 2, Entries: 1},
+		},
+		"different args are cached separately": {
+			cfg: config.Cache{TTL: time.Minute},
+			calls: []call{
+				{method: "getrawtransaction", args: []interface{}{"abc", true}},
+				{method: "getrawtransaction", args: []interface{}{"def", true}},
+				{method: "getrawtransaction", args: []interface{}{"abc", true}},
+			},
+			expCalls: 2,
+			expStats: models.CacheStats{Hits: 1, Misses: 2, Entries: 2},
+		},
+		"wallets are cached separately": {
+			cfg: config.Cache{TTL: time.Minute},
+			calls: []call{
+				{method: "getrawtransaction", args: []interface{}{"abc", true}},
+				{method: "getrawtransaction", args: []interface{}{"abc", true}, wallet: "alice"},
+				{method: "getrawtransaction", args: []interface{}{"abc", true}, wallet: "bob"},
+				{method: "getrawtransaction", args: []interface{}{"abc", true}, wallet: "alice"},
+			},
+			expCalls: 3,
+			expStats: models.CacheStats{Hits: 1, Misses: 3, Entries: 3},
+		},
+		"volatile method expires": {
+			cfg: config.Cache{TTL: 10 * time.Millisecond},
+			calls: []call{
+				{method: "getbestblockhash"},
+				{method: "getbestblockhash"},
+				{method: "getbestblockhash", wait: 20 * time.Millisecond},
+			},
+			expCalls: 2,
+			expStats: models.CacheStats{Hits: 1, Misses: 2, Entries: 1},
+		},
+		"wallet method is never cached": {
+			cfg: config.Cache{TTL: time.Minute},
+			calls: []call{
+				{method: "getbalance"},
+				{method: "getbalance"},
+			},
+			expCalls: 2,
+		},
+		"method policy can be overridden": {
+			cfg: config.Cache{
+				TTL:     time.Minute,
+				Methods: map[string]time.Duration{"getrawtransaction": config.CacheNever},
+			},
+			calls: []call{
+				{method: "getrawtransaction", args: []interface{}{"abc", true}},
+				{method: "getrawtransaction", args: []interface{}{"abc", true}},
+			},
+			expCalls: 2,
+		},
+		"least recently used is evicted": {
+			cfg: config.Cache{TTL: time.Minute, MaxEntries: 2},
+			calls: []call{
+				{method: "getblock", args: []interface{}{"a"}},
+				{method: "getblock", args: []interface{}{"b"}},
+				{method: "getblock", args: []interface{}{"a"}},
+				{method: "getblock", args: []interface{}{"c"}},
+				{method: "getblock", args: []interface{}{"a"}},
+				{method: "getblock", args: []interface{}{"b"}},
+			},
+			expCalls: 4,
+			expStats: models.CacheStats{Hits: 2, Misses: 4, Evictions: 2, Entries: 2},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			var calls int
+			c := service.NewCache(&mocks.MockRPC{
+				DoFunc: func(ctx context.Context, method string, out interface{}, args ...interface{}) error {
+					calls++
+					return reply(t, out, method)
+				},
+			}, &test.cfg)
+
+			for _, call := range test.calls {
+				time.Sleep(call.wait)
+				ctx := context.TODO()
+				if call.wallet != "" {
+					ctx = bnrpc.WithWallet(ctx, call.wallet)
+				}
+				var out string
+				assert.NoError(t, c.Do(ctx, call.method, &out, call.args...))
+				assert.Equal(t, call.method, out)
+			}
+
+			assert.Equal(t, test.expCalls, calls)
+			assert.Equal(t, test.expStats, c.(cacheStats).Stats())
+		})
+	}
+}
+
+func TestCache_Do_Concurrent(t *testing.T) {
+	t.Parallel()
+
+	c := service.NewCache(&mocks.MockRPC{
+		DoFunc: func(ctx context.Context, method string, out interface{}, args ...interface{}) error {
+			return reply(t, out, args[0])
+		},
+	}, &config.Cache{MaxEntries: 10, TTL: time.Minute})
+
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				var out string
+				arg := string(rune('a' + (i+j)%26))
+				assert.NoError(t, c.Do(context.TODO(), "getblock", &out, arg))
+				assert.Equal(t, arg, out)
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	assert.Equal(t, 10, c.(cacheStats).Stats().Entries)
+}
+
+func TestCache_Do_NotShared(t *testing.T) {
+	t.Parallel()
+
+	c := service.NewCache(&mocks.MockRPC{
+		DoFunc: func(ctx context.Context, method string, out interface{}, args ...interface{}) error {
+			return reply(t, out, map[string][]string{"tx": {"a", "b"}})
+		},
+	}, &config.Cache{TTL: time.Minute})
+
+	for i := 0; i < 2; i++ {
+		var out struct {
+			Txs []string `json:"tx"`
+		}
+		assert.NoError(t, c.Do(context.TODO(), "getblock", &out, "blockhash", "RAW_BLOCK"))
+		assert.Equal(t, []string{"a", "b"}, out.Txs)
+		out.Txs[0] = "c"
+	}
+
+	calls := []*service.Call{
+		{Method: "getblock", Args: []interface{}{"blockhash", "RAW_BLOCK"}, Out: &map[string][]string{}},
+		{Method: "getblock", Args: []interface{}{"otherhash", "RAW_BLOCK"}, Out: &map[string][]string{}},
+	}
+	assert.NoError(t, c.(bnrpc.BatchRPC).DoBatch(context.TODO(), calls...))
+	for _, call := range calls {
+		assert.NoError(t, call.Err)
+		assert.Equal(t, &map[string][]string{"tx": {"a", "b"}}, call.Out)
+	}
+	assert.Equal(t, models.CacheStats{Hits: 2, Misses: 2, Entries: 2}, c.(cacheStats).Stats())
+}
+
+func TestCache_Subscribe(t *testing.T) {
+	t.Parallel()
+
+	type call struct {
+		method string
+		args   []interface{}
+	}
+	tests := map[string]struct {
+		event    func(onBlock zmq.HashFunc, onDiscard zmq.DiscardFunc)
+		expCalls []call
+	}{
+		"new block evicts tip results": {
+			event: func(onBlock zmq.HashFunc, _ zmq.DiscardFunc) {
+				onBlock(context.TODO(), "blockhash")
+			},
+			expCalls: []call{
+				{method: "getblock", args: []interface{}{"blockhash"}},
+				{method: "getblockcount"},
+				{method: "getmempoolentry", args: []interface{}{"tx1"}},
+				{method: "getmempoolentry", args: []interface{}{"tx2"}},
+				{method: "getrawmempool"},
+			},
+		},
+		"tx leaving mempool evicts its results": {
+			event: func(_ zmq.HashFunc, onDiscard zmq.DiscardFunc) {
+				onDiscard(context.TODO(), &zmq.MempoolDiscard{TxID: "tx1"})
+			},
+			expCalls: []call{
+				{method: "getmempoolentry", args: []interface{}{"tx1"}},
+				{method: "getrawmempool"},
+			},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			var calls []call
+			c := service.NewCache(&mocks.MockRPC{
+				DoFunc: func(ctx context.Context, method string, out interface{}, args ...interface{}) error {
+					calls = append(calls, call{method: method, args: args})
+					return reply(t, out, method)
+				},
+			}, &config.Cache{TTL: time.Minute})
+
+			subs := make(map[zmq.Topic]zmq.MessageFunc)
+			mq := &bnmocks.NodeMQMock{
+				SubscribeFunc: func(topic zmq.Topic, fn zmq.MessageFunc) error {
+					subs[topic] = fn
+					return nil
+				},
+			}
+			assert.NoError(t, c.(cacheSubscriber).Subscribe(mq))
+			onBlock := func(ctx context.Context, hash string) {
+				subs[zmq.TopicHashBlock](ctx, [][]byte{[]byte(zmq.TopicHashBlock), []byte(hash)})
+			}
+			onDiscard := func(ctx context.Context, d *zmq.MempoolDiscard) {
+				bb, err := json.Marshal(d)
+				assert.NoError(t, err)
+				subs[zmq.TopicDiscardFromMempool](ctx, [][]byte{[]byte(zmq.TopicDiscardFromMempool), bb})
+			}
+
+			all := []call{
+				{method: "getblock", args: []interface{}{"blockhash", "RAW_BLOCK"}},
+				{method: "getblock", args: []interface{}{"blockhash"}},
+				{method: "getblockcount"},
+				{method: "getmempoolentry", args: []interface{}{"tx1"}},
+				{method: "getmempoolentry", args: []interface{}{"tx2"}},
+				{method: "getrawmempool"},
+			}
+			do := func() {
+				for _, cl := range all {
+					var out string
+					assert.NoError(t, c.Do(context.TODO(), cl.method, &out, cl.args...))
+				}
+			}
+
+			do()
+			calls = nil
+			test.event(onBlock, onDiscard)
+			do()
+
+			assert.Equal(t, test.expCalls, calls)
+		})
+	}
+}
+
+func TestCache_Subscribe_Failed(t *testing.T) {
+	t.Parallel()
+
+	subs := make(map[zmq.Topic]bool)
+	mq := &bnmocks.NodeMQMock{
+		SubscribeFunc: func(topic zmq.Topic, fn zmq.MessageFunc) error {
+			if topic == zmq.TopicRemovedFromMempoolBlock {
+				return zmq.ErrInvalidTopic
+			}
+			subs[topic] = true
+			return nil
+		},
+		UnsubscribeFunc: func(topic zmq.Topic) error {
+			delete(subs, topic)
+			return nil
+		},
+	}
+	c := service.NewCache(&mocks.MockRPC{}, &config.Cache{TTL: time.Minute})
+	assert.ErrorIs(t, c.(cacheSubscriber).Subscribe(mq), zmq.ErrInvalidTopic)
+	assert.Empty(t, subs)
+}
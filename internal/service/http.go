@@ -4,13 +4,18 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"fmt"
 	"io/ioutil"
 	"net/http"
+	"net/url"
+	"strings"
+	"sync"
 
 	"golang.org/x/sync/singleflight"
 
 	"github.com/libsv/go-bn/internal/config"
 	"github.com/libsv/go-bn/models"
+	bnrpc "github.com/libsv/go-bn/rpc"
 	"github.com/pkg/errors"
 )
 
@@ -20,15 +25,24 @@ const (
 	JSONRpc = "1.0"
 )
 
-var (
-	// ErrRPCQuery error when rpc query fails.
-	ErrRPCQuery = errors.New("failed to perform rpc query")
-)
-
 type rpc struct {
 	c   *http.Client
 	cfg *config.RPC
 	g   singleflight.Group
+
+	mu     sync.Mutex
+	cookie *cookie
+}
+
+type cookie struct {
+	username string
+	password string
+}
+
+type rawResponse struct {
+	ID     string          `json:"id"`
+	Result json.RawMessage `json:"result"`
+	Error  *models.Error   `json:"error"`
 }
 
 // NewRPC returns a new RPC configured RPC client.
@@ -42,50 +56,204 @@ func NewRPC(cfg *config.RPC, c *http.Client) RPC {
 
 // Do an RPC request.
 func (h *rpc) Do(ctx context.Context, method string, out interface{}, args ...interface{}) error {
-	return h.do(ctx, request{method: method, args: args}, out)
+	return h.do(ctx, newRequest(ctx, method, args), out)
+}
+
+// DoBatch send many RPC requests in a single JSON-RPC batch request.
+func (h *rpc) DoBatch(ctx context.Context, calls ...*Call) error {
+	if len(calls) == 0 {
+		return nil
+	}
+
+	reqs := make([]models.Request, len(calls))
+	for i, c := range calls {
+		reqs[i] = models.Request{
+			ID:      fmt.Sprintf("%s-%d", ID, i),
+			JSONRpc: JSONRpc,
+			Method:  c.Method,
+			Params:  c.Args,
+		}
+	}
+
+	data, err := h.post(ctx, reqs)
+	if err != nil {
+		return err
+	}
+
+	var replies []rawResponse
+	if err = json.Unmarshal(data, &replies); err != nil {
+		var reply rawResponse
+		if json.Unmarshal(data, &reply) == nil && reply.Error != nil {
+			return reply.Error
+		}
+		return err
+	}
+
+	byID := make(map[string]rawResponse, len(replies))
+	for _, r := range replies {
+		byID[r.ID] = r
+	}
+
+	for i, c := range calls {
+		reply, ok := byID[reqs[i].ID]
+		switch {
+		case !ok:
+			c.Err = models.ErrBatchResponseMissing
+		case reply.Error != nil:
+			c.Err = reply.Error
+		default:
+			c.Err = DecodeResult(reply.Result, c.Out)
+		}
+	}
+
+	return nil
 }
 
 func (h *rpc) do(ctx context.Context, r request, out interface{}) error {
-	data, err, _ := h.g.Do(r.Key(), func() (interface{}, error) {
-		data, err := json.Marshal(&models.Request{
+	var sent bool
+	data, err, shared := h.g.Do(r.Key(), func() (interface{}, error) {
+		sent = true
+		return h.post(ctx, &models.Request{
 			ID:      ID,
 			JSONRpc: JSONRpc,
 			Method:  r.method,
 			Params:  r.args,
 		})
-		if err != nil {
-			return nil, err
-		}
+	})
+	if shared && !sent && h.cfg.OnDedupe != nil {
+		h.cfg.OnDedupe(r.method)
+	}
+	if err != nil {
+		return err
+	}
 
-		req, err := http.NewRequestWithContext(
-			ctx,
-			http.MethodPost,
-			h.cfg.Host,
-			bytes.NewReader(data),
-		)
-		if err != nil {
-			return nil, err
-		}
-		req.SetBasicAuth(h.cfg.Username, h.cfg.Password)
-		req.Header.Add("Content-Type", "text/plain")
+	var reply rawResponse
+	if err = json.NewDecoder(bytes.NewBuffer(data.([]byte))).Decode(&reply); err != nil {
+		return err
+	}
 
-		resp, err := h.c.Do(req)
-		if err != nil {
-			return nil, err
-		}
-		defer func() {
-			_ = resp.Body.Close()
-		}()
+	if reply.Error != nil {
+		return reply.Error
+	}
+
+	return DecodeResult(reply.Result, out)
+}
+
+func (h *rpc) post(ctx context.Context, body interface{}) ([]byte, error) {
+	data, err := json.Marshal(body)
+	if err != nil {
+		return nil, err
+	}
+
+	bb, err := h.send(ctx, data, false)
+	var statusErr *models.StatusError
+	if h.cfg.CookieFile != "" && errors.As(err, &statusErr) && statusErr.StatusCode == http.StatusUnauthorized {
+		// The node rotates its cookie on restart, so re-read it and try once more.
+		return h.send(ctx, data, true)
+	}
 
-		bb, err := ioutil.ReadAll(resp.Body)
-		if err != nil {
-			return nil, err
+	return bb, err
+}
+
+func (h *rpc) send(ctx context.Context, data []byte, reloadCreds bool) ([]byte, error) {
+	resp, err := h.open(ctx, data, reloadCreds)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+
+	return readBody(resp)
+}
+
+// open send the request, returning the response with its body unread.
+func (h *rpc) open(ctx context.Context, data []byte, reloadCreds bool) (*http.Response, error) {
+	username, password, err := h.credentials(reloadCreds)
+	if err != nil {
+		return nil, err
+	}
+
+	host := h.cfg.Host
+	if wallet := bnrpc.Wallet(ctx); wallet != "" {
+		host = strings.TrimRight(host, "/") + "/wallet/" + url.PathEscape(wallet)
+	}
+
+	req, err := http.NewRequestWithContext(
+		ctx,
+		http.MethodPost,
+		host,
+		bytes.NewReader(data),
+	)
+	if err != nil {
+		return nil, err
+	}
+	req.SetBasicAuth(username, password)
+	req.Header.Add("Content-Type", "text/plain")
+
+	resp, err := h.c.Do(req)
+	if err != nil {
+		return nil, &models.TransportError{Err: err}
+	}
+
+	return resp, nil
+}
+
+func readBody(resp *http.Response) ([]byte, error) {
+	bb, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, &models.TransportError{Err: err}
+	}
+
+	// The node responds to failed rpc calls with an error status and a JSON-RPC body,
+	// which is decoded as usual. Anything else is a failure of the http layer.
+	if resp.StatusCode >= http.StatusBadRequest && !json.Valid(bb) {
+		return nil, &models.StatusError{
+			StatusCode: resp.StatusCode,
+			Body:       strings.TrimSpace(string(bb)),
 		}
+	}
 
-		return bb, nil
-	})
+	return bb, nil
+}
+
+// credentials the username and password to authenticate with, read from the cookie
+// file if one is configured. The cookie's contents are never included in an error.
+func (h *rpc) credentials(reload bool) (string, string, error) {
+	if h.cfg.CookieFile == "" {
+		return h.cfg.Username, h.cfg.Password, nil
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.cookie != nil && !reload {
+		return h.cookie.username, h.cookie.password, nil
+	}
+
+	bb, err := ioutil.ReadFile(h.cfg.CookieFile)
 	if err != nil {
-		return err
+		return "", "", errors.Wrap(err, "failed to read rpc cookie file")
+	}
+
+	parts := strings.SplitN(strings.TrimSpace(string(bb)), ":", 2)
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return "", "", models.ErrInvalidCookie
+	}
+
+	h.cookie = &cookie{
+		username: parts[0],
+		password: parts[1],
+	}
+
+	return h.cookie.username, h.cookie.password, nil
+}
+
+// DecodeResult decode a raw rpc result into out, applying the NodeJSON and
+// PostProcess hooks where out provides them.
+func DecodeResult(result json.RawMessage, out interface{}) error {
+	if out == nil {
+		return nil
 	}
 
 	if v, ok := out.(interface {
@@ -94,15 +262,10 @@ func (h *rpc) do(ctx context.Context, r request, out interface{}) error {
 		out = v.NodeJSON()
 	}
 
-	reply := models.Response{
-		Result: out,
-	}
-	if err = json.NewDecoder(bytes.NewBuffer(data.([]byte))).Decode(&reply); err != nil {
-		return err
-	}
-
-	if reply.Error != nil {
-		return reply.Error
+	if len(result) > 0 {
+		if err := json.Unmarshal(result, out); err != nil {
+			return err
+		}
 	}
 
 	if v, ok := out.(interface {
@@ -3,8 +3,13 @@ package service_test
 import (
 	"context"
 	"encoding/json"
+	"errors"
+	"io/ioutil"
 	"net/http"
 	"net/http/httptest"
+	"path"
+	"reflect"
+	"sync"
 	"sync/atomic"
 	"testing"
 	"time"
@@ -89,10 +94,19 @@ func TestRPC_Do_SingleFlight(t *testing.T) {
 			}))
 			defer svr.Close()
 
+			var dedupes int32
 			c := service.NewRPC(&config.RPC{
 				Host: svr.URL,
+				OnDedupe: func(method string) {
+					atomic.AddInt32(&dedupes, 1)
+				},
 			}, &http.Client{})
 
+			var total int32
+			for _, inv := range test.invocations {
+				total += inv.timesCalled
+			}
+
 			g, ctx := errgroup.WithContext(context.TODO())
 			for _, inv := range test.invocations {
 				inv := inv
@@ -113,6 +127,231 @@ func TestRPC_Do_SingleFlight(t *testing.T) {
 			}
 
 			assert.Equal(t, test.expCalls, timesCalled)
+			assert.Equal(t, total-test.expCalls, dedupes)
+		})
+	}
+}
+
+func TestRPC_DoBatch(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		response  string
+		calls     []*service.Call
+		expOut    []interface{}
+		expErrs   []error
+		expMethod []string
+		expErr    error
+	}{
+		"successful batch": {
+			response: `[
+				{"id":"go-bn-1","result":12,"error":null},
+				{"id":"go-bn-0","result":"ohiya","error":null}
+			]`,
+			calls: []*service.Call{{
+				Method: "getbestblockhash",
+				Out:    new(string),
+			}, {
+				Method: "getblockcount",
+				Out:    new(uint32),
+			}},
+			expMethod: []string{"getbestblockhash", "getblockcount"},
+			expOut:    []interface{}{"ohiya", uint32(12)},
+			expErrs:   []error{nil, nil},
+		},
+		"per call errors are returned per call": {
+			response: `[
+				{"id":"go-bn-0","result":"ohiya","error":null},
+				{"id":"go-bn-1","result":null,"error":{"code":-5,"message":"No such mempool or blockchain transaction"}}
+			]`,
+			calls: []*service.Call{{
+				Method: "getbestblockhash",
+				Out:    new(string),
+			}, {
+				Method: "getrawtransaction",
+				Args:   []interface{}{"abc", true},
+				Out:    new(string),
+			}},
+			expMethod: []string{"getbestblockhash", "getrawtransaction"},
+			expOut:    []interface{}{"ohiya", ""},
+			expErrs: []error{nil, &models.Error{
+				Code:    -5,
+				Message: "No such mempool or blockchain transaction",
+			}},
+		},
+		"missing response is reported": {
+			response: `[{"id":"go-bn-0","result":"ohiya","error":null}]`,
+			calls: []*service.Call{{
+				Method: "getbestblockhash",
+				Out:    new(string),
+			}, {
+				Method: "getbestblockhash",
+				Out:    new(string),
+			}},
+			expMethod: []string{"getbestblockhash", "getbestblockhash"},
+			expOut:    []interface{}{"ohiya", ""},
+			expErrs:   []error{nil, models.ErrBatchResponseMissing},
+		},
+		"batch level error is returned": {
+			response: `{"id":null,"result":null,"error":{"code":-32700,"message":"Parse error"}}`,
+			calls: []*service.Call{{
+				Method: "getbestblockhash",
+				Out:    new(string),
+			}},
+			expMethod: []string{"getbestblockhash"},
+			expErr:    errors.New("-32700: Parse error"),
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			svr := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				var reqs []models.Request
+				assert.NoError(t, json.NewDecoder(r.Body).Decode(&reqs))
+				methods := make([]string, len(reqs))
+				for i, req := range reqs {
+					methods[i] = req.Method
+				}
+				assert.Equal(t, test.expMethod, methods)
+
+				_, _ = w.Write([]byte(test.response))
+			}))
+			defer svr.Close()
+
+			c := service.NewRPC(&config.RPC{
+				Host: svr.URL,
+			}, &http.Client{})
+
+			err := c.(service.BatchRPC).DoBatch(context.TODO(), test.calls...)
+			if test.expErr != nil {
+				assert.Error(t, err)
+				assert.EqualError(t, err, test.expErr.Error())
+				return
+			}
+
+			assert.NoError(t, err)
+			for i, call := range test.calls {
+				assert.Equal(t, test.expErrs[i], call.Err)
+				assert.Equal(t, test.expOut[i], reflect.ValueOf(call.Out).Elem().Interface())
+			}
+		})
+	}
+}
+
+func TestRPC_Do_CookieFile(t *testing.T) {
+	t.Parallel()
+
+	cookieFile := path.Join(t.TempDir(), ".cookie")
+	assert.NoError(t, ioutil.WriteFile(cookieFile, []byte("__cookie__:first"), 0o600))
+
+	var mu sync.Mutex
+	password := "first"
+	var timesCalled int
+	svr := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		timesCalled++
+
+		user, pass, ok := r.BasicAuth()
+		if !ok || user != "__cookie__" || pass != password {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		assert.NoError(t, json.NewEncoder(w).Encode(models.Response{Result: "ohiya"}))
+	}))
+	defer svr.Close()
+
+	c := service.NewRPC(&config.RPC{
+		Host:       svr.URL,
+		CookieFile: cookieFile,
+	}, &http.Client{})
+
+	var out string
+	assert.NoError(t, c.Do(context.TODO(), "getinfo", &out))
+	assert.Equal(t, "ohiya", out)
+	assert.Equal(t, 1, timesCalled)
+
+	// Node restarts with a new cookie.
+	mu.Lock()
+	password = "second"
+	mu.Unlock()
+	assert.NoError(t, ioutil.WriteFile(cookieFile, []byte("__cookie__:second\n"), 0o600))
+
+	assert.NoError(t, c.Do(context.TODO(), "getinfo", &out))
+	assert.Equal(t, 3, timesCalled)
+
+	// Cookie is rejected even after reloading, without its contents leaking.
+	mu.Lock()
+	password = "third"
+	mu.Unlock()
+
+	err := c.Do(context.TODO(), "getinfo", &out)
+	assert.EqualError(t, err, "rpc http status 401 Unauthorized")
+	assert.NotContains(t, err.Error(), "second")
+	assert.Equal(t, 5, timesCalled)
+
+	assert.NoError(t, ioutil.WriteFile(cookieFile, []byte("garbage"), 0o600))
+	err = c.Do(context.TODO(), "getinfo", &out)
+	assert.ErrorIs(t, err, models.ErrInvalidCookie)
+	assert.NotContains(t, err.Error(), "garbage")
+}
+
+func TestRPC_Do_Errors(t *testing.T) {
+	t.Parallel()
+	tests := map[string]struct {
+		handler http.HandlerFunc
+		closed  bool
+		expIs   error
+		expAs   interface{}
+	}{
+		"node error matches sentinel by code": {
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusInternalServerError)
+				_, _ = w.Write([]byte(`{"id":"go-bn","result":null,"error":{"code":-5,"message":"No such transaction"}}`))
+			},
+			expIs: models.ErrNotFound,
+			expAs: new(*models.Error),
+		},
+		"rejected tx matches sentinel by code": {
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusInternalServerError)
+				_, _ = w.Write([]byte(`{"id":"go-bn","result":null,"error":{"code":-26,"message":"257: txn-already-known"}}`))
+			},
+			expIs: models.ErrRejected,
+			expAs: new(*models.Error),
+		},
+		"http status without body is a status error": {
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusServiceUnavailable)
+				_, _ = w.Write([]byte("Work queue depth exceeded"))
+			},
+			expAs: new(*models.StatusError),
+		},
+		"unreachable node is a transport error": {
+			handler: func(w http.ResponseWriter, r *http.Request) {},
+			closed:  true,
+			expAs:   new(*models.TransportError),
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			svr := httptest.NewServer(test.handler)
+			if test.closed {
+				svr.Close()
+			} else {
+				defer svr.Close()
+			}
+
+			rpc := service.NewRPC(&config.RPC{Host: svr.URL}, svr.Client())
+			var out string
+			err := rpc.Do(context.TODO(), "getrawtransaction", &out, "abc")
+			assert.Error(t, err)
+			if test.expIs != nil {
+				assert.True(t, errors.Is(err, test.expIs))
+				assert.False(t, errors.Is(err, models.ErrWarmingUp))
+			}
+			assert.True(t, errors.As(err, test.expAs))
 		})
 	}
 }
@@ -2,19 +2,57 @@ package service
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+
+	bnrpc "github.com/libsv/go-bn/rpc"
 )
 
 // RPC interface with an rpc server.
-type RPC interface {
-	Do(ctx context.Context, method string, out interface{}, args ...interface{}) error
-}
+type RPC = bnrpc.RPC
+
+// BatchRPC interface with an rpc server capable of serving many calls in a single request.
+type BatchRPC = bnrpc.BatchRPC
+
+// Call a single rpc call made as part of a batch.
+type Call = bnrpc.Call
+
+// StreamRPC interface with an rpc server capable of streaming a hex encoded result.
+type StreamRPC = bnrpc.StreamRPC
+
+// StreamFunc reads a streamed result.
+type StreamFunc = bnrpc.StreamFunc
 
 type request struct {
 	method string
 	args   []interface{}
+	wallet string
+}
+
+func newRequest(ctx context.Context, method string, args []interface{}) request {
+	return request{
+		method: method,
+		args:   args,
+		wallet: bnrpc.Wallet(ctx),
+	}
 }
 
+// Key a canonical key for the request, equal for requests with equal method and args
+// served by the same wallet.
 func (r request) Key() string {
-	return fmt.Sprintf("%s|%s", r.method, r.args)
+	prefix := r.method
+	if r.wallet != "" {
+		prefix = r.wallet + "|" + prefix
+	}
+	bb, err := json.Marshal(r.args)
+	if err != nil {
+		return fmt.Sprintf("%s|%v", prefix, r.args)
+	}
+	return prefix + "|" + string(bb)
+}
+
+// REST interface with a node's REST server.
+type REST interface {
+	// Get the resource at the path, relative to `/rest/`.
+	Get(ctx context.Context, path string) ([]byte, error)
 }
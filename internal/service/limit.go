@@ -0,0 +1,279 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/libsv/go-bn/internal/config"
+	"github.com/libsv/go-bn/models"
+)
+
+// ClassHeavy the class of methods which are expensive for the node to serve.
+const ClassHeavy = "heavy"
+
+// heavyMethods methods belonging to ClassHeavy unless configured otherwise.
+var heavyMethods = map[string]bool{
+	"getblock":              true,
+	"getblockbyheight":      true,
+	"getblockstats":         true,
+	"getblockstatsbyheight": true,
+	"getchaintxstats":       true,
+	"getrawmempool":         true,
+	"getrawnonfinalmempool": true,
+	"gettxoutsetinfo":       true,
+	"verifychain":           true,
+}
+
+type limitClass struct {
+	sem      chan struct{}
+	interval time.Duration
+	burst    time.Duration
+
+	mu   sync.Mutex
+	next time.Time
+}
+
+func newLimitClass(cfg config.LimitClass) *limitClass {
+	c := &limitClass{}
+	if cfg.MaxInFlight > 0 {
+		c.sem = make(chan struct{}, cfg.MaxInFlight)
+	}
+	if cfg.PerSecond > 0 {
+		c.interval = time.Duration(float64(time.Second) / cfg.PerSecond)
+		if cfg.Burst > 1 {
+			c.burst = time.Duration(cfg.Burst-1) * c.interval
+		}
+	}
+	return c
+}
+
+// reserve the time at which the next call may start.
+func (c *limitClass) reserve() time.Time {
+	now := time.Now()
+	if c.interval == 0 {
+		return now
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	// Unused capacity accrues up to the burst, allowing calls to start early.
+	if earliest := now.Add(-c.burst); c.next.Before(earliest) {
+		c.next = earliest
+	}
+	at := c.next
+	c.next = c.next.Add(c.interval)
+	if at.Before(now) {
+		return now
+	}
+	return at
+}
+
+// cancel give back a reservation which went unused, so the next call may start sooner.
+func (c *limitClass) cancel() {
+	if c.interval == 0 {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.next = c.next.Add(-c.interval)
+}
+
+type limiter struct {
+	rpc     RPC
+	cfg     *config.Limit
+	dflt    *limitClass
+	classes map[string]*limitClass
+
+	mu      sync.Mutex
+	paused  time.Time
+	backoff time.Duration
+}
+
+// NewLimiter returns a limiter wrapper around an RPC service, capping the calls in
+// flight and started per second within each class of method. Callers queue until
+// their call may be sent or their context is done. When the node answers 503, as
+// it does once its work queue is full, all calls are paused with exponential backoff.
+func NewLimiter(rpc RPC, cfg *config.Limit) RPC {
+	l := &limiter{
+		rpc:     rpc,
+		cfg:     cfg,
+		dflt:    newLimitClass(cfg.Default),
+		classes: make(map[string]*limitClass, len(cfg.Classes)),
+	}
+	for name, c := range cfg.Classes {
+		l.classes[name] = newLimitClass(c)
+	}
+
+	return l
+}
+
+// Do an RPC request once within budget.
+func (l *limiter) Do(ctx context.Context, method string, out interface{}, args ...interface{}) error {
+	release, err := l.acquire(ctx, l.class(method))
+	if err != nil {
+		return err
+	}
+	defer release()
+
+	err = l.rpc.Do(ctx, method, out, args...)
+	l.observe(err)
+	return err
+}
+
+// DoStream an RPC request streaming its result once within budget.
+func (l *limiter) DoStream(ctx context.Context, method string, fn StreamFunc, args ...interface{}) error {
+	release, err := l.acquire(ctx, l.class(method))
+	if err != nil {
+		return err
+	}
+	defer release()
+
+	err = DoStream(ctx, l.rpc, method, fn, args...)
+	l.observe(err)
+	return err
+}
+
+// DoBatch an RPC batch request once within budget. The batch, being sent as a single
+// request, takes one call from the budget of each class among its calls.
+func (l *limiter) DoBatch(ctx context.Context, calls ...*Call) error {
+	classes := l.batchClasses(calls)
+	for _, c := range classes {
+		release, err := l.acquire(ctx, c)
+		if err != nil {
+			return err
+		}
+		defer release()
+	}
+
+	err := DoBatch(ctx, l.rpc, calls...)
+	l.observe(err)
+	return err
+}
+
+// batchClasses the distinct classes of the calls, in a consistent order so that
+// concurrent batches do not deadlock acquiring them.
+func (l *limiter) batchClasses(calls []*Call) []*limitClass {
+	names := make([]string, 0, len(calls))
+	seen := make(map[*limitClass]bool, len(calls))
+	byName := make(map[string]*limitClass, len(calls))
+	for _, call := range calls {
+		name := l.className(call.Method)
+		c := l.class(call.Method)
+		if seen[c] {
+			continue
+		}
+		seen[c] = true
+		names = append(names, name)
+		byName[name] = c
+	}
+	sort.Strings(names)
+
+	classes := make([]*limitClass, len(names))
+	for i, n := range names {
+		classes[i] = byName[n]
+	}
+	return classes
+}
+
+func (l *limiter) className(method string) string {
+	if name, ok := l.cfg.Methods[method]; ok {
+		return name
+	}
+	if heavyMethods[method] {
+		return ClassHeavy
+	}
+	return ""
+}
+
+func (l *limiter) class(method string) *limitClass {
+	if c, ok := l.classes[l.className(method)]; ok {
+		return c
+	}
+	return l.dflt
+}
+
+// acquire wait for any backoff to pass, for a slot in flight and then for the class
+// to have budget, returning a func to release the call's slot once done.
+func (l *limiter) acquire(ctx context.Context, c *limitClass) (func(), error) {
+	for {
+		l.mu.Lock()
+		until := l.paused
+		l.mu.Unlock()
+		d := time.Until(until)
+		if d <= 0 {
+			break
+		}
+		if err := sleep(ctx, d); err != nil {
+			return nil, err
+		}
+	}
+
+	// Take a slot before reserving a start time, so calls queued for a slot do not
+	// spend the rate budget while they wait.
+	release := func() {}
+	if c.sem != nil {
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case c.sem <- struct{}{}:
+			release = func() { <-c.sem }
+		}
+	}
+
+	if err := sleep(ctx, time.Until(c.reserve())); err != nil {
+		c.cancel()
+		release()
+		return nil, err
+	}
+
+	return release, nil
+}
+
+// observe pause all calls should the node be overloaded, resetting the backoff once
+// it recovers.
+func (l *limiter) observe(err error) {
+	var statusErr *models.StatusError
+	overloaded := errors.As(err, &statusErr) && statusErr.StatusCode == http.StatusServiceUnavailable
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if !overloaded {
+		if err == nil {
+			l.backoff = 0
+		}
+		return
+	}
+
+	l.backoff *= 2
+	if l.backoff < l.cfg.BaseBackoff {
+		l.backoff = l.cfg.BaseBackoff
+	}
+	if l.cfg.MaxBackoff > 0 && l.backoff > l.cfg.MaxBackoff {
+		l.backoff = l.cfg.MaxBackoff
+	}
+	l.paused = time.Now().Add(l.backoff)
+}
+
+// sleep for d, or until ctx is done.
+func sleep(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return ctx.Err()
+	}
+
+	t := time.NewTimer(d)
+	defer t.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-t.C:
+		return nil
+	}
+}
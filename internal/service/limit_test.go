@@ -0,0 +1,177 @@
+package service_test
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/libsv/go-bn/internal/config"
+	"github.com/libsv/go-bn/internal/mocks"
+	"github.com/libsv/go-bn/internal/service"
+	"github.com/libsv/go-bn/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLimiter_Do_MaxInFlight(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		cfg         config.Limit
+		method      string
+		expInFlight int32
+	}{
+		"default budget caps default methods": {
+			cfg: config.Limit{
+				Default: config.LimitClass{MaxInFlight: 2},
+				Classes: map[string]config.LimitClass{service.ClassHeavy: {MaxInFlight: 1}},
+			},
+			method:      "getinfo",
+			expInFlight: 2,
+		},
+		"heavy budget caps heavy methods": {
+			cfg: config.Limit{
+				Default: config.LimitClass{MaxInFlight: 2},
+				Classes: map[string]config.LimitClass{service.ClassHeavy: {MaxInFlight: 1}},
+			},
+			method:      "getblock",
+			expInFlight: 1,
+		},
+		"heavy methods use default budget without a class budget": {
+			cfg: config.Limit{
+				Default: config.LimitClass{MaxInFlight: 3},
+			},
+			method:      "getblock",
+			expInFlight: 3,
+		},
+		"methods can be assigned classes": {
+			cfg: config.Limit{
+				Default: config.LimitClass{MaxInFlight: 3},
+				Classes: map[string]config.LimitClass{"slow": {MaxInFlight: 1}},
+				Methods: map[string]string{"getinfo": "slow"},
+			},
+			method:      "getinfo",
+			expInFlight: 1,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			var inFlight, maxInFlight int32
+			l := service.NewLimiter(&mocks.MockRPC{
+				DoFunc: func(ctx context.Context, method string, out interface{}, args ...interface{}) error {
+					n := atomic.AddInt32(&inFlight, 1)
+					defer atomic.AddInt32(&inFlight, -1)
+					for {
+						prev := atomic.LoadInt32(&maxInFlight)
+						if n <= prev || atomic.CompareAndSwapInt32(&maxInFlight, prev, n) {
+							break
+						}
+					}
+					time.Sleep(20 * time.Millisecond)
+					return nil
+				},
+			}, &test.cfg)
+
+			var wg sync.WaitGroup
+			for i := 0; i < 6; i++ {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					assert.NoError(t, l.Do(context.TODO(), test.method, nil))
+				}()
+			}
+			wg.Wait()
+
+			assert.Equal(t, test.expInFlight, maxInFlight)
+		})
+	}
+}
+
+func TestLimiter_Do_PerSecond(t *testing.T) {
+	t.Parallel()
+
+	l := service.NewLimiter(&mocks.MockRPC{
+		DoFunc: func(ctx context.Context, method string, out interface{}, args ...interface{}) error {
+			return nil
+		},
+	}, &config.Limit{Default: config.LimitClass{PerSecond: 50, Burst: 2}})
+
+	start := time.Now()
+	for i := 0; i < 6; i++ {
+		assert.NoError(t, l.Do(context.TODO(), "getinfo", nil))
+	}
+
+	// The first two calls start immediately, each of the rest 20ms apart.
+	assert.GreaterOrEqual(t, int64(time.Since(start)), int64(70*time.Millisecond))
+}
+
+func TestLimiter_Do_ContextCancelled(t *testing.T) {
+	t.Parallel()
+
+	release := make(chan struct{})
+	l := service.NewLimiter(&mocks.MockRPC{
+		DoFunc: func(ctx context.Context, method string, out interface{}, args ...interface{}) error {
+			<-release
+			return nil
+		},
+	}, &config.Limit{Default: config.LimitClass{MaxInFlight: 1}})
+
+	go func() {
+		_ = l.Do(context.TODO(), "getinfo", nil)
+	}()
+	time.Sleep(10 * time.Millisecond)
+
+	ctx, cancel := context.WithTimeout(context.TODO(), 10*time.Millisecond)
+	defer cancel()
+	assert.ErrorIs(t, l.Do(ctx, "getinfo", nil), context.DeadlineExceeded)
+	close(release)
+}
+
+func TestLimiter_Do_CancelledReservationReturned(t *testing.T) {
+	t.Parallel()
+
+	l := service.NewLimiter(&mocks.MockRPC{
+		DoFunc: func(ctx context.Context, method string, out interface{}, args ...interface{}) error {
+			return nil
+		},
+	}, &config.Limit{Default: config.LimitClass{PerSecond: 10}})
+
+	start := time.Now()
+	assert.NoError(t, l.Do(context.TODO(), "getinfo", nil))
+	for i := 0; i < 5; i++ {
+		ctx, cancel := context.WithTimeout(context.TODO(), 5*time.Millisecond)
+		assert.ErrorIs(t, l.Do(ctx, "getinfo", nil), context.DeadlineExceeded)
+		cancel()
+	}
+
+	// The cancelled calls' budget is given back, the next call starting 100ms after
+	// the first rather than 600ms.
+	assert.NoError(t, l.Do(context.TODO(), "getinfo", nil))
+	assert.Less(t, int64(time.Since(start)), int64(300*time.Millisecond))
+}
+
+func TestLimiter_Do_BackoffOnOverload(t *testing.T) {
+	t.Parallel()
+
+	var calls []time.Time
+	l := service.NewLimiter(&mocks.MockRPC{
+		DoFunc: func(ctx context.Context, method string, out interface{}, args ...interface{}) error {
+			calls = append(calls, time.Now())
+			if len(calls) <= 2 {
+				return &models.StatusError{StatusCode: http.StatusServiceUnavailable, Body: "Work queue depth exceeded"}
+			}
+			return nil
+		},
+	}, &config.Limit{BaseBackoff: 20 * time.Millisecond, MaxBackoff: time.Second})
+
+	for i := 0; i < 3; i++ {
+		_ = l.Do(context.TODO(), "getinfo", nil)
+	}
+
+	assert.Len(t, calls, 3)
+	assert.GreaterOrEqual(t, int64(calls[1].Sub(calls[0])), int64(20*time.Millisecond))
+	assert.GreaterOrEqual(t, int64(calls[2].Sub(calls[1])), int64(40*time.Millisecond))
+}
@@ -0,0 +1,308 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"golang.org/x/sync/singleflight"
+
+	"github.com/libsv/go-bn/internal/config"
+	"github.com/libsv/go-bn/models"
+	bnrpc "github.com/libsv/go-bn/rpc"
+)
+
+// ErrNoNodes error when a pool is configured without any nodes.
+var ErrNoNodes = errors.New("no nodes configured in pool")
+
+// pinned methods which depend upon the state of a single node, and so are always
+// sent to the primary. Pinned calls are never tried against another node, so those
+// such as `sendrawtransaction`, which the primary may have received despite a
+// transport error, are not sent twice.
+var pinned = map[string]bool{
+	// wallet
+	"abandontransaction":     true,
+	"addmultisigaddress":     true,
+	"backupwallet":           true,
+	"dumpprivkey":            true,
+	"dumpwallet":             true,
+	"encryptwallet":          true,
+	"fundrawtransaction":     true,
+	"getaccount":             true,
+	"getaccountaddress":      true,
+	"getaddressesbyaccount":  true,
+	"getbalance":             true,
+	"getnewaddress":          true,
+	"getrawchangeaddress":    true,
+	"getreceivedbyaddress":   true,
+	"gettransaction":         true,
+	"getunconfirmedbalance":  true,
+	"getwalletinfo":          true,
+	"importaddress":          true,
+	"importmulti":            true,
+	"importprivkey":          true,
+	"importprunedfunds":      true,
+	"importpubkey":           true,
+	"importwallet":           true,
+	"keypoolrefill":          true,
+	"listaccounts":           true,
+	"listlockunspent":        true,
+	"listreceivedbyaccount":  true,
+	"listreceivedbyaddress":  true,
+	"listsinceblock":         true,
+	"listtransactions":       true,
+	"listunspent":            true,
+	"listwallets":            true,
+	"lockunspent":            true,
+	"move":                   true,
+	"removeprunedfunds":      true,
+	"sendfrom":               true,
+	"sendmany":               true,
+	"sendtoaddress":          true,
+	"setaccount":             true,
+	"settxfee":               true,
+	"signmessage":            true,
+	"signrawtransaction":     true,
+	"walletlock":             true,
+	"walletpassphrase":       true,
+	"walletpassphrasechange": true,
+
+	// node state
+	"addnode":                  true,
+	"clearbanned":              true,
+	"clearinvalidtransactions": true,
+	"disconnectnode":           true,
+	"generate":                 true,
+	"generatetoaddress":        true,
+	"getblocktemplate":         true,
+	"getminingcandidate":       true,
+	"preciousblock":            true,
+	"prioritisetx":             true,
+	"pruneblockchain":          true,
+	"rebuildjournal":           true,
+	"sendrawtransaction":       true,
+	"sendrawtransactions":      true,
+	"setban":                   true,
+	"setblockmaxsize":          true,
+	"setexcessiveblock":        true,
+	"setnetworkactive":         true,
+	"stop":                     true,
+	"submitblock":              true,
+	"submitminingsolution":     true,
+}
+
+type poolNode struct {
+	rpc     RPC
+	healthy bool
+	blocks  uint32
+}
+
+type pool struct {
+	mu      sync.RWMutex
+	nodes   []*poolNode
+	cfg     *config.Pool
+	g       singleflight.Group
+	next    uint32
+	checked time.Time
+}
+
+// NewPool returns an RPC service backed by a pool of nodes. Reads are balanced across
+// nodes answering `ping` and `getblockchaininfo` whose chain is within cfg.MaxLag
+// blocks of the best tip, failing over to the next node should one be unreachable.
+// Wallet and other stateful calls, `sendrawtransaction` among them, are always sent
+// to the primary, being the first node, and never fail over.
+func NewPool(cfg *config.Pool, c *http.Client) RPC {
+	nodes := make([]*poolNode, len(cfg.Nodes))
+	for i, n := range cfg.Nodes {
+		nodes[i] = &poolNode{
+			rpc:     NewRPC(n, c),
+			healthy: true,
+		}
+	}
+
+	return &pool{
+		nodes: nodes,
+		cfg:   cfg,
+	}
+}
+
+// Do an RPC request against a node in the pool.
+func (p *pool) Do(ctx context.Context, method string, out interface{}, args ...interface{}) error {
+	return p.try(ctx, p.candidates(ctx, method), func(rpc RPC) error {
+		return rpc.Do(ctx, method, out, args...)
+	})
+}
+
+// DoBatch an RPC batch request against a node in the pool. The batch is sent to the
+// primary should any of its calls be pinned.
+func (p *pool) DoBatch(ctx context.Context, calls ...*Call) error {
+	return p.try(ctx, p.candidates(ctx, methods(calls)...), func(rpc RPC) error {
+		return DoBatch(ctx, rpc, calls...)
+	})
+}
+
+// DoStream an RPC request against a node in the pool, streaming its result. Once the
+// result has begun to stream, the request is not tried against another node.
+func (p *pool) DoStream(ctx context.Context, method string, fn StreamFunc, args ...interface{}) error {
+	err := ErrNoNodes
+	for _, n := range p.candidates(ctx, method) {
+		var started bool
+		err = DoStream(ctx, n.rpc, method, func(r io.Reader) error {
+			started = true
+			return fn(r)
+		}, args...)
+		if started || !failover(ctx, err) {
+			return err
+		}
+		p.markUnhealthy(n)
+	}
+
+	return err
+}
+
+func (p *pool) try(ctx context.Context, nodes []*poolNode, fn func(rpc RPC) error) error {
+	err := ErrNoNodes
+	for _, n := range nodes {
+		if err = fn(n.rpc); !failover(ctx, err) {
+			return err
+		}
+		p.markUnhealthy(n)
+	}
+
+	return err
+}
+
+// candidates the nodes to try for the methods, in order of preference. Calls made
+// to a named wallet are pinned, the wallet being loaded on the primary.
+func (p *pool) candidates(ctx context.Context, methods ...string) []*poolNode {
+	if len(p.nodes) == 0 {
+		return nil
+	}
+	if bnrpc.Wallet(ctx) != "" {
+		return p.nodes[:1]
+	}
+	for _, m := range methods {
+		if pinned[m] {
+			return p.nodes[:1]
+		}
+	}
+
+	p.checkHealth(ctx)
+
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	var best uint32
+	for _, n := range p.nodes {
+		if n.healthy && n.blocks > best {
+			best = n.blocks
+		}
+	}
+
+	start := int(atomic.AddUint32(&p.next, 1))
+	nodes := make([]*poolNode, 0, len(p.nodes))
+	for i := range p.nodes {
+		n := p.nodes[(start+i)%len(p.nodes)]
+		if n.healthy && best-n.blocks <= p.cfg.MaxLag {
+			nodes = append(nodes, n)
+		}
+	}
+	if len(nodes) == 0 {
+		// Nothing is known to be healthy, so try everything, primary first.
+		return p.nodes
+	}
+
+	return nodes
+}
+
+// checkHealth refresh the health of each node, should the last check have expired.
+// The refresh runs in the background, only the first check being waited upon, and
+// then for no longer than ctx allows.
+func (p *pool) checkHealth(ctx context.Context) {
+	p.mu.RLock()
+	checked := p.checked
+	p.mu.RUnlock()
+	if time.Since(checked) < p.cfg.HealthCheckInterval {
+		return
+	}
+
+	ch := p.g.DoChan("health", func() (interface{}, error) {
+		// A cancelled caller should not mark the pool unhealthy, so check with a
+		// context of its own, bounded by the check interval.
+		ctx, cancel := context.WithTimeout(context.Background(), p.cfg.HealthCheckInterval)
+		defer cancel()
+
+		var wg sync.WaitGroup
+		for _, n := range p.nodes {
+			wg.Add(1)
+			go func(n *poolNode) {
+				defer wg.Done()
+				blocks, err := probe(ctx, n.rpc)
+
+				p.mu.Lock()
+				defer p.mu.Unlock()
+				n.healthy = err == nil
+				n.blocks = blocks
+			}(n)
+		}
+		wg.Wait()
+
+		p.mu.Lock()
+		p.checked = time.Now()
+		p.mu.Unlock()
+		return nil, nil
+	})
+	if !checked.IsZero() {
+		return
+	}
+	select {
+	case <-ch:
+	case <-ctx.Done():
+	}
+}
+
+// probe the node, returning its height should it be reachable.
+func probe(ctx context.Context, rpc RPC) (uint32, error) {
+	if err := rpc.Do(ctx, "ping", nil); err != nil {
+		return 0, err
+	}
+	var info models.ChainInfo
+	if err := rpc.Do(ctx, "getblockchaininfo", &info); err != nil {
+		return 0, err
+	}
+
+	return info.Blocks, nil
+}
+
+func (p *pool) markUnhealthy(n *poolNode) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	n.healthy = false
+}
+
+// failover reports whether the request should be tried against another node, being
+// so only should the node be unreachable, answer with an http 5xx and no JSON-RPC
+// error, or be warming up. Any other error, such as bad credentials or a result not
+// decoding into out, would fail alike on every node, so is final.
+func failover(ctx context.Context, err error) bool {
+	if err == nil || ctx.Err() != nil {
+		return false
+	}
+
+	var nodeErr *models.Error
+	if errors.As(err, &nodeErr) {
+		return errors.Is(nodeErr, models.ErrWarmingUp)
+	}
+
+	var statusErr *models.StatusError
+	if errors.As(err, &statusErr) {
+		return statusErr.StatusCode >= http.StatusInternalServerError
+	}
+
+	var transportErr *models.TransportError
+	return errors.As(err, &transportErr)
+}
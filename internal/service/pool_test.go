@@ -0,0 +1,233 @@
+package service_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/libsv/go-bn/internal/config"
+	"github.com/libsv/go-bn/internal/service"
+	"github.com/libsv/go-bn/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPool_Do(t *testing.T) {
+	t.Parallel()
+
+	type node struct {
+		name   string
+		blocks uint32
+		down   bool
+	}
+	tests := map[string]struct {
+		nodes    []node
+		method   string
+		maxLag   uint32
+		expNodes []string
+		expErr   bool
+	}{
+		"reads are balanced across in sync nodes": {
+			nodes:    []node{{name: "a", blocks: 100}, {name: "b", blocks: 99}, {name: "c", blocks: 90}},
+			method:   "getbestblockhash",
+			maxLag:   2,
+			expNodes: []string{"a", "b"},
+		},
+		"reads fail over from an unreachable node": {
+			nodes:    []node{{name: "a", blocks: 100}, {name: "b", blocks: 100, down: true}},
+			method:   "getbestblockhash",
+			expNodes: []string{"a"},
+		},
+		"wallet calls are pinned to the primary": {
+			nodes:    []node{{name: "a", blocks: 90}, {name: "b", blocks: 100}, {name: "c", blocks: 100}},
+			method:   "getbalance",
+			expNodes: []string{"a"},
+		},
+		"wallet calls do not fail over": {
+			nodes:  []node{{name: "a", blocks: 100, down: true}, {name: "b", blocks: 100}},
+			method: "sendtoaddress",
+			expErr: true,
+		},
+		"txs are not sent to another node": {
+			nodes:  []node{{name: "a", blocks: 100, down: true}, {name: "b", blocks: 100}},
+			method: "sendrawtransaction",
+			expErr: true,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			var mu sync.Mutex
+			served := map[string]bool{}
+
+			cfg := &config.Pool{
+				MaxLag:              test.maxLag,
+				HealthCheckInterval: time.Minute,
+			}
+			for _, n := range test.nodes {
+				n := n
+				svr := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					var req models.Request
+					assert.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+
+					resp := models.Response{Result: n.name}
+					switch req.Method {
+					case "ping":
+						resp.Result = nil
+					case "getblockchaininfo":
+						resp.Result = models.ChainInfo{Blocks: n.blocks}
+					default:
+						mu.Lock()
+						served[n.name] = true
+						mu.Unlock()
+					}
+					assert.NoError(t, json.NewEncoder(w).Encode(resp))
+				}))
+				if n.down {
+					svr.Close()
+				} else {
+					defer svr.Close()
+				}
+				cfg.Nodes = append(cfg.Nodes, &config.RPC{Host: svr.URL})
+			}
+
+			p := service.NewPool(cfg, &http.Client{})
+			for i := 0; i < 20; i++ {
+				var out string
+				err := p.Do(context.TODO(), test.method, &out)
+				if test.expErr {
+					assert.Error(t, err)
+					continue
+				}
+				assert.NoError(t, err)
+			}
+
+			var nodes []string
+			for _, n := range test.nodes {
+				if served[n.name] {
+					nodes = append(nodes, n.name)
+				}
+			}
+			assert.Equal(t, test.expNodes, nodes)
+		})
+	}
+}
+
+func TestPool_Do_HealthCheckBoundedByContext(t *testing.T) {
+	t.Parallel()
+
+	release := make(chan struct{})
+	svr := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req models.Request
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		if req.Method == "ping" {
+			<-release
+		}
+		assert.NoError(t, json.NewEncoder(w).Encode(models.Response{Result: "a"}))
+	}))
+	defer svr.Close()
+	defer close(release)
+
+	p := service.NewPool(&config.Pool{
+		Nodes:               []*config.RPC{{Host: svr.URL}},
+		HealthCheckInterval: time.Minute,
+	}, &http.Client{})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	var out string
+	assert.Error(t, p.Do(ctx, "getbestblockhash", &out))
+	assert.Less(t, int64(time.Since(start)), int64(time.Second))
+}
+
+func TestPool_Do_Failover(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		status      int
+		resp        models.Response
+		expFailover bool
+	}{
+		"warming up fails over": {
+			status:      http.StatusInternalServerError,
+			resp:        models.Response{Error: &models.Error{Code: -28, Message: "Loading block index..."}},
+			expFailover: true,
+		},
+		"http 5xx fails over": {
+			status:      http.StatusServiceUnavailable,
+			expFailover: true,
+		},
+		"node error is final": {
+			status: http.StatusInternalServerError,
+			resp:   models.Response{Error: &models.Error{Code: -5, Message: "Block not found"}},
+		},
+		"bad credentials are final": {
+			status: http.StatusUnauthorized,
+		},
+		"result not decoding is final": {
+			status: http.StatusOK,
+			resp:   models.Response{Result: "abc"},
+		},
+	}
+
+	for name, test := range tests {
+		test := test
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			var mu sync.Mutex
+			var served int
+			failing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				var req models.Request
+				assert.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+				if req.Method == "ping" || req.Method == "getblockchaininfo" {
+					assert.NoError(t, json.NewEncoder(w).Encode(models.Response{Result: models.ChainInfo{Blocks: 1}}))
+					return
+				}
+				w.WriteHeader(test.status)
+				if test.resp.Result != nil || test.resp.Error != nil {
+					assert.NoError(t, json.NewEncoder(w).Encode(test.resp))
+				}
+			}))
+			defer failing.Close()
+			healthy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				var req models.Request
+				assert.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+				resp := models.Response{Result: models.ChainInfo{Blocks: 1}}
+				if req.Method != "ping" && req.Method != "getblockchaininfo" {
+					mu.Lock()
+					served++
+					mu.Unlock()
+					resp.Result = 1
+				}
+				assert.NoError(t, json.NewEncoder(w).Encode(resp))
+			}))
+			defer healthy.Close()
+
+			p := service.NewPool(&config.Pool{
+				Nodes:               []*config.RPC{{Host: failing.URL}, {Host: healthy.URL}},
+				HealthCheckInterval: time.Minute,
+			}, &http.Client{})
+			var errs int
+			for i := 0; i < 4; i++ {
+				var out int
+				if err := p.Do(context.TODO(), "getblockcount", &out); err != nil {
+					errs++
+				}
+			}
+
+			// Reads alternate between the nodes, every read being served by the healthy
+			// node only should the failing node's error fail over.
+			if test.expFailover {
+				assert.Equal(t, 0, errs)
+				assert.Equal(t, 4, served)
+				return
+			}
+			assert.Equal(t, 2, errs)
+			assert.Equal(t, 2, served)
+		})
+	}
+}
@@ -0,0 +1,57 @@
+package service
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"strings"
+
+	"github.com/libsv/go-bn/models"
+)
+
+type rest struct {
+	c    *http.Client
+	host string
+}
+
+// NewREST returns a new REST client of the node at host.
+func NewREST(host string, c *http.Client) REST {
+	return &rest{
+		c:    c,
+		host: strings.TrimRight(host, "/"),
+	}
+}
+
+// Get the resource at the path. As with rpc calls, an unknown resource is
+// reported as models.ErrNotFound and a malformed request as models.ErrInvalidParameter.
+func (r *rest) Get(ctx context.Context, path string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, r.host+"/rest/"+path, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := r.c.Do(req)
+	if err != nil {
+		return nil, &models.TransportError{Err: err}
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+
+	bb, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, &models.TransportError{Err: err}
+	}
+
+	msg := strings.TrimSpace(string(bb))
+	switch {
+	case resp.StatusCode == http.StatusNotFound:
+		return nil, &models.Error{Code: models.ErrNotFound.Code, Message: msg}
+	case resp.StatusCode == http.StatusBadRequest:
+		return nil, &models.Error{Code: models.ErrInvalidParameter.Code, Message: msg}
+	case resp.StatusCode >= http.StatusBadRequest:
+		return nil, &models.StatusError{StatusCode: resp.StatusCode, Body: msg}
+	}
+
+	return bb, nil
+}
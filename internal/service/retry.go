@@ -0,0 +1,225 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"io"
+	"math/rand"
+	"net/http"
+	"syscall"
+	"time"
+
+	"github.com/libsv/go-bn/internal/config"
+	"github.com/libsv/go-bn/models"
+)
+
+// idempotent methods which only read, and so are safe to retry should the node have
+// received an earlier attempt. Any method not listed here, including those unknown to
+// the client, is only retried if the caller opts in.
+var idempotent = map[string]bool{
+	"activezmqnotifications": true,
+	"checkjournal":           true,
+	"createmultisig":         true,
+	"createrawtransaction":   true,
+	"dumpparameters":         true,
+	"getaccount":             true,
+	"getaddednodeinfo":       true,
+	"getaddressesbyaccount":  true,
+	"getbalance":             true,
+	"getbestblockhash":       true,
+	"getblock":               true,
+	"getblockbyheight":       true,
+	"getblockchaininfo":      true,
+	"getblockcount":          true,
+	"getblockhash":           true,
+	"getblockheader":         true,
+	"getblockstats":          true,
+	"getblockstatsbyheight":  true,
+	"getchaintips":           true,
+	"getchaintxstats":        true,
+	"getconnectioncount":     true,
+	"getdifficulty":          true,
+	"getexcessiveblock":      true,
+	"getinfo":                true,
+	"getmemoryinfo":          true,
+	"getmempoolancestors":    true,
+	"getmempooldescendants":  true,
+	"getmempoolentry":        true,
+	"getmerkleproof":         true,
+	"getmerkleproof2":        true,
+	"getmininginfo":          true,
+	"getnettotals":           true,
+	"getnetworkhashps":       true,
+	"getnetworkinfo":         true,
+	"getpeerinfo":            true,
+	"getrawmempool":          true,
+	"getrawnonfinalmempool":  true,
+	"getrawtransaction":      true,
+	"getreceivedbyaddress":   true,
+	"getsettings":            true,
+	"gettransaction":         true,
+	"gettxout":               true,
+	"gettxouts":              true,
+	"gettxoutsetinfo":        true,
+	"getunconfirmedbalance":  true,
+	"getwalletinfo":          true,
+	"listaccounts":           true,
+	"listbanned":             true,
+	"listlockunspent":        true,
+	"listreceivedbyaccount":  true,
+	"listreceivedbyaddress":  true,
+	"listsinceblock":         true,
+	"listtransactions":       true,
+	"listunspent":            true,
+	"listwallets":            true,
+	"ping":                   true,
+	"signmessagewithprivkey": true,
+	"uptime":                 true,
+	"validateaddress":        true,
+	"verifychain":            true,
+	"verifymessage":          true,
+}
+
+type retry struct {
+	rpc RPC
+	cfg *config.Retry
+}
+
+// NewRetry returns a retry wrapper around an RPC service. Calls failing with a
+// connection reset, an http 5xx or while the node is warming up are retried with
+// exponential backoff, provided the method is an idempotent read or one the caller
+// has opted in to retrying.
+func NewRetry(rpc RPC, cfg *config.Retry) RPC {
+	return &retry{
+		rpc: rpc,
+		cfg: cfg,
+	}
+}
+
+// Do an RPC request, retrying on failure.
+func (r *retry) Do(ctx context.Context, method string, out interface{}, args ...interface{}) error {
+	for attempt := 1; ; attempt++ {
+		err := r.rpc.Do(ctx, method, out, args...)
+		if err == nil || attempt >= r.cfg.MaxAttempts || !r.canRetry(method) || !retryable(err) {
+			return err
+		}
+		if err := r.wait(ctx, attempt); err != nil {
+			return err
+		}
+	}
+}
+
+// DoBatch an RPC batch request, retrying the whole batch on transport failure and
+// any calls which individually failed with a retryable error.
+func (r *retry) DoBatch(ctx context.Context, calls ...*Call) error {
+	pending := calls
+	for attempt := 1; ; attempt++ {
+		err := DoBatch(ctx, r.rpc, pending...)
+		if err != nil {
+			if attempt >= r.cfg.MaxAttempts || !r.canRetry(methods(pending)...) || !retryable(err) {
+				return err
+			}
+		} else {
+			pending = r.failed(pending)
+			if len(pending) == 0 || attempt >= r.cfg.MaxAttempts {
+				return nil
+			}
+		}
+
+		if err := r.wait(ctx, attempt); err != nil {
+			return err
+		}
+		for _, c := range pending {
+			c.Err = nil
+		}
+	}
+}
+
+// DoStream an RPC request streaming its result, retrying on failure until the
+// result has begun to stream.
+func (r *retry) DoStream(ctx context.Context, method string, fn StreamFunc, args ...interface{}) error {
+	for attempt := 1; ; attempt++ {
+		var started bool
+		err := DoStream(ctx, r.rpc, method, func(rd io.Reader) error {
+			started = true
+			return fn(rd)
+		}, args...)
+		if err == nil || started || attempt >= r.cfg.MaxAttempts || !r.canRetry(method) || !retryable(err) {
+			return err
+		}
+		if err := r.wait(ctx, attempt); err != nil {
+			return err
+		}
+	}
+}
+
+func (r *retry) failed(calls []*Call) []*Call {
+	var failed []*Call
+	for _, c := range calls {
+		if c.Err != nil && r.canRetry(c.Method) && retryable(c.Err) {
+			failed = append(failed, c)
+		}
+	}
+	return failed
+}
+
+func (r *retry) canRetry(methods ...string) bool {
+	for _, m := range methods {
+		if !idempotent[m] && !r.cfg.Methods[m] {
+			return false
+		}
+	}
+	return true
+}
+
+func (r *retry) wait(ctx context.Context, attempt int) error {
+	return sleep(ctx, r.backoff(attempt))
+}
+
+// backoff the delay before the next attempt, doubling each attempt up to the max
+// delay, with the upper half jittered.
+func (r *retry) backoff(attempt int) time.Duration {
+	d := r.cfg.MaxDelay
+	if shift := uint(attempt - 1); shift < 32 {
+		if b := r.cfg.BaseDelay << shift; b > 0 && (d <= 0 || b < d) {
+			d = b
+		}
+	}
+	if d <= 0 {
+		return 0
+	}
+
+	half := d / 2
+	return half + time.Duration(rand.Int63n(int64(d-half)+1)) // nolint:gosec // jitter, not security sensitive
+}
+
+// retryable reports whether err is a transient failure worth retrying.
+func retryable(err error) bool {
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+
+	var nodeErr *models.Error
+	if errors.As(err, &nodeErr) {
+		return errors.Is(nodeErr, models.ErrWarmingUp)
+	}
+
+	var statusErr *models.StatusError
+	if errors.As(err, &statusErr) {
+		return statusErr.StatusCode >= http.StatusInternalServerError
+	}
+
+	return errors.Is(err, syscall.ECONNRESET) ||
+		errors.Is(err, syscall.ECONNREFUSED) ||
+		errors.Is(err, syscall.EPIPE) ||
+		errors.Is(err, io.EOF) ||
+		errors.Is(err, io.ErrUnexpectedEOF)
+}
+
+func methods(calls []*Call) []string {
+	mm := make([]string, len(calls))
+	for i, c := range calls {
+		mm[i] = c.Method
+	}
+	return mm
+}
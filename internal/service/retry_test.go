@@ -0,0 +1,150 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/libsv/go-bn/internal/config"
+	"github.com/libsv/go-bn/internal/mocks"
+	"github.com/libsv/go-bn/internal/service"
+	"github.com/libsv/go-bn/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRetry_Do(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		method   string
+		errs     []error
+		methods  map[string]bool
+		expCalls int
+		expErr   error
+	}{
+		"success is not retried": {
+			method:   "getinfo",
+			errs:     []error{nil},
+			expCalls: 1,
+		},
+		"connection reset is retried": {
+			method:   "getinfo",
+			errs:     []error{syscall.ECONNRESET, nil},
+			expCalls: 2,
+		},
+		"http 5xx is retried": {
+			method:   "getinfo",
+			errs:     []error{&models.StatusError{StatusCode: http.StatusServiceUnavailable}, nil},
+			expCalls: 2,
+		},
+		"warming up is retried": {
+			method:   "getinfo",
+			errs:     []error{&models.Error{Code: -28, Message: "Loading block index..."}, nil},
+			expCalls: 2,
+		},
+		"gives up after max attempts": {
+			method: "getinfo",
+			errs: []error{
+				&models.Error{Code: -28, Message: "Loading block index..."},
+				&models.Error{Code: -28, Message: "Loading block index..."},
+				&models.Error{Code: -28, Message: "Verifying blocks..."},
+			},
+			expCalls: 3,
+			expErr:   errors.New("-28: Verifying blocks..."),
+		},
+		"http 4xx is not retried": {
+			method:   "getinfo",
+			errs:     []error{&models.StatusError{StatusCode: http.StatusUnauthorized}},
+			expCalls: 1,
+			expErr:   errors.New("rpc http status 401 Unauthorized"),
+		},
+		"node error is not retried": {
+			method:   "getrawtransaction",
+			errs:     []error{&models.Error{Code: -5, Message: "No such mempool or blockchain transaction"}},
+			expCalls: 1,
+			expErr:   errors.New("-5: No such mempool or blockchain transaction"),
+		},
+		"non-idempotent method is not retried": {
+			method:   "sendtoaddress",
+			errs:     []error{syscall.ECONNRESET},
+			expCalls: 1,
+			expErr:   syscall.ECONNRESET,
+		},
+		"wallet state change is not retried": {
+			method:   "walletpassphrase",
+			errs:     []error{syscall.ECONNRESET},
+			expCalls: 1,
+			expErr:   syscall.ECONNRESET,
+		},
+		"block submission is not retried": {
+			method:   "submitblock",
+			errs:     []error{syscall.ECONNRESET},
+			expCalls: 1,
+			expErr:   syscall.ECONNRESET,
+		},
+		"unknown method is not retried": {
+			method:   "getsomethingnew",
+			errs:     []error{syscall.ECONNRESET},
+			expCalls: 1,
+			expErr:   syscall.ECONNRESET,
+		},
+		"non-idempotent method is retried when opted in": {
+			method:   "sendtoaddress",
+			errs:     []error{syscall.ECONNRESET, nil},
+			methods:  map[string]bool{"sendtoaddress": true},
+			expCalls: 2,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			var calls int
+			r := service.NewRetry(&mocks.MockRPC{
+				DoFunc: func(ctx context.Context, method string, out interface{}, args ...interface{}) error {
+					assert.Equal(t, test.method, method)
+					err := test.errs[calls]
+					calls++
+					return err
+				},
+			}, &config.Retry{
+				MaxAttempts: 3,
+				BaseDelay:   time.Millisecond,
+				MaxDelay:    2 * time.Millisecond,
+				Methods:     test.methods,
+			})
+
+			err := r.Do(context.TODO(), test.method, nil)
+			if test.expErr != nil {
+				assert.Error(t, err)
+				assert.EqualError(t, err, test.expErr.Error())
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, test.expCalls, calls)
+		})
+	}
+}
+
+func TestRetry_Do_ContextCancelled(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	var calls int
+	r := service.NewRetry(&mocks.MockRPC{
+		DoFunc: func(ctx context.Context, method string, out interface{}, args ...interface{}) error {
+			calls++
+			cancel()
+			return syscall.ECONNRESET
+		},
+	}, &config.Retry{
+		MaxAttempts: 5,
+		BaseDelay:   time.Second,
+		MaxDelay:    time.Second,
+	})
+
+	assert.ErrorIs(t, r.Do(ctx, "getinfo", nil), context.Canceled)
+	assert.Equal(t, 1, calls)
+}
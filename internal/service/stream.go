@@ -0,0 +1,231 @@
+package service
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strings"
+
+	"github.com/libsv/go-bn/models"
+	bnrpc "github.com/libsv/go-bn/rpc"
+)
+
+// ErrStreamResult error when a streamed result is not a hex string.
+var ErrStreamResult = errors.New("rpc result is not a hex string")
+
+// DoStream stream the result of the call to fn if the RPC supports it, otherwise
+// buffer the result and pass it to fn once received.
+func DoStream(ctx context.Context, rpc RPC, method string, fn StreamFunc, args ...interface{}) error {
+	return bnrpc.DoStream(ctx, rpc, method, fn, args...)
+}
+
+// DoStream an RPC request, hex decoding the result as it is read from the response
+// rather than buffering it. Streamed calls are never shared with concurrent callers.
+func (h *rpc) DoStream(ctx context.Context, method string, fn StreamFunc, args ...interface{}) error {
+	data, err := json.Marshal(&models.Request{
+		ID:      ID,
+		JSONRpc: JSONRpc,
+		Method:  method,
+		Params:  args,
+	})
+	if err != nil {
+		return err
+	}
+
+	resp, err := h.open(ctx, data, false)
+	if err == nil && resp.StatusCode == http.StatusUnauthorized && h.cfg.CookieFile != "" {
+		// The node rotates its cookie on restart, so re-read it and try once more.
+		_ = resp.Body.Close()
+		resp, err = h.open(ctx, data, true)
+	}
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+
+	if resp.StatusCode >= http.StatusBadRequest {
+		bb, err := readBody(resp)
+		if err != nil {
+			return err
+		}
+		var reply rawResponse
+		if err = json.Unmarshal(bb, &reply); err != nil {
+			return err
+		}
+		if reply.Error != nil {
+			return reply.Error
+		}
+		return &models.StatusError{
+			StatusCode: resp.StatusCode,
+			Body:       strings.TrimSpace(string(bb)),
+		}
+	}
+
+	return decodeStream(&transportReader{r: resp.Body}, fn)
+}
+
+// decodeStream decode a JSON-RPC response envelope incrementally, passing its
+// result to fn as it is read. Every other field is small, and so decoded as usual.
+func decodeStream(body io.Reader, fn StreamFunc) error {
+	r := bufio.NewReader(body)
+	if err := expect(r, '{'); err != nil {
+		return err
+	}
+
+	var nodeErr *models.Error
+	var streamed bool
+	for {
+		c, err := skipSpace(r)
+		if err != nil {
+			return err
+		}
+		switch c {
+		case '}':
+			if nodeErr != nil {
+				return nodeErr
+			}
+			if !streamed {
+				return ErrStreamResult
+			}
+			return nil
+		case ',':
+			continue
+		case '"':
+		default:
+			return fmt.Errorf("invalid character %q in rpc response", c)
+		}
+
+		// The envelope's keys never contain escapes.
+		key, err := r.ReadString('"')
+		if err != nil {
+			return err
+		}
+		key = key[:len(key)-1]
+		if err = expect(r, ':'); err != nil {
+			return err
+		}
+
+		if c, err = skipSpace(r); err != nil {
+			return err
+		}
+		if err = r.UnreadByte(); err != nil {
+			return err
+		}
+
+		if key == "result" && c == '"' {
+			_, _ = r.Discard(1)
+			hr := &hexStringReader{r: r}
+			if err = fn(hex.NewDecoder(hr)); err != nil {
+				return err
+			}
+			if _, err = io.Copy(ioutil.Discard, hr); err != nil {
+				return err
+			}
+			streamed = true
+			continue
+		}
+
+		dec := json.NewDecoder(r)
+		var raw json.RawMessage
+		if err = dec.Decode(&raw); err != nil {
+			return err
+		}
+		r = bufio.NewReader(io.MultiReader(dec.Buffered(), r))
+		if key == "error" {
+			if err = json.Unmarshal(raw, &nodeErr); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// skipSpace read the next non whitespace byte.
+func skipSpace(r *bufio.Reader) (byte, error) {
+	for {
+		c, err := r.ReadByte()
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				return 0, io.ErrUnexpectedEOF
+			}
+			return 0, err
+		}
+		switch c {
+		case ' ', '\t', '\r', '\n':
+			continue
+		}
+		return c, nil
+	}
+}
+
+func expect(r *bufio.Reader, want byte) error {
+	c, err := skipSpace(r)
+	if err != nil {
+		return err
+	}
+	if c != want {
+		return fmt.Errorf("invalid character %q in rpc response, expected %q", c, want)
+	}
+	return nil
+}
+
+// hexStringReader reads the contents of a JSON string, up to its closing quote.
+// The string must not contain escapes, as is the case for hex.
+type hexStringReader struct {
+	r    *bufio.Reader
+	done bool
+}
+
+func (h *hexStringReader) Read(p []byte) (int, error) {
+	if h.done {
+		return 0, io.EOF
+	}
+	if len(p) == 0 {
+		return 0, nil
+	}
+	if h.r.Buffered() == 0 {
+		if _, err := h.r.Peek(1); err != nil {
+			if errors.Is(err, io.EOF) {
+				return 0, io.ErrUnexpectedEOF
+			}
+			return 0, err
+		}
+	}
+
+	n := len(p)
+	if b := h.r.Buffered(); b < n {
+		n = b
+	}
+	buf, _ := h.r.Peek(n)
+	if i := bytes.IndexByte(buf, '"'); i >= 0 {
+		n = copy(p, buf[:i])
+		_, _ = h.r.Discard(i + 1)
+		h.done = true
+		return n, nil
+	}
+
+	n = copy(p, buf)
+	_, _ = h.r.Discard(n)
+	return n, nil
+}
+
+// transportReader reports failures reading the response body as transport errors.
+type transportReader struct {
+	r io.Reader
+}
+
+func (t *transportReader) Read(p []byte) (int, error) {
+	n, err := t.r.Read(p)
+	if err != nil && !errors.Is(err, io.EOF) {
+		err = &models.TransportError{Err: err}
+	}
+	return n, err
+}
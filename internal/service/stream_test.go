@@ -0,0 +1,92 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/libsv/go-bn/internal/config"
+	"github.com/libsv/go-bn/internal/service"
+	"github.com/libsv/go-bn/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRPC_DoStream(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		status  int
+		body    string
+		expData string
+		expErr  error
+	}{
+		"result is hex decoded": {
+			status:  http.StatusOK,
+			body:    `{"result":"6f686979610a","error":null,"id":"go-bn"}`,
+			expData: "ohiya\n",
+		},
+		"fields may come in any order": {
+			status:  http.StatusOK,
+			body:    "{\n  \"error\": null,\n  \"id\": \"go-bn\",\n  \"result\": \"6f6869796120\"\n}",
+			expData: "ohiya ",
+		},
+		"node error is returned": {
+			status: http.StatusInternalServerError,
+			body:   `{"result":null,"error":{"code":-5,"message":"Block not found"},"id":"go-bn"}`,
+			expErr: &models.Error{Code: -5, Message: "Block not found"},
+		},
+		"node error in successful response is returned": {
+			status: http.StatusOK,
+			body:   `{"error":{"code":-8,"message":"Invalid verbosity"},"id":"go-bn","result":null}`,
+			expErr: &models.Error{Code: -8, Message: "Invalid verbosity"},
+		},
+		"non string result is rejected": {
+			status: http.StatusOK,
+			body:   `{"result":{"hash":"abc"},"error":null,"id":"go-bn"}`,
+			expErr: service.ErrStreamResult,
+		},
+		"http status is returned": {
+			status: http.StatusServiceUnavailable,
+			body:   "Work queue depth exceeded",
+			expErr: &models.StatusError{StatusCode: http.StatusServiceUnavailable, Body: "Work queue depth exceeded"},
+		},
+		"truncated response is reported": {
+			status: http.StatusOK,
+			body:   `{"result":"6f6869`,
+			expErr: io.ErrUnexpectedEOF,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			svr := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(test.status)
+				_, _ = w.Write([]byte(test.body))
+			}))
+			defer svr.Close()
+
+			c := service.NewRPC(&config.RPC{Host: svr.URL}, svr.Client())
+
+			var data []byte
+			err := service.DoStream(context.TODO(), c, "getblock", func(r io.Reader) error {
+				var err error
+				data, err = ioutil.ReadAll(r)
+				return err
+			}, "abc", models.VerbosityRawBlock)
+			if test.expErr != nil {
+				assert.Error(t, err)
+				if !errors.Is(err, test.expErr) {
+					assert.Equal(t, test.expErr, err)
+				}
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, test.expData, string(data))
+		})
+	}
+}
@@ -0,0 +1,170 @@
+package bn
+
+import (
+	"context"
+	"math"
+	"sort"
+
+	"github.com/libsv/go-bn/internal/util"
+	"github.com/libsv/go-bn/models"
+)
+
+// MempoolGraph the dependency graph of txs in the mempool, linking each to its
+// parents by the Depends of its mempool entry. Parents not in the graph, having
+// been left out of the entries it was built from, are ignored.
+//
+// A tx can only be mined alongside its unconfirmed ancestors, so is judged by the
+// fee rate of its package, itself and those ancestors:
+//
+//	g, err := c.MempoolGraph(ctx, txID)
+//	pkg, _ := g.Package(txID)
+//	if pkg.FeeRate < 0.5 {
+//		fee, _ := g.CPFPFee(txID, childSize, 0.5)
+//	}
+type MempoolGraph struct {
+	entries  map[string]models.MempoolEntry
+	children map[string][]string
+}
+
+// NewMempoolGraph returns the dependency graph of the txs, such as those returned
+// by RawMempool.
+func NewMempoolGraph(txs models.MempoolTxs) *MempoolGraph {
+	g := &MempoolGraph{
+		entries:  make(map[string]models.MempoolEntry, len(txs)),
+		children: make(map[string][]string),
+	}
+	for txID, entry := range txs {
+		g.entries[txID] = entry
+	}
+	for txID, entry := range txs {
+		for _, parent := range entry.Depends {
+			if _, ok := txs[parent]; ok {
+				g.children[parent] = append(g.children[parent], txID)
+			}
+		}
+	}
+	return g
+}
+
+// MempoolGraph the dependency graph of the tx with txID, its ancestors and its
+// descendants.
+func (c *client) MempoolGraph(ctx context.Context, txID string) (*MempoolGraph, error) {
+	entry, err := c.MempoolEntry(ctx, txID)
+	if err != nil {
+		return nil, err
+	}
+	ancestors, err := c.MempoolAncestors(ctx, txID)
+	if err != nil {
+		return nil, err
+	}
+	descendants, err := c.MempoolDescendants(ctx, txID)
+	if err != nil {
+		return nil, err
+	}
+
+	txs := make(models.MempoolTxs, len(ancestors)+len(descendants)+1)
+	txs[txID] = *entry
+	for id, e := range ancestors {
+		txs[id] = e
+	}
+	for id, e := range descendants {
+		txs[id] = e
+	}
+	return NewMempoolGraph(txs), nil
+}
+
+// Entry the mempool entry of the tx with txID, should it be in the graph.
+func (g *MempoolGraph) Entry(txID string) (models.MempoolEntry, bool) {
+	e, ok := g.entries[txID]
+	return e, ok
+}
+
+// Ancestors the txids of the ancestors of the tx with txID in the graph, sorted.
+func (g *MempoolGraph) Ancestors(txID string) []string {
+	return g.walk(txID, func(txID string) []string {
+		return g.entries[txID].Depends
+	})
+}
+
+// Descendants the txids of the descendants of the tx with txID in the graph, sorted.
+func (g *MempoolGraph) Descendants(txID string) []string {
+	return g.walk(txID, func(txID string) []string {
+		return g.children[txID]
+	})
+}
+
+// Package the package of the tx with txID, itself and its ancestors, with their
+// total size, fees and fee rate.
+func (g *MempoolGraph) Package(txID string) (*models.MempoolPackage, bool) {
+	if _, ok := g.entries[txID]; !ok {
+		return nil, false
+	}
+	pkg := &models.MempoolPackage{TxIDs: append(g.Ancestors(txID), txID)}
+	for _, id := range pkg.TxIDs {
+		e := g.entries[id]
+		pkg.Size += uint64(e.Size)
+		pkg.Fees += util.BSVToSatoshis(e.Fee)
+	}
+	pkg.FeeRate = feeRate(pkg.Fees, pkg.Size)
+	return pkg, true
+}
+
+// LowestFeeRate the txid and fee rate, in satoshis per byte, of the tx of the lowest
+// fee rate in the package of the tx with txID. Should it be below the rate miners
+// accept, it is the tx blocking the package, most likely to need a CPFP.
+func (g *MempoolGraph) LowestFeeRate(txID string) (string, float64, bool) {
+	pkg, ok := g.Package(txID)
+	if !ok {
+		return "", 0, false
+	}
+	lowest, rate := "", math.Inf(1)
+	for _, id := range pkg.TxIDs {
+		e := g.entries[id]
+		if r := feeRate(util.BSVToSatoshis(e.Fee), uint64(e.Size)); r < rate {
+			lowest, rate = id, r
+		}
+	}
+	return lowest, rate, true
+}
+
+// CPFPFee the fee, in satoshis, a child of the tx with txID, of childSize bytes,
+// must pay to lift the package of the child to satsPerByte. Should the package of
+// the tx already pay enough, the child need only pay for itself.
+func (g *MempoolGraph) CPFPFee(txID string, childSize uint64, satsPerByte float64) (uint64, bool) {
+	pkg, ok := g.Package(txID)
+	if !ok {
+		return 0, false
+	}
+	childFee := uint64(math.Ceil(float64(childSize) * satsPerByte))
+	total := uint64(math.Ceil(float64(pkg.Size+childSize) * satsPerByte))
+	if total <= pkg.Fees+childFee {
+		return childFee, true
+	}
+	return total - pkg.Fees, true
+}
+
+// walk the txs reachable from the tx with txID by next, excluding itself.
+func (g *MempoolGraph) walk(txID string, next func(txID string) []string) []string {
+	seen := map[string]bool{txID: true}
+	txIDs := []string{}
+	queue := []string{txID}
+	for ; len(queue) > 0; queue = queue[1:] {
+		for _, id := range next(queue[0]) {
+			if _, ok := g.entries[id]; !ok || seen[id] {
+				continue
+			}
+			seen[id] = true
+			txIDs = append(txIDs, id)
+			queue = append(queue, id)
+		}
+	}
+	sort.Strings(txIDs)
+	return txIDs
+}
+
+func feeRate(fees, size uint64) float64 {
+	if size == 0 {
+		return 0
+	}
+	return float64(fees) / float64(size)
+}
@@ -0,0 +1,122 @@
+package bn_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/libsv/go-bn"
+	"github.com/libsv/go-bn/internal/mocks"
+	"github.com/libsv/go-bn/models"
+	"github.com/stretchr/testify/assert"
+)
+
+// testMempool txs a to e, where b and d spend a, c spends b, and e is unrelated.
+func testMempool() models.MempoolTxs {
+	return models.MempoolTxs{
+		"a": {Size: 200, Fee: 0.0000002},
+		"b": {Size: 100, Fee: 0.000001, Depends: []string{"a"}},
+		"c": {Size: 300, Fee: 0.0000015, Depends: []string{"b", "z"}},
+		"d": {Size: 100, Fee: 0.0000005, Depends: []string{"a"}},
+		"e": {Size: 100, Fee: 0.000001},
+	}
+}
+
+func TestMempoolGraph(t *testing.T) {
+	t.Parallel()
+	g := bn.NewMempoolGraph(testMempool())
+
+	assert.Equal(t, []string{"a", "b"}, g.Ancestors("c"))
+	assert.Equal(t, []string{}, g.Ancestors("a"))
+	assert.Equal(t, []string{"b", "c", "d"}, g.Descendants("a"))
+	assert.Equal(t, []string{}, g.Descendants("e"))
+
+	pkg, ok := g.Package("c")
+	assert.True(t, ok)
+	assert.Equal(t, &models.MempoolPackage{
+		TxIDs:   []string{"a", "b", "c"},
+		Size:    600,
+		Fees:    270,
+		FeeRate: 0.45,
+	}, pkg)
+	_, ok = g.Package("z")
+	assert.False(t, ok)
+
+	txID, rate, ok := g.LowestFeeRate("c")
+	assert.True(t, ok)
+	assert.Equal(t, "a", txID)
+	assert.Equal(t, 0.1, rate)
+}
+
+func TestMempoolGraph_CPFPFee(t *testing.T) {
+	t.Parallel()
+	tests := map[string]struct {
+		txID        string
+		childSize   uint64
+		satsPerByte float64
+		expFee      uint64
+		expOK       bool
+	}{
+		"package below rate": {
+			txID:        "c",
+			childSize:   200,
+			satsPerByte: 1,
+			expFee:      530,
+			expOK:       true,
+		},
+		"package above rate pays for child": {
+			txID:        "b",
+			childSize:   100,
+			satsPerByte: 0.1,
+			expFee:      10,
+			expOK:       true,
+		},
+		"fractional fee rounded up": {
+			txID:        "e",
+			childSize:   101,
+			satsPerByte: 1.5,
+			expFee:      202,
+			expOK:       true,
+		},
+		"unknown tx": {
+			txID:        "z",
+			childSize:   100,
+			satsPerByte: 1,
+		},
+	}
+
+	for name, test := range tests {
+		test := test
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			fee, ok := bn.NewMempoolGraph(testMempool()).CPFPFee(test.txID, test.childSize, test.satsPerByte)
+			assert.Equal(t, test.expOK, ok)
+			assert.Equal(t, test.expFee, fee)
+		})
+	}
+}
+
+func TestBlockChainClient_MempoolGraph(t *testing.T) {
+	t.Parallel()
+	mempool := testMempool()
+	c := bn.NewNodeClient(bn.WithCustomRPC(&mocks.MockRPC{
+		DoFunc: func(ctx context.Context, method string, out interface{}, args ...interface{}) error {
+			assert.Equal(t, "b", args[0])
+			switch method {
+			case "getmempoolentry":
+				*(out.(*models.MempoolEntry)) = mempool["b"]
+			case "getmempoolancestors":
+				*(out.(*models.MempoolTxs)) = models.MempoolTxs{"a": mempool["a"]}
+			case "getmempooldescendants":
+				*(out.(*models.MempoolTxs)) = models.MempoolTxs{"c": mempool["c"]}
+			}
+			return nil
+		},
+	}))
+
+	g, err := c.MempoolGraph(context.Background(), "b")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"a"}, g.Ancestors("b"))
+	assert.Equal(t, []string{"c"}, g.Descendants("b"))
+	_, ok := g.Entry("d")
+	assert.False(t, ok)
+}
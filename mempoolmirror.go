@@ -0,0 +1,445 @@
+package bn
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/libsv/go-bn/internal/util"
+	"github.com/libsv/go-bn/models"
+	"github.com/libsv/go-bn/zmq"
+	"github.com/libsv/go-bt/v2"
+)
+
+// mirrorBatchSize the number of txs fetched from the node per batch.
+const mirrorBatchSize = 500
+
+// Reasons txs are removed from a MempoolMirror, other than those given by the node
+// in `discardfrommempool` messages.
+const (
+	MempoolRemovedInBlock   = "included-in-block"
+	MempoolRemovedReconcile = "reconcile"
+)
+
+// MempoolEvent a change to a MempoolMirror, either a MempoolTxAdded or a
+// MempoolTxRemoved.
+type MempoolEvent interface {
+	mempoolEvent()
+}
+
+// MempoolTxAdded event when a tx enters the mempool.
+type MempoolTxAdded struct {
+	Tx *models.MempoolTx
+}
+
+// MempoolTxRemoved event when a tx leaves the mempool, either by being mined into
+// the block with BlockHash or being discarded for Reason.
+type MempoolTxRemoved struct {
+	Tx        *models.MempoolTx
+	Reason    string
+	BlockHash string
+}
+
+func (MempoolTxAdded) mempoolEvent()   {}
+func (MempoolTxRemoved) mempoolEvent() {}
+
+// mirrorEvent a zmq message awaiting the mirror, adding the tx with txID, or
+// removing it should remove be set.
+type mirrorEvent struct {
+	txID      string
+	tx        *bt.Tx
+	remove    bool
+	reason    string
+	blockHash string
+}
+
+// MempoolMirror an in-process replica of the node's mempool. It is seeded from
+// `getrawmempool` and kept up to date by the node's zmq messages, periodically
+// reconciling against `getrawmempool` to correct any drift, such as from missed
+// messages:
+//
+//	m := bn.NewMempoolMirror(c, mq)
+//	go m.Run(ctx)
+//	if conflicts := m.Conflicts(tx); len(conflicts) > 0 {}
+//
+// Its lookups are safe for concurrent use whilst it runs.
+type MempoolMirror struct {
+	c         NodeClient
+	mq        zmq.NodeMQ
+	interval  time.Duration
+	useHashTx bool
+	events    chan mirrorEvent
+	drifted   chan struct{}
+
+	mu     sync.RWMutex
+	txs    map[string]*models.MempoolTx
+	spends map[string]string
+	stats  models.MempoolStats
+	subs   map[int]func(e MempoolEvent)
+	nextID int
+}
+
+// MempoolMirrorOptFunc for setting mempool mirror options.
+type MempoolMirrorOptFunc func(m *MempoolMirror)
+
+// MirrorReconcileInterval set how often the mirror is reconciled against the node's
+// mempool. Defaults to a minute.
+func MirrorReconcileInterval(d time.Duration) MempoolMirrorOptFunc {
+	return func(m *MempoolMirror) {
+		m.interval = d
+	}
+}
+
+// MirrorHashTx follow txs entering the mempool by their `hashtx` messages, fetching
+// each from the node, rather than by `rawtx`, for nodes not publishing `rawtx`.
+func MirrorHashTx() MempoolMirrorOptFunc {
+	return func(m *MempoolMirror) {
+		m.useHashTx = true
+	}
+}
+
+// NewMempoolMirror returns a mirror of the mempool of the node c is a client of, and
+// mq is connected to. Whilst running, the mirror subscribes to `rawtx`, or `hashtx`,
+// `discardfrommempool` and `removedfrommempoolblock` through zmq.Shared, so sharing
+// the topics with other subscribers of mq.
+func NewMempoolMirror(c NodeClient, mq zmq.NodeMQ, oo ...MempoolMirrorOptFunc) *MempoolMirror {
+	m := &MempoolMirror{
+		c:        c,
+		mq:       zmq.Shared(mq),
+		interval: time.Minute,
+		events:   make(chan mirrorEvent, 10000),
+		drifted:  make(chan struct{}, 1),
+		txs:      make(map[string]*models.MempoolTx),
+		spends:   make(map[string]string),
+		subs:     make(map[int]func(e MempoolEvent)),
+	}
+	for _, o := range oo {
+		o(m)
+	}
+	return m
+}
+
+// Run seed the mirror and keep it up to date until ctx is done or the node errors.
+func (m *MempoolMirror) Run(ctx context.Context) error {
+	if err := m.subscribe(); err != nil {
+		return err
+	}
+	defer m.unsubscribe()
+
+	if err := m.seed(ctx); err != nil {
+		return err
+	}
+
+	ticker := time.NewTicker(m.interval)
+	defer ticker.Stop()
+	for {
+		var err error
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case e := <-m.events:
+			err = m.apply(ctx, e)
+		case <-m.drifted:
+			err = m.reconcile(ctx)
+		case <-ticker.C:
+			err = m.reconcile(ctx)
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// Tx the tx with txID, should it be in the mempool.
+func (m *MempoolMirror) Tx(txID string) (*models.MempoolTx, bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	tx, ok := m.txs[txID]
+	return tx, ok
+}
+
+// Conflicts the txids of the txs in the mempool spending any of the outputs tx spends.
+func (m *MempoolMirror) Conflicts(tx *bt.Tx) []string {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	txID := tx.TxID()
+	var conflicts []string
+	for _, in := range tx.Inputs {
+		spender, ok := m.spends[outpointKey(in.PreviousTxIDStr(), in.PreviousTxOutIndex)]
+		if ok && spender != txID && !contains(conflicts, spender) {
+			conflicts = append(conflicts, spender)
+		}
+	}
+	return conflicts
+}
+
+// Stats the number, total size and total fees of the txs in the mempool.
+func (m *MempoolMirror) Stats() models.MempoolStats {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.stats
+}
+
+// Subscribe pass each change to the mirror to fn, until the returned func is called.
+// fn is called from the mirror's Run, so should return promptly.
+func (m *MempoolMirror) Subscribe(fn func(e MempoolEvent)) func() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	id := m.nextID
+	m.nextID++
+	m.subs[id] = fn
+	return func() {
+		m.mu.Lock()
+		defer m.mu.Unlock()
+		delete(m.subs, id)
+	}
+}
+
+// subscribe to the mirror's topics, unsubscribing from those subscribed to should
+// any fail.
+func (m *MempoolMirror) subscribe() error {
+	var err error
+	if m.useHashTx {
+		err = m.mq.SubscribeHashTx(func(_ context.Context, txID string) {
+			m.queue(mirrorEvent{txID: txID})
+		})
+	} else {
+		err = m.mq.SubscribeRawTx(func(_ context.Context, tx *bt.Tx) {
+			m.queue(mirrorEvent{txID: tx.TxID(), tx: tx})
+		})
+	}
+	if err == nil {
+		err = m.mq.SubscribeDiscardFromMempool(func(_ context.Context, d *zmq.MempoolDiscard) {
+			m.queue(mirrorEvent{txID: d.TxID, remove: true, reason: d.Reason})
+		})
+	}
+	if err == nil {
+		err = m.mq.SubscribeRemovedFromMempoolBlock(func(_ context.Context, d *zmq.MempoolDiscard) {
+			m.queue(mirrorEvent{txID: d.TxID, remove: true, reason: MempoolRemovedInBlock, blockHash: d.BlockHash})
+		})
+	}
+	if err != nil {
+		m.unsubscribe()
+	}
+	return err
+}
+
+func (m *MempoolMirror) unsubscribe() {
+	topics := []zmq.Topic{zmq.TopicRawTx, zmq.TopicDiscardFromMempool, zmq.TopicRemovedFromMempoolBlock}
+	if m.useHashTx {
+		topics[0] = zmq.TopicHashTx
+	}
+	for _, t := range topics {
+		_ = m.mq.Unsubscribe(t)
+	}
+}
+
+// queue an event for Run. Should Run fall too far behind, the event is dropped and
+// the mirror reconciled instead.
+func (m *MempoolMirror) queue(e mirrorEvent) {
+	select {
+	case m.events <- e:
+	default:
+		select {
+		case m.drifted <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// seed the mirror with the node's mempool. The verbose mempool listing carries each
+// tx's entry, so only the txs themselves are fetched.
+func (m *MempoolMirror) seed(ctx context.Context) error {
+	entries, err := m.c.RawMempool(ctx)
+	if err != nil {
+		return err
+	}
+	txIDs := make([]string, 0, len(entries))
+	for txID := range entries {
+		txIDs = append(txIDs, txID)
+	}
+
+	for len(txIDs) > 0 {
+		n := len(txIDs)
+		if n > mirrorBatchSize {
+			n = mirrorBatchSize
+		}
+
+		b := NewBatch()
+		txs := make([]*BatchTx, n)
+		for i, txID := range txIDs[:n] {
+			txs[i] = b.RawTransaction(txID)
+		}
+		if err := m.c.Batch(ctx, b); err != nil {
+			return err
+		}
+
+		for i, txID := range txIDs[:n] {
+			tx, err := txs[i].Result()
+			if errors.Is(err, models.ErrNotFound) {
+				continue
+			}
+			if err != nil {
+				return fmt.Errorf("tx %s: %w", txID, err)
+			}
+			entry := entries[txID]
+			m.add(&models.MempoolTx{Tx: tx, Entry: &entry})
+		}
+		txIDs = txIDs[n:]
+	}
+	return nil
+}
+
+// reconcile the mirror with the node's mempool, adding the txs it is missing and
+// removing those which have since left the node's mempool.
+func (m *MempoolMirror) reconcile(ctx context.Context) error {
+	txIDs, err := m.c.RawMempoolIDs(ctx)
+	if err != nil {
+		return err
+	}
+
+	inMempool := make(map[string]bool, len(txIDs))
+	var missing []string
+	for _, txID := range txIDs {
+		inMempool[txID] = true
+		if _, ok := m.Tx(txID); !ok {
+			missing = append(missing, txID)
+		}
+	}
+
+	m.mu.RLock()
+	var stale []string
+	for txID := range m.txs {
+		if !inMempool[txID] {
+			stale = append(stale, txID)
+		}
+	}
+	m.mu.RUnlock()
+	for _, txID := range stale {
+		m.remove(mirrorEvent{txID: txID, reason: MempoolRemovedReconcile})
+	}
+
+	return m.fetch(ctx, missing, nil)
+}
+
+func (m *MempoolMirror) apply(ctx context.Context, e mirrorEvent) error {
+	if e.remove {
+		m.remove(e)
+		return nil
+	}
+	if _, ok := m.Tx(e.txID); ok {
+		return nil
+	}
+	var known map[string]*bt.Tx
+	if e.tx != nil {
+		known = map[string]*bt.Tx{e.txID: e.tx}
+	}
+	return m.fetch(ctx, []string{e.txID}, known)
+}
+
+// fetch the mempool entries, and txs not known, of the txs with txIDs, adding those
+// still in the mempool.
+func (m *MempoolMirror) fetch(ctx context.Context, txIDs []string, known map[string]*bt.Tx) error {
+	for len(txIDs) > 0 {
+		n := len(txIDs)
+		if n > mirrorBatchSize {
+			n = mirrorBatchSize
+		}
+
+		b := NewBatch()
+		entries := make([]*BatchMempoolEntry, n)
+		txs := make([]*BatchTx, n)
+		for i, txID := range txIDs[:n] {
+			entries[i] = b.MempoolEntry(txID)
+			if _, ok := known[txID]; !ok {
+				txs[i] = b.RawTransaction(txID)
+			}
+		}
+		if err := m.c.Batch(ctx, b); err != nil {
+			return err
+		}
+
+		for i, txID := range txIDs[:n] {
+			entry, err := entries[i].Result()
+			if errors.Is(err, models.ErrNotFound) {
+				continue
+			}
+			if err != nil {
+				return fmt.Errorf("mempool entry %s: %w", txID, err)
+			}
+			tx, ok := known[txID]
+			if !ok {
+				if tx, err = txs[i].Result(); errors.Is(err, models.ErrNotFound) {
+					continue
+				} else if err != nil {
+					return fmt.Errorf("tx %s: %w", txID, err)
+				}
+			}
+			m.add(&models.MempoolTx{Tx: tx, Entry: entry})
+		}
+		txIDs = txIDs[n:]
+	}
+	return nil
+}
+
+func (m *MempoolMirror) add(tx *models.MempoolTx) {
+	txID := tx.Tx.TxID()
+	m.mu.Lock()
+	if _, ok := m.txs[txID]; ok {
+		m.mu.Unlock()
+		return
+	}
+	m.txs[txID] = tx
+	for _, in := range tx.Tx.Inputs {
+		m.spends[outpointKey(in.PreviousTxIDStr(), in.PreviousTxOutIndex)] = txID
+	}
+	m.stats.Count++
+	m.stats.Size += uint64(tx.Entry.Size)
+	m.stats.Fees += util.BSVToSatoshis(tx.Entry.Fee)
+	subs := m.subscribers()
+	m.mu.Unlock()
+
+	for _, fn := range subs {
+		fn(MempoolTxAdded{Tx: tx})
+	}
+}
+
+func (m *MempoolMirror) remove(e mirrorEvent) {
+	m.mu.Lock()
+	tx, ok := m.txs[e.txID]
+	if !ok {
+		m.mu.Unlock()
+		return
+	}
+	delete(m.txs, e.txID)
+	for _, in := range tx.Tx.Inputs {
+		key := outpointKey(in.PreviousTxIDStr(), in.PreviousTxOutIndex)
+		if m.spends[key] == e.txID {
+			delete(m.spends, key)
+		}
+	}
+	m.stats.Count--
+	m.stats.Size -= uint64(tx.Entry.Size)
+	m.stats.Fees -= util.BSVToSatoshis(tx.Entry.Fee)
+	subs := m.subscribers()
+	m.mu.Unlock()
+
+	for _, fn := range subs {
+		fn(MempoolTxRemoved{Tx: tx, Reason: e.reason, BlockHash: e.blockHash})
+	}
+}
+
+// subscribers the funcs subscribed to the mirror. The mirror must be locked.
+func (m *MempoolMirror) subscribers() []func(e MempoolEvent) {
+	subs := make([]func(e MempoolEvent), 0, len(m.subs))
+	for _, fn := range m.subs {
+		subs = append(subs, fn)
+	}
+	return subs
+}
+
+func outpointKey(txID string, vout uint32) string {
+	return fmt.Sprintf("%s:%d", txID, vout)
+}
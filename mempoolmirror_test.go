@@ -0,0 +1,217 @@
+package bn_test
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/libsv/go-bn"
+	"github.com/libsv/go-bn/fakenode"
+	bnmocks "github.com/libsv/go-bn/mocks"
+	"github.com/libsv/go-bn/models"
+	"github.com/libsv/go-bn/rpc"
+	"github.com/libsv/go-bn/zmq"
+	"github.com/libsv/go-bt/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+// testMQ a node mq capturing the subscriptions, for publishing to by hand.
+type testMQ struct {
+	mu   sync.Mutex
+	subs map[zmq.Topic]zmq.MessageFunc
+}
+
+func (q *testMQ) mock() *bnmocks.NodeMQMock {
+	q.subs = make(map[zmq.Topic]zmq.MessageFunc)
+	return &bnmocks.NodeMQMock{
+		SubscribeFunc: func(topic zmq.Topic, fn zmq.MessageFunc) error {
+			q.mu.Lock()
+			defer q.mu.Unlock()
+			q.subs[topic] = fn
+			return nil
+		},
+		UnsubscribeFunc: func(topic zmq.Topic) error {
+			q.mu.Lock()
+			defer q.mu.Unlock()
+			delete(q.subs, topic)
+			return nil
+		},
+	}
+}
+
+func (q *testMQ) subscribed() bool {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return len(q.subs) == 3
+}
+
+// publish the message body to the topic's subscriber.
+func (q *testMQ) publish(ctx context.Context, topic zmq.Topic, body []byte) {
+	q.mu.Lock()
+	fn := q.subs[topic]
+	q.mu.Unlock()
+	fn(ctx, [][]byte{[]byte(topic), body})
+}
+
+func (q *testMQ) rawTx(ctx context.Context, tx *bt.Tx) {
+	q.publish(ctx, zmq.TopicRawTx, tx.Bytes())
+}
+
+func (q *testMQ) discard(ctx context.Context, d *zmq.MempoolDiscard) {
+	bb, _ := json.Marshal(d)
+	q.publish(ctx, zmq.TopicDiscardFromMempool, bb)
+}
+
+func (q *testMQ) block(ctx context.Context, d *zmq.MempoolDiscard) {
+	bb, _ := json.Marshal(d)
+	q.publish(ctx, zmq.TopicRemovedFromMempoolBlock, bb)
+}
+
+// send fund, sign and send a tx paying addr.
+func send(t *testing.T, c bn.NodeClient, addr string) *bt.Tx {
+	ctx := context.Background()
+	tx := bt.NewTx()
+	assert.NoError(t, tx.AddP2PKHOutputFromAddress(addr, 1000))
+	funded, err := c.FundRawTransaction(ctx, tx, nil)
+	assert.NoError(t, err)
+	signed, err := c.SignRawTransaction(ctx, funded.Tx, nil)
+	assert.NoError(t, err)
+	_, err = c.SendRawTransaction(ctx, signed.Tx, nil)
+	assert.NoError(t, err)
+	return signed.Tx
+}
+
+func TestMempoolMirror(t *testing.T) {
+	t.Parallel()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	node := fakenode.New()
+	var entryCalls int32
+	c := bn.NewNodeClient(bn.WithCustomRPC(rpc.Func(
+		func(ctx context.Context, method string, out interface{}, args ...interface{}) error {
+			if method == "getmempoolentry" {
+				atomic.AddInt32(&entryCalls, 1)
+			}
+			return node.Do(ctx, method, out, args...)
+		})))
+	addr, err := c.NewAddress(ctx, nil)
+	assert.NoError(t, err)
+	_, err = c.GenerateToAddress(ctx, 104, addr, nil)
+	assert.NoError(t, err)
+	seeded := send(t, c, addr)
+
+	q := &testMQ{}
+	m := bn.NewMempoolMirror(c, q.mock(), bn.MirrorReconcileInterval(time.Hour))
+	var mu sync.Mutex
+	var events []bn.MempoolEvent
+	unsubscribe := m.Subscribe(func(e bn.MempoolEvent) {
+		mu.Lock()
+		defer mu.Unlock()
+		events = append(events, e)
+	})
+	received := func() []bn.MempoolEvent {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]bn.MempoolEvent{}, events...)
+	}
+	errs := make(chan error, 1)
+	go func() {
+		errs <- m.Run(ctx)
+	}()
+	assert.Eventually(t, func() bool {
+		return q.subscribed() && m.Stats().Count == 1
+	}, time.Second, time.Millisecond)
+
+	got, ok := m.Tx(seeded.TxID())
+	assert.True(t, ok)
+	assert.Equal(t, seeded.String(), got.Tx.String())
+	assert.Equal(t, uint32(seeded.Size()), got.Entry.Size)
+	// The seed reuses the entries listed by getrawmempool.
+	assert.Zero(t, atomic.LoadInt32(&entryCalls))
+
+	tx := send(t, c, addr)
+	q.rawTx(ctx, tx)
+	assert.Eventually(t, func() bool {
+		return len(received()) == 2
+	}, time.Second, time.Millisecond)
+	stats := m.Stats()
+	assert.Equal(t, 2, stats.Count)
+	assert.Equal(t, uint64(seeded.Size()+tx.Size()), stats.Size)
+	assert.NotZero(t, stats.Fees)
+
+	doubleSpend := bt.NewTx()
+	doubleSpend.Inputs = append(doubleSpend.Inputs, tx.Inputs[0])
+	assert.Equal(t, []string{tx.TxID()}, m.Conflicts(doubleSpend))
+	assert.Empty(t, m.Conflicts(tx))
+
+	hashes, err := c.Generate(ctx, 1, nil)
+	assert.NoError(t, err)
+	q.block(ctx, &zmq.MempoolDiscard{TxID: tx.TxID(), BlockHash: hashes[0]})
+	q.discard(ctx, &zmq.MempoolDiscard{TxID: seeded.TxID(), Reason: "collision-in-block-tx"})
+	assert.Eventually(t, func() bool {
+		return len(received()) == 4
+	}, time.Second, time.Millisecond)
+	assert.Equal(t, models.MempoolStats{}, m.Stats())
+	assert.Empty(t, m.Conflicts(doubleSpend))
+
+	ee := received()
+	assert.Equal(t, bn.MempoolTxAdded{Tx: got}, ee[0])
+	assert.Equal(t, tx.TxID(), ee[1].(bn.MempoolTxAdded).Tx.Tx.TxID())
+	removed := ee[2].(bn.MempoolTxRemoved)
+	assert.Equal(t, tx.TxID(), removed.Tx.Tx.TxID())
+	assert.Equal(t, bn.MempoolRemovedInBlock, removed.Reason)
+	assert.Equal(t, hashes[0], removed.BlockHash)
+	removed = ee[3].(bn.MempoolTxRemoved)
+	assert.Equal(t, seeded.TxID(), removed.Tx.Tx.TxID())
+	assert.Equal(t, "collision-in-block-tx", removed.Reason)
+
+	unsubscribe()
+	cancel()
+	assert.Equal(t, context.Canceled, <-errs)
+}
+
+func TestMempoolMirror_Reconcile(t *testing.T) {
+	t.Parallel()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	c := bn.NewNodeClient(bn.WithCustomRPC(fakenode.New()))
+	addr, err := c.NewAddress(ctx, nil)
+	assert.NoError(t, err)
+	_, err = c.GenerateToAddress(ctx, 103, addr, nil)
+	assert.NoError(t, err)
+
+	q := &testMQ{}
+	m := bn.NewMempoolMirror(c, q.mock(), bn.MirrorReconcileInterval(10*time.Millisecond))
+	go func() {
+		_ = m.Run(ctx)
+	}()
+	assert.Eventually(t, q.subscribed, time.Second, time.Millisecond)
+
+	// Neither the tx entering the mempool nor it being mined are published.
+	tx := send(t, c, addr)
+	assert.Eventually(t, func() bool {
+		_, ok := m.Tx(tx.TxID())
+		return ok
+	}, time.Second, time.Millisecond)
+
+	var reason string
+	var mu sync.Mutex
+	m.Subscribe(func(e bn.MempoolEvent) {
+		mu.Lock()
+		defer mu.Unlock()
+		if r, ok := e.(bn.MempoolTxRemoved); ok {
+			reason = r.Reason
+		}
+	})
+	_, err = c.Generate(ctx, 1, nil)
+	assert.NoError(t, err)
+	assert.Eventually(t, func() bool {
+		return m.Stats().Count == 0
+	}, time.Second, time.Millisecond)
+	mu.Lock()
+	defer mu.Unlock()
+	assert.Equal(t, bn.MempoolRemovedReconcile, reason)
+}
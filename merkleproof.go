@@ -0,0 +1,150 @@
+package bn
+
+import (
+	"context"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/libsv/go-bc"
+	"github.com/libsv/go-bk/crypto"
+	"github.com/libsv/go-bn/models"
+	"github.com/libsv/go-bt/v2"
+)
+
+// Merkle proof verification errors.
+var (
+	ErrMerkleProofInvalid     = errors.New("merkle proof does not prove tx")
+	ErrMerkleProofUnsupported = errors.New("merkle proof unsupported")
+)
+
+// HeaderStore a source of the headers of the best chain, against which merkle proofs
+// are verified. A BlockChainClient is one, as is a store of headers kept locally.
+type HeaderStore interface {
+	BlockHeader(ctx context.Context, hash string) (*models.BlockHeader, error)
+}
+
+// MerkleRootHeaderStore a HeaderStore able to look up the header of the best chain
+// with a merkle root, allowing proofs targeting a merkle root to be verified.
+type MerkleRootHeaderStore interface {
+	HeaderStore
+	BlockHeaderByMerkleRoot(ctx context.Context, merkleRoot string) (*models.BlockHeader, error)
+}
+
+// VerifyMerkleProof verify the proof proves the tx with txID to be in a block of the
+// chain served by store, recomputing the merkle root from the proof's nodes and
+// comparing it to that of the block's header, returning ErrMerkleProofInvalid should
+// they differ or the store report the block as not on its best chain, as a node does
+// with negative confirmations for a block reorged out. Proofs targeting a merkle root
+// are only verified should store be a MerkleRootHeaderStore, the root alone not
+// identifying a block to look up, and are otherwise ErrMerkleProofUnsupported. Legacy
+// proofs are first converted with models.LegacyMerkleProof.MerkleProof:
+//
+//	err := bn.VerifyMerkleProof(ctx, headers, legacy.MerkleProof(), txID)
+func VerifyMerkleProof(ctx context.Context, store HeaderStore, proof *bc.MerkleProof, txID string) error {
+	if proof.Composite {
+		return fmt.Errorf("%w: composite proofs", ErrMerkleProofUnsupported)
+	}
+	root, err := merkleRoot(proof, txID)
+	if err != nil {
+		return err
+	}
+
+	header, hash, err := targetHeader(ctx, store, proof, root)
+	if err != nil {
+		return err
+	}
+	if header.Confirmations < 0 {
+		return fmt.Errorf("%w: block %s is not on the best chain", ErrMerkleProofInvalid, hash)
+	}
+	if header.HashMerkleRootStr() != root {
+		return fmt.Errorf("%w: merkle root %s, block %s has %s", ErrMerkleProofInvalid, root, hash,
+			header.HashMerkleRootStr())
+	}
+	return nil
+}
+
+// targetHeader the header, and hash, of the block targeted by the proof, looked up in store.
+func targetHeader(ctx context.Context, store HeaderStore, proof *bc.MerkleProof,
+	root string) (*models.BlockHeader, string, error) {
+	var hash string
+	switch strings.ToLower(proof.TargetType) {
+	case "", string(models.MerkleProofTargetTypeHash):
+		hash = proof.Target
+	case string(models.MerkleProofTargetTypeHeader):
+		header, err := bc.NewBlockHeaderFromStr(proof.Target)
+		if err != nil {
+			return nil, "", err
+		}
+		if header.HashMerkleRootStr() != root {
+			return nil, "", fmt.Errorf("%w: merkle root %s, header has %s", ErrMerkleProofInvalid, root,
+				header.HashMerkleRootStr())
+		}
+		hash = hex.EncodeToString(bt.ReverseBytes(crypto.Sha256d(header.Bytes())))
+	case string(models.MerkleProofTargetTypeMerkleRoot):
+		roots, ok := store.(MerkleRootHeaderStore)
+		if !ok {
+			return nil, "", fmt.Errorf("%w: merkle root target without a MerkleRootHeaderStore",
+				ErrMerkleProofUnsupported)
+		}
+		if proof.Target != root {
+			return nil, "", fmt.Errorf("%w: merkle root %s, target is %s", ErrMerkleProofInvalid, root,
+				proof.Target)
+		}
+		header, err := roots.BlockHeaderByMerkleRoot(ctx, root)
+		if err != nil {
+			return nil, "", err
+		}
+		return header, header.Hash, nil
+	default:
+		return nil, "", fmt.Errorf("%w: target type %s", ErrMerkleProofUnsupported, proof.TargetType)
+	}
+
+	header, err := store.BlockHeader(ctx, hash)
+	if err != nil {
+		return nil, "", err
+	}
+	return header, hash, nil
+}
+
+func (c *client) VerifyMerkleProof(ctx context.Context, proof *bc.MerkleProof, txID string) error {
+	return VerifyMerkleProof(ctx, c, proof, txID)
+}
+
+// merkleRoot the merkle root computed from the proof of the tx with txID.
+func merkleRoot(proof *bc.MerkleProof, txID string) (string, error) {
+	id := proof.TxOrID
+	if len(id) > 64 {
+		tx, err := bt.NewTxFromString(proof.TxOrID)
+		if err != nil {
+			return "", err
+		}
+		id = tx.TxID()
+	}
+	if id != txID {
+		return "", fmt.Errorf("%w: proof is of tx %s", ErrMerkleProofInvalid, id)
+	}
+
+	root, index := txID, proof.Index
+	for _, node := range proof.Nodes {
+		if node == "*" {
+			node = root
+		}
+		var err error
+		if index%2 == 0 {
+			root, err = bc.MerkleTreeParentStr(root, node)
+		} else {
+			root, err = bc.MerkleTreeParentStr(node, root)
+		}
+		if err != nil {
+			return "", err
+		}
+		index /= 2
+	}
+	if index != 0 {
+		return "", fmt.Errorf("%w: index %d beyond tree of %d levels", ErrMerkleProofInvalid, proof.Index,
+			len(proof.Nodes))
+	}
+	return root, nil
+}
@@ -0,0 +1,282 @@
+package bn_test
+
+import (
+	"context"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/libsv/go-bc"
+	"github.com/libsv/go-bk/crypto"
+	"github.com/libsv/go-bn"
+	"github.com/libsv/go-bn/internal/config"
+	"github.com/libsv/go-bn/internal/service"
+	bnmocks "github.com/libsv/go-bn/mocks"
+	"github.com/libsv/go-bn/models"
+	"github.com/libsv/go-bn/testing/util"
+	"github.com/libsv/go-bt/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+// merkleTree the proof of each tx of a block of the txs with txIDs, and its merkle root.
+func merkleTree(t *testing.T, txIDs []string) ([]*bc.MerkleProof, string) {
+	proofs := make([]*bc.MerkleProof, len(txIDs))
+	for i := range proofs {
+		proofs[i] = &bc.MerkleProof{Index: uint64(i), TxOrID: txIDs[i]}
+	}
+
+	level := txIDs
+	for len(level) > 1 {
+		var next []string
+		for i := 0; i < len(level); i += 2 {
+			right := level[i]
+			if i+1 < len(level) {
+				right = level[i+1]
+			}
+			parent, err := bc.MerkleTreeParentStr(level[i], right)
+			assert.NoError(t, err)
+			next = append(next, parent)
+		}
+		for _, p := range proofs {
+			sibling := int(p.Index>>len(p.Nodes)) ^ 1
+			if sibling < len(level) {
+				p.Nodes = append(p.Nodes, level[sibling])
+			} else {
+				p.Nodes = append(p.Nodes, "*")
+			}
+		}
+		level = next
+	}
+
+	root, err := bc.BuildMerkleRoot(txIDs)
+	assert.NoError(t, err)
+	assert.Equal(t, root, level[0])
+	return proofs, root
+}
+
+// rootStore a header store able to look up headers by merkle root.
+type rootStore struct {
+	bn.HeaderStore
+	headers map[string]*models.BlockHeader
+}
+
+func (s *rootStore) BlockHeaderByMerkleRoot(ctx context.Context, root string) (*models.BlockHeader, error) {
+	if h, ok := s.headers[root]; ok {
+		return h, nil
+	}
+	return nil, &models.Error{Code: models.ErrNotFound.Code, Message: "Block not found"}
+}
+
+func TestVerifyMerkleProof(t *testing.T) {
+	t.Parallel()
+	txIDs := make([]string, 5)
+	for i := range txIDs {
+		txIDs[i] = hex.EncodeToString(crypto.Sha256d([]byte(fmt.Sprint(i))))
+	}
+	proofs, root := merkleTree(t, txIDs)
+	rootBytes, err := hex.DecodeString(root)
+	assert.NoError(t, err)
+	header := &bc.BlockHeader{
+		Version:        1,
+		HashPrevBlock:  make([]byte, 32),
+		HashMerkleRoot: rootBytes,
+		Bits:           []byte{0x1d, 0x00, 0xff, 0xff},
+	}
+	blockHash := hex.EncodeToString(bt.ReverseBytes(crypto.Sha256d(header.Bytes())))
+	staleHeader := *header
+	staleHeader.Nonce = 2
+	staleHash := hex.EncodeToString(bt.ReverseBytes(crypto.Sha256d(staleHeader.Bytes())))
+	// A proof of a tree of the forger's making, consistent with its own target.
+	forgedRoot, err := bc.MerkleTreeParentStr(txIDs[0], txIDs[1])
+	assert.NoError(t, err)
+
+	store := &bnmocks.BlockChainClientMock{
+		BlockHeaderFunc: func(ctx context.Context, hash string) (*models.BlockHeader, error) {
+			switch hash {
+			case blockHash:
+				return &models.BlockHeader{BlockHeader: header, Hash: hash, Confirmations: 1}, nil
+			case staleHash:
+				return &models.BlockHeader{BlockHeader: &staleHeader, Hash: hash, Confirmations: -1}, nil
+			}
+			return nil, &models.Error{Code: models.ErrNotFound.Code, Message: "Block not found"}
+		},
+	}
+	roots := &rootStore{HeaderStore: store, headers: map[string]*models.BlockHeader{
+		root: {BlockHeader: header, Hash: blockHash, Confirmations: 1},
+	}}
+	proof := func(i int, fn func(p *bc.MerkleProof)) *bc.MerkleProof {
+		p := *proofs[i]
+		p.Nodes = append([]string{}, p.Nodes...)
+		p.Target = blockHash
+		if fn != nil {
+			fn(&p)
+		}
+		return &p
+	}
+
+	tests := map[string]struct {
+		proof  *bc.MerkleProof
+		store  bn.HeaderStore
+		txID   string
+		expErr error
+	}{
+		"hash target": {
+			proof: proof(1, nil),
+			txID:  txIDs[1],
+		},
+		"hash target of duplicated last tx": {
+			proof: proof(4, nil),
+			txID:  txIDs[4],
+		},
+		"header target": {
+			proof: proof(2, func(p *bc.MerkleProof) {
+				p.TargetType = string(models.MerkleProofTargetTypeHeader)
+				p.Target = header.String()
+			}),
+			txID: txIDs[2],
+		},
+		"merkle root target": {
+			proof: proof(3, func(p *bc.MerkleProof) {
+				p.TargetType = "merkleRoot"
+				p.Target = root
+			}),
+			store: roots,
+			txID:  txIDs[3],
+		},
+		"merkle root target without root lookup": {
+			proof: proof(3, func(p *bc.MerkleProof) {
+				p.TargetType = "merkleRoot"
+				p.Target = root
+			}),
+			txID:   txIDs[3],
+			expErr: bn.ErrMerkleProofUnsupported,
+		},
+		"forged merkle root target": {
+			proof: &bc.MerkleProof{
+				TxOrID:     txIDs[0],
+				Nodes:      []string{txIDs[1]},
+				TargetType: string(models.MerkleProofTargetTypeMerkleRoot),
+				Target:     forgedRoot,
+			},
+			store:  roots,
+			txID:   txIDs[0],
+			expErr: models.ErrNotFound,
+		},
+		"proof of another tx": {
+			proof:  proof(1, nil),
+			txID:   txIDs[0],
+			expErr: bn.ErrMerkleProofInvalid,
+		},
+		"tampered node": {
+			proof: proof(0, func(p *bc.MerkleProof) {
+				p.Nodes[1] = txIDs[0]
+			}),
+			txID:   txIDs[0],
+			expErr: bn.ErrMerkleProofInvalid,
+		},
+		"wrong index": {
+			proof: proof(0, func(p *bc.MerkleProof) {
+				p.Index = 1
+			}),
+			txID:   txIDs[0],
+			expErr: bn.ErrMerkleProofInvalid,
+		},
+		"index beyond tree": {
+			proof: proof(0, func(p *bc.MerkleProof) {
+				p.Index = 8
+			}),
+			txID:   txIDs[0],
+			expErr: bn.ErrMerkleProofInvalid,
+		},
+		"wrong merkle root target": {
+			proof: proof(3, func(p *bc.MerkleProof) {
+				p.TargetType = string(models.MerkleProofTargetTypeMerkleRoot)
+				p.Target = txIDs[0]
+			}),
+			store:  roots,
+			txID:   txIDs[3],
+			expErr: bn.ErrMerkleProofInvalid,
+		},
+		"header not in chain": {
+			proof: proof(2, func(p *bc.MerkleProof) {
+				h := *header
+				h.Nonce++
+				p.TargetType = string(models.MerkleProofTargetTypeHeader)
+				p.Target = h.String()
+			}),
+			txID:   txIDs[2],
+			expErr: models.ErrNotFound,
+		},
+		"block not on best chain": {
+			proof: proof(1, func(p *bc.MerkleProof) {
+				p.Target = staleHash
+			}),
+			txID:   txIDs[1],
+			expErr: bn.ErrMerkleProofInvalid,
+		},
+		"unknown block": {
+			proof: proof(1, func(p *bc.MerkleProof) {
+				p.Target = txIDs[0]
+			}),
+			txID:   txIDs[1],
+			expErr: models.ErrNotFound,
+		},
+		"composite proof": {
+			proof: proof(1, func(p *bc.MerkleProof) {
+				p.Composite = true
+			}),
+			txID:   txIDs[1],
+			expErr: bn.ErrMerkleProofUnsupported,
+		},
+	}
+
+	for name, test := range tests {
+		test := test
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			s := test.store
+			if s == nil {
+				s = store
+			}
+			err := bn.VerifyMerkleProof(context.Background(), s, test.proof, test.txID)
+			if test.expErr == nil {
+				assert.NoError(t, err)
+				return
+			}
+			assert.True(t, errors.Is(err, test.expErr), err)
+		})
+	}
+}
+
+func TestBlockChainClient_LegacyMerkleProof(t *testing.T) {
+	t.Parallel()
+	const txID = "13603923fecfea75e1cea6c769c44ca7b1e19510018bb6a63f4bd6ddc9813379"
+	svr, cls := util.TestServer(t, &models.Request{
+		ID:      "go-bn",
+		JSONRpc: "1.0",
+		Method:  "getmerkleproof",
+		Params:  []interface{}{txID},
+	}, "getmerkleproof")
+	defer cls()
+
+	c := bn.NewNodeClient(bn.WithCustomRPC(service.NewRPC(&config.RPC{Host: svr.URL}, &http.Client{})))
+	legacy, err := c.LegacyMerkleProof(context.Background(), txID, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, &bc.MerkleProof{
+		Index:      1,
+		TxOrID:     txID,
+		Target:     "0000000000000000000000000000000000000000000000000000000000000001",
+		Nodes:      []string{"c98f2b1187c569d98e32f69cff4f09c8548208b0281661742f68af3ac877b8fb"},
+		TargetType: "hash",
+	}, legacy.MerkleProof())
+
+	store := &bnmocks.BlockChainClientMock{
+		BlockHeaderFunc: func(ctx context.Context, hash string) (*models.BlockHeader, error) {
+			assert.Equal(t, legacy.Target.Hash, hash)
+			return &legacy.Target, nil
+		},
+	}
+	assert.NoError(t, bn.VerifyMerkleProof(context.Background(), store, legacy.MerkleProof(), txID))
+}
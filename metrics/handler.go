@@ -0,0 +1,71 @@
+package metrics
+
+import (
+	"bufio"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+)
+
+// Handler an http.Handler serving the metrics in the Prometheus text exposition format.
+func (c *Collector) Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		bw := bufio.NewWriter(w)
+		writePrometheus(bw, c.Snapshot())
+		_ = bw.Flush()
+	})
+}
+
+func writePrometheus(w *bufio.Writer, s Snapshot) {
+	names := make([]string, 0, len(s.Methods))
+	for name := range s.Methods {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	header := func(name, typ, help string) {
+		fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s %s\n", name, help, name, typ)
+	}
+
+	header("bn_rpc_calls_total", "counter", "Total rpc requests completed by the node, each retry its own, by method.")
+	for _, n := range names {
+		fmt.Fprintf(w, "bn_rpc_calls_total{method=%q} %d\n", n, s.Methods[n].Calls)
+	}
+
+	header("bn_rpc_errors_total", "counter", "Total rpc calls failed, by method and node error code.")
+	for _, n := range names {
+		codes := make([]int, 0, len(s.Methods[n].ErrorsByCode))
+		for code := range s.Methods[n].ErrorsByCode {
+			codes = append(codes, code)
+		}
+		sort.Ints(codes)
+		for _, code := range codes {
+			fmt.Fprintf(w, "bn_rpc_errors_total{method=%q,code=\"%d\"} %d\n", n, code, s.Methods[n].ErrorsByCode[code])
+		}
+	}
+
+	header("bn_rpc_in_flight", "gauge", "Rpc calls currently in flight, by method.")
+	for _, n := range names {
+		fmt.Fprintf(w, "bn_rpc_in_flight{method=%q} %d\n", n, s.Methods[n].InFlight)
+	}
+
+	header("bn_rpc_dedupe_hits_total", "counter", "Rpc calls served by an identical call already in flight, by method.")
+	for _, n := range names {
+		fmt.Fprintf(w, "bn_rpc_dedupe_hits_total{method=%q} %d\n", n, s.Methods[n].DedupeHits)
+	}
+
+	header("bn_rpc_duration_seconds", "histogram", "Latency of rpc requests to the node, each retry its own, by method.")
+	for _, n := range names {
+		h := s.Methods[n].Latency
+		for _, b := range h.Buckets {
+			le := strconv.FormatFloat(b.UpperBound.Seconds(), 'g', -1, 64)
+			fmt.Fprintf(w, "bn_rpc_duration_seconds_bucket{method=%q,le=%q} %d\n", n, le, b.Count)
+		}
+		fmt.Fprintf(w, "bn_rpc_duration_seconds_bucket{method=%q,le=\"+Inf\"} %d\n", n, h.Count)
+		fmt.Fprintf(w, "bn_rpc_duration_seconds_sum{method=%q} %s\n", n,
+			strconv.FormatFloat(h.Sum.Seconds(), 'g', -1, 64))
+		fmt.Fprintf(w, "bn_rpc_duration_seconds_count{method=%q} %d\n", n, h.Count)
+	}
+}
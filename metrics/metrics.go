@@ -0,0 +1,247 @@
+// Package metrics collects per-method statistics of the rpc calls made by a node client.
+//
+// A Collector is installed with bn.WithMetrics, and may be read as a Snapshot, served
+// in the Prometheus text format via Handler, or published with expvar:
+//
+//	m := metrics.NewCollector()
+//	expvar.Publish("bn", m)
+//	http.Handle("/metrics", m.Handler())
+//	c := bn.NewNodeClient(bn.WithMetrics(m))
+//
+// Installed with bn.WithMetrics, the collector records the requests sent to the
+// node, beneath the client's cache and retries, so a retried call is recorded once
+// per attempt and a call served by the cache is not recorded.
+package metrics
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/libsv/go-bn/models"
+	"github.com/libsv/go-bn/rpc"
+)
+
+// DefaultBuckets the upper bounds of the latency histogram buckets used when none
+// are provided to NewCollector.
+var DefaultBuckets = []time.Duration{
+	time.Millisecond,
+	5 * time.Millisecond,
+	10 * time.Millisecond,
+	25 * time.Millisecond,
+	50 * time.Millisecond,
+	100 * time.Millisecond,
+	250 * time.Millisecond,
+	500 * time.Millisecond,
+	time.Second,
+	2500 * time.Millisecond,
+	5 * time.Second,
+	10 * time.Second,
+}
+
+// Snapshot the statistics of each method called, keyed by method.
+type Snapshot struct {
+	Methods map[string]MethodStats `json:"methods"`
+}
+
+// MethodStats the statistics of calls to a single method.
+type MethodStats struct {
+	Calls  uint64 `json:"calls"`
+	Errors uint64 `json:"errors"`
+	// ErrorsByCode the errors counted by node error code. Errors which did not come
+	// from the node, such as transport failures, are counted under code 0.
+	ErrorsByCode map[int]uint64 `json:"errorsByCode"`
+	InFlight     int64          `json:"inFlight"`
+	// DedupeHits the calls served by sharing the response of an identical call
+	// already in flight, rather than sending a request of their own.
+	DedupeHits uint64    `json:"dedupeHits"`
+	Latency    Histogram `json:"latency"`
+}
+
+// Histogram a latency histogram.
+type Histogram struct {
+	// Buckets cumulative counts of calls completing within each upper bound.
+	Buckets []Bucket      `json:"buckets"`
+	Count   uint64        `json:"count"`
+	Sum     time.Duration `json:"sum"`
+}
+
+// Bucket a histogram bucket.
+type Bucket struct {
+	UpperBound time.Duration `json:"upperBound"`
+	Count      uint64        `json:"count"`
+}
+
+type methodStats struct {
+	calls    uint64
+	errors   uint64
+	codes    map[int]uint64
+	inFlight int64
+	dedupe   uint64
+	counts   []uint64
+	sum      time.Duration
+}
+
+// Collector collects per-method rpc metrics. It is safe for concurrent use.
+type Collector struct {
+	buckets []time.Duration
+
+	mu      sync.Mutex
+	methods map[string]*methodStats
+}
+
+// NewCollector returns a collector recording latencies into histograms with the
+// provided bucket upper bounds, or DefaultBuckets if none are provided.
+func NewCollector(buckets ...time.Duration) *Collector {
+	if len(buckets) == 0 {
+		buckets = DefaultBuckets
+	}
+	bb := make([]time.Duration, len(buckets))
+	copy(bb, buckets)
+	sort.Slice(bb, func(i, j int) bool { return bb[i] < bb[j] })
+
+	return &Collector{
+		buckets: bb,
+		methods: make(map[string]*methodStats),
+	}
+}
+
+type middleware struct {
+	c    *Collector
+	next rpc.RPC
+}
+
+// Middleware an rpc.Middleware recording each call passing through it. Batches are
+// kept intact, with each call within recorded as taking as long as the batch.
+func (c *Collector) Middleware(next rpc.RPC) rpc.RPC {
+	return &middleware{c: c, next: next}
+}
+
+// Do record the call.
+func (m *middleware) Do(ctx context.Context, method string, out interface{}, args ...interface{}) error {
+	m.c.start(method)
+	start := time.Now()
+	err := m.next.Do(ctx, method, out, args...)
+	m.c.done(method, time.Since(start), err)
+	return err
+}
+
+// DoBatch record each call in the batch.
+func (m *middleware) DoBatch(ctx context.Context, calls ...*rpc.Call) error {
+	for _, call := range calls {
+		m.c.start(call.Method)
+	}
+	start := time.Now()
+	err := rpc.DoBatch(ctx, m.next, calls...)
+	d := time.Since(start)
+	for _, call := range calls {
+		callErr := call.Err
+		if err != nil {
+			callErr = err
+		}
+		m.c.done(call.Method, d, callErr)
+	}
+	return err
+}
+
+// DoStream record the streamed call, including the time taken to read its result.
+func (m *middleware) DoStream(ctx context.Context, method string, fn rpc.StreamFunc, args ...interface{}) error {
+	m.c.start(method)
+	start := time.Now()
+	err := rpc.DoStream(ctx, m.next, method, fn, args...)
+	m.c.done(method, time.Since(start), err)
+	return err
+}
+
+// Dedupe record a call to the method being served by an identical call already in flight.
+func (c *Collector) Dedupe(method string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.method(method).dedupe++
+}
+
+// Snapshot a copy of the metrics collected so far.
+func (c *Collector) Snapshot() Snapshot {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	s := Snapshot{Methods: make(map[string]MethodStats, len(c.methods))}
+	for name, m := range c.methods {
+		stats := MethodStats{
+			Calls:        m.calls,
+			Errors:       m.errors,
+			ErrorsByCode: make(map[int]uint64, len(m.codes)),
+			InFlight:     m.inFlight,
+			DedupeHits:   m.dedupe,
+			Latency: Histogram{
+				Buckets: make([]Bucket, len(c.buckets)),
+				Sum:     m.sum,
+			},
+		}
+		for code, n := range m.codes {
+			stats.ErrorsByCode[code] = n
+		}
+		var cum uint64
+		for i, b := range c.buckets {
+			cum += m.counts[i]
+			stats.Latency.Buckets[i] = Bucket{UpperBound: b, Count: cum}
+		}
+		stats.Latency.Count = cum + m.counts[len(c.buckets)]
+		s.Methods[name] = stats
+	}
+
+	return s
+}
+
+// String the snapshot as JSON, satisfying expvar.Var.
+func (c *Collector) String() string {
+	bb, err := json.Marshal(c.Snapshot())
+	if err != nil {
+		return "{}"
+	}
+	return string(bb)
+}
+
+func (c *Collector) start(method string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.method(method).inFlight++
+}
+
+func (c *Collector) done(method string, d time.Duration, err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	m := c.method(method)
+	m.inFlight--
+	m.calls++
+	m.sum += d
+	m.counts[sort.Search(len(c.buckets), func(i int) bool { return d <= c.buckets[i] })]++
+	if err == nil {
+		return
+	}
+
+	m.errors++
+	var code int
+	var nodeErr *models.Error
+	if errors.As(err, &nodeErr) {
+		code = nodeErr.Code
+	}
+	m.codes[code]++
+}
+
+// method the stats of the method, created on first use. The lock must be held.
+func (c *Collector) method(name string) *methodStats {
+	m, ok := c.methods[name]
+	if !ok {
+		m = &methodStats{
+			codes:  make(map[int]uint64),
+			counts: make([]uint64, len(c.buckets)+1),
+		}
+		c.methods[name] = m
+	}
+	return m
+}
@@ -0,0 +1,174 @@
+package metrics_test
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/libsv/go-bn/metrics"
+	"github.com/libsv/go-bn/models"
+	"github.com/libsv/go-bn/rpc"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCollector_Middleware(t *testing.T) {
+	t.Parallel()
+
+	buckets := []time.Duration{10 * time.Millisecond, time.Second}
+	tests := map[string]struct {
+		do       func(r rpc.RPC, m *metrics.Collector)
+		expStats map[string]metrics.MethodStats
+	}{
+		"successful calls are counted": {
+			do: func(r rpc.RPC, m *metrics.Collector) {
+				_ = r.Do(context.TODO(), "getinfo", nil)
+				_ = r.Do(context.TODO(), "getinfo", nil)
+			},
+			expStats: map[string]metrics.MethodStats{
+				"getinfo": {
+					Calls:        2,
+					ErrorsByCode: map[int]uint64{},
+					Latency: metrics.Histogram{
+						Buckets: []metrics.Bucket{
+							{UpperBound: 10 * time.Millisecond, Count: 2},
+							{UpperBound: time.Second, Count: 2},
+						},
+						Count: 2,
+					},
+				},
+			},
+		},
+		"errors are counted by node code": {
+			do: func(r rpc.RPC, m *metrics.Collector) {
+				_ = r.Do(context.TODO(), "getrawtransaction", nil, "notfound")
+				_ = r.Do(context.TODO(), "getrawtransaction", nil, "notfound")
+				_ = r.Do(context.TODO(), "getrawtransaction", nil, "transport")
+				_ = r.Do(context.TODO(), "getrawtransaction", nil, "slow")
+			},
+			expStats: map[string]metrics.MethodStats{
+				"getrawtransaction": {
+					Calls:        4,
+					Errors:       3,
+					ErrorsByCode: map[int]uint64{-5: 2, 0: 1},
+					Latency: metrics.Histogram{
+						Buckets: []metrics.Bucket{
+							{UpperBound: 10 * time.Millisecond, Count: 3},
+							{UpperBound: time.Second, Count: 4},
+						},
+						Count: 4,
+					},
+				},
+			},
+		},
+		"batched calls are each counted": {
+			do: func(r rpc.RPC, m *metrics.Collector) {
+				_ = rpc.DoBatch(context.TODO(), r,
+					&rpc.Call{Method: "getblockcount"},
+					&rpc.Call{Method: "getrawtransaction", Args: []interface{}{"notfound"}},
+				)
+			},
+			expStats: map[string]metrics.MethodStats{
+				"getblockcount": {
+					Calls:        1,
+					ErrorsByCode: map[int]uint64{},
+					Latency: metrics.Histogram{
+						Buckets: []metrics.Bucket{
+							{UpperBound: 10 * time.Millisecond, Count: 1},
+							{UpperBound: time.Second, Count: 1},
+						},
+						Count: 1,
+					},
+				},
+				"getrawtransaction": {
+					Calls:        1,
+					Errors:       1,
+					ErrorsByCode: map[int]uint64{-5: 1},
+					Latency: metrics.Histogram{
+						Buckets: []metrics.Bucket{
+							{UpperBound: 10 * time.Millisecond, Count: 1},
+							{UpperBound: time.Second, Count: 1},
+						},
+						Count: 1,
+					},
+				},
+			},
+		},
+		"dedupe hits are counted": {
+			do: func(r rpc.RPC, m *metrics.Collector) {
+				m.Dedupe("getinfo")
+			},
+			expStats: map[string]metrics.MethodStats{
+				"getinfo": {
+					DedupeHits:   1,
+					ErrorsByCode: map[int]uint64{},
+					Latency: metrics.Histogram{
+						Buckets: []metrics.Bucket{
+							{UpperBound: 10 * time.Millisecond},
+							{UpperBound: time.Second},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			m := metrics.NewCollector(buckets...)
+			r := m.Middleware(rpc.Func(func(ctx context.Context, method string, out interface{}, args ...interface{}) error {
+				if len(args) == 0 {
+					return nil
+				}
+				switch args[0] {
+				case "notfound":
+					return &models.Error{Code: -5, Message: "No such transaction"}
+				case "transport":
+					return &models.TransportError{Err: errors.New("connection reset")}
+				case "slow":
+					time.Sleep(20 * time.Millisecond)
+				}
+				return nil
+			}))
+
+			test.do(r, m)
+
+			s := m.Snapshot()
+			for name, stats := range s.Methods {
+				stats.Latency.Sum = 0
+				s.Methods[name] = stats
+			}
+			assert.Equal(t, test.expStats, s.Methods)
+		})
+	}
+}
+
+func TestCollector_Handler(t *testing.T) {
+	t.Parallel()
+
+	m := metrics.NewCollector(time.Second)
+	r := m.Middleware(rpc.Func(func(ctx context.Context, method string, out interface{}, args ...interface{}) error {
+		return &models.Error{Code: -26, Message: "257: txn-already-known"}
+	}))
+	_ = r.Do(context.TODO(), "sendrawtransaction", nil)
+	m.Dedupe("sendrawtransaction")
+
+	w := httptest.NewRecorder()
+	m.Handler().ServeHTTP(w, httptest.NewRequest("GET", "/metrics", nil))
+	bb, err := ioutil.ReadAll(w.Body)
+	assert.NoError(t, err)
+
+	body := string(bb)
+	assert.Contains(t, body, "# TYPE bn_rpc_calls_total counter\n")
+	assert.Contains(t, body, `bn_rpc_calls_total{method="sendrawtransaction"} 1`+"\n")
+	assert.Contains(t, body, `bn_rpc_errors_total{method="sendrawtransaction",code="-26"} 1`+"\n")
+	assert.Contains(t, body, `bn_rpc_in_flight{method="sendrawtransaction"} 0`+"\n")
+	assert.Contains(t, body, `bn_rpc_dedupe_hits_total{method="sendrawtransaction"} 1`+"\n")
+	assert.Contains(t, body, `bn_rpc_duration_seconds_bucket{method="sendrawtransaction",le="1"} 1`+"\n")
+	assert.Contains(t, body, `bn_rpc_duration_seconds_bucket{method="sendrawtransaction",le="+Inf"} 1`+"\n")
+	assert.Contains(t, body, `bn_rpc_duration_seconds_count{method="sendrawtransaction"} 1`+"\n")
+
+	assert.Contains(t, m.String(), `"sendrawtransaction":{"calls":1,"errors":1,"errorsByCode":{"-26":1}`)
+}
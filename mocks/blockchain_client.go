@@ -60,6 +60,9 @@ var _ bn.BlockChainClient = &BlockChainClientMock{}
 // 			BlockStatsByHeightFunc: func(ctx context.Context, height int, fields ...string) (*models.BlockStats, error) {
 // 				panic("mock out the BlockStatsByHeight method")
 // 			},
+// 			BlockStreamFunc: func(ctx context.Context, hash string) *bn.BlockStream {
+// 				panic("mock out the BlockStream method")
+// 			},
 // 			ChainInfoFunc: func(ctx context.Context) (*models.ChainInfo, error) {
 // 				panic("mock out the ChainInfo method")
 // 			},
@@ -72,6 +75,9 @@ var _ bn.BlockChainClient = &BlockChainClientMock{}
 // 			CheckJournalFunc: func(ctx context.Context) (*models.JournalStatus, error) {
 // 				panic("mock out the CheckJournal method")
 // 			},
+// 			CheckOutpointsFunc: func(ctx context.Context, outpoints []models.Outpoint, includeMempool bool) ([]*models.OutpointCheck, error) {
+// 				panic("mock out the CheckOutpoints method")
+// 			},
 // 			DifficultyFunc: func(ctx context.Context) (float64, error) {
 // 				panic("mock out the Difficulty method")
 // 			},
@@ -99,6 +105,9 @@ var _ bn.BlockChainClient = &BlockChainClientMock{}
 // 			MempoolEntryFunc: func(ctx context.Context, txID string) (*models.MempoolEntry, error) {
 // 				panic("mock out the MempoolEntry method")
 // 			},
+// 			MempoolGraphFunc: func(ctx context.Context, txID string) (*bn.MempoolGraph, error) {
+// 				panic("mock out the MempoolGraph method")
+// 			},
 // 			MerkleProofFunc: func(ctx context.Context, blockHash string, txID string, opts *models.OptsMerkleProof) (*bc.MerkleProof, error) {
 // 				panic("mock out the MerkleProof method")
 // 			},
@@ -129,6 +138,9 @@ var _ bn.BlockChainClient = &BlockChainClientMock{}
 // 			VerifyChainFunc: func(ctx context.Context) (bool, error) {
 // 				panic("mock out the VerifyChain method")
 // 			},
+// 			VerifyMerkleProofFunc: func(ctx context.Context, proof *bc.MerkleProof, txID string) error {
+// 				panic("mock out the VerifyMerkleProof method")
+// 			},
 // 		}
 //
 // 		// use mockedBlockChainClient in code that requires bn.BlockChainClient
@@ -175,6 +187,9 @@ type BlockChainClientMock struct {
 	// BlockStatsByHeightFunc mocks the BlockStatsByHeight method.
 	BlockStatsByHeightFunc func(ctx context.Context, height int, fields ...string) (*models.BlockStats, error)
 
+	// BlockStreamFunc mocks the BlockStream method.
+	BlockStreamFunc func(ctx context.Context, hash string) *bn.BlockStream
+
 	// ChainInfoFunc mocks the ChainInfo method.
 	ChainInfoFunc func(ctx context.Context) (*models.ChainInfo, error)
 
@@ -187,6 +202,9 @@ type BlockChainClientMock struct {
 	// CheckJournalFunc mocks the CheckJournal method.
 	CheckJournalFunc func(ctx context.Context) (*models.JournalStatus, error)
 
+	// CheckOutpointsFunc mocks the CheckOutpoints method.
+	CheckOutpointsFunc func(ctx context.Context, outpoints []models.Outpoint, includeMempool bool) ([]*models.OutpointCheck, error)
+
 	// DifficultyFunc mocks the Difficulty method.
 	DifficultyFunc func(ctx context.Context) (float64, error)
 
@@ -214,6 +232,9 @@ type BlockChainClientMock struct {
 	// MempoolEntryFunc mocks the MempoolEntry method.
 	MempoolEntryFunc func(ctx context.Context, txID string) (*models.MempoolEntry, error)
 
+	// MempoolGraphFunc mocks the MempoolGraph method.
+	MempoolGraphFunc func(ctx context.Context, txID string) (*bn.MempoolGraph, error)
+
 	// MerkleProofFunc mocks the MerkleProof method.
 	MerkleProofFunc func(ctx context.Context, blockHash string, txID string, opts *models.OptsMerkleProof) (*bc.MerkleProof, error)
 
@@ -244,6 +265,9 @@ type BlockChainClientMock struct {
 	// VerifyChainFunc mocks the VerifyChain method.
 	VerifyChainFunc func(ctx context.Context) (bool, error)
 
+	// VerifyMerkleProofFunc mocks the VerifyMerkleProof method.
+	VerifyMerkleProofFunc func(ctx context.Context, proof *bc.MerkleProof, txID string) error
+
 	// calls tracks calls to the methods.
 	calls struct {
 		// BestBlockHash holds details about calls to the BestBlockHash method.
@@ -337,6 +361,13 @@ type BlockChainClientMock struct {
 			// Fields is the fields argument value.
 			Fields []string
 		}
+		// BlockStream holds details about calls to the BlockStream method.
+		BlockStream []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// Hash is the hash argument value.
+			Hash string
+		}
 		// ChainInfo holds details about calls to the ChainInfo method.
 		ChainInfo []struct {
 			// Ctx is the ctx argument value.
@@ -359,6 +390,15 @@ type BlockChainClientMock struct {
 			// Ctx is the ctx argument value.
 			Ctx context.Context
 		}
+		// CheckOutpoints holds details about calls to the CheckOutpoints method.
+		CheckOutpoints []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// Outpoints is the outpoints argument value.
+			Outpoints []models.Outpoint
+			// IncludeMempool is the includeMempool argument value.
+			IncludeMempool bool
+		}
 		// Difficulty holds details about calls to the Difficulty method.
 		Difficulty []struct {
 			// Ctx is the ctx argument value.
@@ -428,6 +468,13 @@ type BlockChainClientMock struct {
 			// TxID is the txID argument value.
 			TxID string
 		}
+		// MempoolGraph holds details about calls to the MempoolGraph method.
+		MempoolGraph []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// TxID is the txID argument value.
+			TxID string
+		}
 		// MerkleProof holds details about calls to the MerkleProof method.
 		MerkleProof []struct {
 			// Ctx is the ctx argument value.
@@ -494,6 +541,15 @@ type BlockChainClientMock struct {
 			// Ctx is the ctx argument value.
 			Ctx context.Context
 		}
+		// VerifyMerkleProof holds details about calls to the VerifyMerkleProof method.
+		VerifyMerkleProof []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// Proof is the proof argument value.
+			Proof *bc.MerkleProof
+			// TxID is the txID argument value.
+			TxID string
+		}
 	}
 	lockBestBlockHash             sync.RWMutex
 	lockBlock                     sync.RWMutex
@@ -508,10 +564,12 @@ type BlockChainClientMock struct {
 	lockBlockHexByHeight          sync.RWMutex
 	lockBlockStats                sync.RWMutex
 	lockBlockStatsByHeight        sync.RWMutex
+	lockBlockStream               sync.RWMutex
 	lockChainInfo                 sync.RWMutex
 	lockChainTips                 sync.RWMutex
 	lockChainTxStats              sync.RWMutex
 	lockCheckJournal              sync.RWMutex
+	lockCheckOutpoints            sync.RWMutex
 	lockDifficulty                sync.RWMutex
 	lockGenerate                  sync.RWMutex
 	lockGenerateToAddress         sync.RWMutex
@@ -521,6 +579,7 @@ type BlockChainClientMock struct {
 	lockMempoolDescendantIDs      sync.RWMutex
 	lockMempoolDescendants        sync.RWMutex
 	lockMempoolEntry              sync.RWMutex
+	lockMempoolGraph              sync.RWMutex
 	lockMerkleProof               sync.RWMutex
 	lockOutput                    sync.RWMutex
 	lockOutputSetInfo             sync.RWMutex
@@ -531,6 +590,7 @@ type BlockChainClientMock struct {
 	lockRawNonFinalMempool        sync.RWMutex
 	lockRebuildJournal            sync.RWMutex
 	lockVerifyChain               sync.RWMutex
+	lockVerifyMerkleProof         sync.RWMutex
 }
 
 // BestBlockHash calls BestBlockHashFunc.
@@ -988,6 +1048,41 @@ func (mock *BlockChainClientMock) BlockStatsByHeightCalls() []struct {
 	return calls
 }
 
+// BlockStream calls BlockStreamFunc.
+func (mock *BlockChainClientMock) BlockStream(ctx context.Context, hash string) *bn.BlockStream {
+	if mock.BlockStreamFunc == nil {
+		panic("BlockChainClientMock.BlockStreamFunc: method is nil but BlockChainClient.BlockStream was just called")
+	}
+	callInfo := struct {
+		Ctx  context.Context
+		Hash string
+	}{
+		Ctx:  ctx,
+		Hash: hash,
+	}
+	mock.lockBlockStream.Lock()
+	mock.calls.BlockStream = append(mock.calls.BlockStream, callInfo)
+	mock.lockBlockStream.Unlock()
+	return mock.BlockStreamFunc(ctx, hash)
+}
+
+// BlockStreamCalls gets all the calls that were made to BlockStream.
+// Check the length with:
+//     len(mockedBlockChainClient.BlockStreamCalls())
+func (mock *BlockChainClientMock) BlockStreamCalls() []struct {
+	Ctx  context.Context
+	Hash string
+} {
+	var calls []struct {
+		Ctx  context.Context
+		Hash string
+	}
+	mock.lockBlockStream.RLock()
+	calls = mock.calls.BlockStream
+	mock.lockBlockStream.RUnlock()
+	return calls
+}
+
 // ChainInfo calls ChainInfoFunc.
 func (mock *BlockChainClientMock) ChainInfo(ctx context.Context) (*models.ChainInfo, error) {
 	if mock.ChainInfoFunc == nil {
@@ -1116,6 +1211,45 @@ func (mock *BlockChainClientMock) CheckJournalCalls() []struct {
 	return calls
 }
 
+// CheckOutpoints calls CheckOutpointsFunc.
+func (mock *BlockChainClientMock) CheckOutpoints(ctx context.Context, outpoints []models.Outpoint, includeMempool bool) ([]*models.OutpointCheck, error) {
+	if mock.CheckOutpointsFunc == nil {
+		panic("BlockChainClientMock.CheckOutpointsFunc: method is nil but BlockChainClient.CheckOutpoints was just called")
+	}
+	callInfo := struct {
+		Ctx            context.Context
+		Outpoints      []models.Outpoint
+		IncludeMempool bool
+	}{
+		Ctx:            ctx,
+		Outpoints:      outpoints,
+		IncludeMempool: includeMempool,
+	}
+	mock.lockCheckOutpoints.Lock()
+	mock.calls.CheckOutpoints = append(mock.calls.CheckOutpoints, callInfo)
+	mock.lockCheckOutpoints.Unlock()
+	return mock.CheckOutpointsFunc(ctx, outpoints, includeMempool)
+}
+
+// CheckOutpointsCalls gets all the calls that were made to CheckOutpoints.
+// Check the length with:
+//     len(mockedBlockChainClient.CheckOutpointsCalls())
+func (mock *BlockChainClientMock) CheckOutpointsCalls() []struct {
+	Ctx            context.Context
+	Outpoints      []models.Outpoint
+	IncludeMempool bool
+} {
+	var calls []struct {
+		Ctx            context.Context
+		Outpoints      []models.Outpoint
+		IncludeMempool bool
+	}
+	mock.lockCheckOutpoints.RLock()
+	calls = mock.calls.CheckOutpoints
+	mock.lockCheckOutpoints.RUnlock()
+	return calls
+}
+
 // Difficulty calls DifficultyFunc.
 func (mock *BlockChainClientMock) Difficulty(ctx context.Context) (float64, error) {
 	if mock.DifficultyFunc == nil {
@@ -1443,6 +1577,41 @@ func (mock *BlockChainClientMock) MempoolEntryCalls() []struct {
 	return calls
 }
 
+// MempoolGraph calls MempoolGraphFunc.
+func (mock *BlockChainClientMock) MempoolGraph(ctx context.Context, txID string) (*bn.MempoolGraph, error) {
+	if mock.MempoolGraphFunc == nil {
+		panic("BlockChainClientMock.MempoolGraphFunc: method is nil but BlockChainClient.MempoolGraph was just called")
+	}
+	callInfo := struct {
+		Ctx  context.Context
+		TxID string
+	}{
+		Ctx:  ctx,
+		TxID: txID,
+	}
+	mock.lockMempoolGraph.Lock()
+	mock.calls.MempoolGraph = append(mock.calls.MempoolGraph, callInfo)
+	mock.lockMempoolGraph.Unlock()
+	return mock.MempoolGraphFunc(ctx, txID)
+}
+
+// MempoolGraphCalls gets all the calls that were made to MempoolGraph.
+// Check the length with:
+//     len(mockedBlockChainClient.MempoolGraphCalls())
+func (mock *BlockChainClientMock) MempoolGraphCalls() []struct {
+	Ctx  context.Context
+	TxID string
+} {
+	var calls []struct {
+		Ctx  context.Context
+		TxID string
+	}
+	mock.lockMempoolGraph.RLock()
+	calls = mock.calls.MempoolGraph
+	mock.lockMempoolGraph.RUnlock()
+	return calls
+}
+
 // MerkleProof calls MerkleProofFunc.
 func (mock *BlockChainClientMock) MerkleProof(ctx context.Context, blockHash string, txID string, opts *models.OptsMerkleProof) (*bc.MerkleProof, error) {
 	if mock.MerkleProofFunc == nil {
@@ -1784,3 +1953,42 @@ func (mock *BlockChainClientMock) VerifyChainCalls() []struct {
 	mock.lockVerifyChain.RUnlock()
 	return calls
 }
+
+// VerifyMerkleProof calls VerifyMerkleProofFunc.
+func (mock *BlockChainClientMock) VerifyMerkleProof(ctx context.Context, proof *bc.MerkleProof, txID string) error {
+	if mock.VerifyMerkleProofFunc == nil {
+		panic("BlockChainClientMock.VerifyMerkleProofFunc: method is nil but BlockChainClient.VerifyMerkleProof was just called")
+	}
+	callInfo := struct {
+		Ctx   context.Context
+		Proof *bc.MerkleProof
+		TxID  string
+	}{
+		Ctx:   ctx,
+		Proof: proof,
+		TxID:  txID,
+	}
+	mock.lockVerifyMerkleProof.Lock()
+	mock.calls.VerifyMerkleProof = append(mock.calls.VerifyMerkleProof, callInfo)
+	mock.lockVerifyMerkleProof.Unlock()
+	return mock.VerifyMerkleProofFunc(ctx, proof, txID)
+}
+
+// VerifyMerkleProofCalls gets all the calls that were made to VerifyMerkleProof.
+// Check the length with:
+//     len(mockedBlockChainClient.VerifyMerkleProofCalls())
+func (mock *BlockChainClientMock) VerifyMerkleProofCalls() []struct {
+	Ctx   context.Context
+	Proof *bc.MerkleProof
+	TxID  string
+} {
+	var calls []struct {
+		Ctx   context.Context
+		Proof *bc.MerkleProof
+		TxID  string
+	}
+	mock.lockVerifyMerkleProof.RLock()
+	calls = mock.calls.VerifyMerkleProof
+	mock.lockVerifyMerkleProof.RUnlock()
+	return calls
+}
@@ -1,6 +1,7 @@
 package mocks
 
 //go:generate moq -pkg mocks -out node_client.go ../ NodeClient
+//go:generate moq -pkg mocks -out batch_client.go ../ BatchClient
 //go:generate moq -pkg mocks -out blockchain_client.go ../ BlockChainClient
 //go:generate moq -pkg mocks -out control_client.go ../ ControlClient
 //go:generate moq -pkg mocks -out mining_client.go ../ MiningClient
@@ -8,6 +9,7 @@ package mocks
 //go:generate moq -pkg mocks -out transaction_client.go ../ TransactionClient
 //go:generate moq -pkg mocks -out util_client.go ../ UtilClient
 //go:generate moq -pkg mocks -out wallet_client.go ../ WalletClient
+//go:generate moq -pkg mocks -out node_mq.go ../zmq NodeMQ
 
 // Third party
 
@@ -52,6 +52,9 @@ var _ bn.NodeClient = &NodeClientMock{}
 // 			BalanceFunc: func(ctx context.Context, opts *models.OptsBalance) (uint64, error) {
 // 				panic("mock out the Balance method")
 // 			},
+// 			BatchFunc: func(ctx context.Context, b *bn.Batch) error {
+// 				panic("mock out the Batch method")
+// 			},
 // 			BestBlockHashFunc: func(ctx context.Context) (string, error) {
 // 				panic("mock out the BestBlockHash method")
 // 			},
@@ -91,9 +94,15 @@ var _ bn.NodeClient = &NodeClientMock{}
 // 			BlockStatsByHeightFunc: func(ctx context.Context, height int, fields ...string) (*models.BlockStats, error) {
 // 				panic("mock out the BlockStatsByHeight method")
 // 			},
+// 			BlockStreamFunc: func(ctx context.Context, hash string) *bn.BlockStream {
+// 				panic("mock out the BlockStream method")
+// 			},
 // 			BlockTemplateFunc: func(ctx context.Context, opts *models.BlockTemplateRequest) (*models.BlockTemplate, error) {
 // 				panic("mock out the BlockTemplate method")
 // 			},
+// 			CacheStatsFunc: func() models.CacheStats {
+// 				panic("mock out the CacheStats method")
+// 			},
 // 			ChainInfoFunc: func(ctx context.Context) (*models.ChainInfo, error) {
 // 				panic("mock out the ChainInfo method")
 // 			},
@@ -106,6 +115,9 @@ var _ bn.NodeClient = &NodeClientMock{}
 // 			CheckJournalFunc: func(ctx context.Context) (*models.JournalStatus, error) {
 // 				panic("mock out the CheckJournal method")
 // 			},
+// 			CheckOutpointsFunc: func(ctx context.Context, outpoints []models.Outpoint, includeMempool bool) ([]*models.OutpointCheck, error) {
+// 				panic("mock out the CheckOutpoints method")
+// 			},
 // 			ClearBannedFunc: func(ctx context.Context) error {
 // 				panic("mock out the ClearBanned method")
 // 			},
@@ -142,6 +154,9 @@ var _ bn.NodeClient = &NodeClientMock{}
 // 			ExcessiveBlockFunc: func(ctx context.Context) (*models.ExcessiveBlock, error) {
 // 				panic("mock out the ExcessiveBlock method")
 // 			},
+// 			ForWalletFunc: func(name string) bn.WalletClient {
+// 				panic("mock out the ForWallet method")
+// 			},
 // 			FundRawTransactionFunc: func(ctx context.Context, tx *bt.Tx, opts *models.OptsFundRawTransaction) (*models.FundRawTransaction, error) {
 // 				panic("mock out the FundRawTransaction method")
 // 			},
@@ -226,6 +241,9 @@ var _ bn.NodeClient = &NodeClientMock{}
 // 			MempoolEntryFunc: func(ctx context.Context, txID string) (*models.MempoolEntry, error) {
 // 				panic("mock out the MempoolEntry method")
 // 			},
+// 			MempoolGraphFunc: func(ctx context.Context, txID string) (*bn.MempoolGraph, error) {
+// 				panic("mock out the MempoolGraph method")
+// 			},
 // 			MerkleProofFunc: func(ctx context.Context, blockHash string, txID string, opts *models.OptsMerkleProof) (*bc.MerkleProof, error) {
 // 				panic("mock out the MerkleProof method")
 // 			},
@@ -373,9 +391,15 @@ var _ bn.NodeClient = &NodeClientMock{}
 // 			VerifyChainFunc: func(ctx context.Context) (bool, error) {
 // 				panic("mock out the VerifyChain method")
 // 			},
+// 			VerifyMerkleProofFunc: func(ctx context.Context, proof *bc.MerkleProof, txID string) error {
+// 				panic("mock out the VerifyMerkleProof method")
+// 			},
 // 			VerifySignedMessageFunc: func(ctx context.Context, w *wif.WIF, signature string, message string) (bool, error) {
 // 				panic("mock out the VerifySignedMessage method")
 // 			},
+// 			WaitReadyFunc: func(ctx context.Context, opts *models.OptsWaitReady) error {
+// 				panic("mock out the WaitReady method")
+// 			},
 // 			WalletInfoFunc: func(ctx context.Context) (*models.WalletInfo, error) {
 // 				panic("mock out the WalletInfo method")
 // 			},
@@ -422,6 +446,9 @@ type NodeClientMock struct {
 	// BalanceFunc mocks the Balance method.
 	BalanceFunc func(ctx context.Context, opts *models.OptsBalance) (uint64, error)
 
+	// BatchFunc mocks the Batch method.
+	BatchFunc func(ctx context.Context, b *bn.Batch) error
+
 	// BestBlockHashFunc mocks the BestBlockHash method.
 	BestBlockHashFunc func(ctx context.Context) (string, error)
 
@@ -461,9 +488,15 @@ type NodeClientMock struct {
 	// BlockStatsByHeightFunc mocks the BlockStatsByHeight method.
 	BlockStatsByHeightFunc func(ctx context.Context, height int, fields ...string) (*models.BlockStats, error)
 
+	// BlockStreamFunc mocks the BlockStream method.
+	BlockStreamFunc func(ctx context.Context, hash string) *bn.BlockStream
+
 	// BlockTemplateFunc mocks the BlockTemplate method.
 	BlockTemplateFunc func(ctx context.Context, opts *models.BlockTemplateRequest) (*models.BlockTemplate, error)
 
+	// CacheStatsFunc mocks the CacheStats method.
+	CacheStatsFunc func() models.CacheStats
+
 	// ChainInfoFunc mocks the ChainInfo method.
 	ChainInfoFunc func(ctx context.Context) (*models.ChainInfo, error)
 
@@ -476,6 +509,9 @@ type NodeClientMock struct {
 	// CheckJournalFunc mocks the CheckJournal method.
 	CheckJournalFunc func(ctx context.Context) (*models.JournalStatus, error)
 
+	// CheckOutpointsFunc mocks the CheckOutpoints method.
+	CheckOutpointsFunc func(ctx context.Context, outpoints []models.Outpoint, includeMempool bool) ([]*models.OutpointCheck, error)
+
 	// ClearBannedFunc mocks the ClearBanned method.
 	ClearBannedFunc func(ctx context.Context) error
 
@@ -512,6 +548,9 @@ type NodeClientMock struct {
 	// ExcessiveBlockFunc mocks the ExcessiveBlock method.
 	ExcessiveBlockFunc func(ctx context.Context) (*models.ExcessiveBlock, error)
 
+	// ForWalletFunc mocks the ForWallet method.
+	ForWalletFunc func(name string) bn.WalletClient
+
 	// FundRawTransactionFunc mocks the FundRawTransaction method.
 	FundRawTransactionFunc func(ctx context.Context, tx *bt.Tx, opts *models.OptsFundRawTransaction) (*models.FundRawTransaction, error)
 
@@ -596,6 +635,9 @@ type NodeClientMock struct {
 	// MempoolEntryFunc mocks the MempoolEntry method.
 	MempoolEntryFunc func(ctx context.Context, txID string) (*models.MempoolEntry, error)
 
+	// MempoolGraphFunc mocks the MempoolGraph method.
+	MempoolGraphFunc func(ctx context.Context, txID string) (*bn.MempoolGraph, error)
+
 	// MerkleProofFunc mocks the MerkleProof method.
 	MerkleProofFunc func(ctx context.Context, blockHash string, txID string, opts *models.OptsMerkleProof) (*bc.MerkleProof, error)
 
@@ -743,9 +785,15 @@ type NodeClientMock struct {
 	// VerifyChainFunc mocks the VerifyChain method.
 	VerifyChainFunc func(ctx context.Context) (bool, error)
 
+	// VerifyMerkleProofFunc mocks the VerifyMerkleProof method.
+	VerifyMerkleProofFunc func(ctx context.Context, proof *bc.MerkleProof, txID string) error
+
 	// VerifySignedMessageFunc mocks the VerifySignedMessage method.
 	VerifySignedMessageFunc func(ctx context.Context, w *wif.WIF, signature string, message string) (bool, error)
 
+	// WaitReadyFunc mocks the WaitReady method.
+	WaitReadyFunc func(ctx context.Context, opts *models.OptsWaitReady) error
+
 	// WalletInfoFunc mocks the WalletInfo method.
 	WalletInfoFunc func(ctx context.Context) (*models.WalletInfo, error)
 
@@ -825,6 +873,13 @@ type NodeClientMock struct {
 			// Opts is the opts argument value.
 			Opts *models.OptsBalance
 		}
+		// Batch holds details about calls to the Batch method.
+		Batch []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// B is the b argument value.
+			B *bn.Batch
+		}
 		// BestBlockHash holds details about calls to the BestBlockHash method.
 		BestBlockHash []struct {
 			// Ctx is the ctx argument value.
@@ -916,6 +971,13 @@ type NodeClientMock struct {
 			// Fields is the fields argument value.
 			Fields []string
 		}
+		// BlockStream holds details about calls to the BlockStream method.
+		BlockStream []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// Hash is the hash argument value.
+			Hash string
+		}
 		// BlockTemplate holds details about calls to the BlockTemplate method.
 		BlockTemplate []struct {
 			// Ctx is the ctx argument value.
@@ -923,6 +985,9 @@ type NodeClientMock struct {
 			// Opts is the opts argument value.
 			Opts *models.BlockTemplateRequest
 		}
+		// CacheStats holds details about calls to the CacheStats method.
+		CacheStats []struct {
+		}
 		// ChainInfo holds details about calls to the ChainInfo method.
 		ChainInfo []struct {
 			// Ctx is the ctx argument value.
@@ -945,6 +1010,15 @@ type NodeClientMock struct {
 			// Ctx is the ctx argument value.
 			Ctx context.Context
 		}
+		// CheckOutpoints holds details about calls to the CheckOutpoints method.
+		CheckOutpoints []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// Outpoints is the outpoints argument value.
+			Outpoints []models.Outpoint
+			// IncludeMempool is the includeMempool argument value.
+			IncludeMempool bool
+		}
 		// ClearBanned holds details about calls to the ClearBanned method.
 		ClearBanned []struct {
 			// Ctx is the ctx argument value.
@@ -1021,6 +1095,11 @@ type NodeClientMock struct {
 			// Ctx is the ctx argument value.
 			Ctx context.Context
 		}
+		// ForWallet holds details about calls to the ForWallet method.
+		ForWallet []struct {
+			// Name is the name argument value.
+			Name string
+		}
 		// FundRawTransaction holds details about calls to the FundRawTransaction method.
 		FundRawTransaction []struct {
 			// Ctx is the ctx argument value.
@@ -1229,6 +1308,13 @@ type NodeClientMock struct {
 			// TxID is the txID argument value.
 			TxID string
 		}
+		// MempoolGraph holds details about calls to the MempoolGraph method.
+		MempoolGraph []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// TxID is the txID argument value.
+			TxID string
+		}
 		// MerkleProof holds details about calls to the MerkleProof method.
 		MerkleProof []struct {
 			// Ctx is the ctx argument value.
@@ -1588,6 +1674,15 @@ type NodeClientMock struct {
 			// Ctx is the ctx argument value.
 			Ctx context.Context
 		}
+		// VerifyMerkleProof holds details about calls to the VerifyMerkleProof method.
+		VerifyMerkleProof []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// Proof is the proof argument value.
+			Proof *bc.MerkleProof
+			// TxID is the txID argument value.
+			TxID string
+		}
 		// VerifySignedMessage holds details about calls to the VerifySignedMessage method.
 		VerifySignedMessage []struct {
 			// Ctx is the ctx argument value.
@@ -1599,6 +1694,13 @@ type NodeClientMock struct {
 			// Message is the message argument value.
 			Message string
 		}
+		// WaitReady holds details about calls to the WaitReady method.
+		WaitReady []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// Opts is the opts argument value.
+			Opts *models.OptsWaitReady
+		}
 		// WalletInfo holds details about calls to the WalletInfo method.
 		WalletInfo []struct {
 			// Ctx is the ctx argument value.
@@ -1637,6 +1739,7 @@ type NodeClientMock struct {
 	lockAddNode                   sync.RWMutex
 	lockBackupWallet              sync.RWMutex
 	lockBalance                   sync.RWMutex
+	lockBatch                     sync.RWMutex
 	lockBestBlockHash             sync.RWMutex
 	lockBlock                     sync.RWMutex
 	lockBlockByHeight             sync.RWMutex
@@ -1650,11 +1753,14 @@ type NodeClientMock struct {
 	lockBlockHexByHeight          sync.RWMutex
 	lockBlockStats                sync.RWMutex
 	lockBlockStatsByHeight        sync.RWMutex
+	lockBlockStream               sync.RWMutex
 	lockBlockTemplate             sync.RWMutex
+	lockCacheStats                sync.RWMutex
 	lockChainInfo                 sync.RWMutex
 	lockChainTips                 sync.RWMutex
 	lockChainTxStats              sync.RWMutex
 	lockCheckJournal              sync.RWMutex
+	lockCheckOutpoints            sync.RWMutex
 	lockClearBanned               sync.RWMutex
 	lockClearInvalidTransactions  sync.RWMutex
 	lockConnectionCount           sync.RWMutex
@@ -1667,6 +1773,7 @@ type NodeClientMock struct {
 	lockDumpWallet                sync.RWMutex
 	lockEncryptWallet             sync.RWMutex
 	lockExcessiveBlock            sync.RWMutex
+	lockForWallet                 sync.RWMutex
 	lockFundRawTransaction        sync.RWMutex
 	lockGenerate                  sync.RWMutex
 	lockGenerateToAddress         sync.RWMutex
@@ -1695,6 +1802,7 @@ type NodeClientMock struct {
 	lockMempoolDescendantIDs      sync.RWMutex
 	lockMempoolDescendants        sync.RWMutex
 	lockMempoolEntry              sync.RWMutex
+	lockMempoolGraph              sync.RWMutex
 	lockMerkleProof               sync.RWMutex
 	lockMiningCandidate           sync.RWMutex
 	lockMiningInfo                sync.RWMutex
@@ -1744,7 +1852,9 @@ type NodeClientMock struct {
 	lockValidateAddress           sync.RWMutex
 	lockVerifyBlockCandidate      sync.RWMutex
 	lockVerifyChain               sync.RWMutex
+	lockVerifyMerkleProof         sync.RWMutex
 	lockVerifySignedMessage       sync.RWMutex
+	lockWaitReady                 sync.RWMutex
 	lockWalletInfo                sync.RWMutex
 	lockWalletLock                sync.RWMutex
 	lockWalletPhassphrase         sync.RWMutex
@@ -2070,6 +2180,41 @@ func (mock *NodeClientMock) BalanceCalls() []struct {
 	return calls
 }
 
+// Batch calls BatchFunc.
+func (mock *NodeClientMock) Batch(ctx context.Context, b *bn.Batch) error {
+	if mock.BatchFunc == nil {
+		panic("NodeClientMock.BatchFunc: method is nil but NodeClient.Batch was just called")
+	}
+	callInfo := struct {
+		Ctx context.Context
+		B   *bn.Batch
+	}{
+		Ctx: ctx,
+		B:   b,
+	}
+	mock.lockBatch.Lock()
+	mock.calls.Batch = append(mock.calls.Batch, callInfo)
+	mock.lockBatch.Unlock()
+	return mock.BatchFunc(ctx, b)
+}
+
+// BatchCalls gets all the calls that were made to Batch.
+// Check the length with:
+//     len(mockedNodeClient.BatchCalls())
+func (mock *NodeClientMock) BatchCalls() []struct {
+	Ctx context.Context
+	B   *bn.Batch
+} {
+	var calls []struct {
+		Ctx context.Context
+		B   *bn.Batch
+	}
+	mock.lockBatch.RLock()
+	calls = mock.calls.Batch
+	mock.lockBatch.RUnlock()
+	return calls
+}
+
 // BestBlockHash calls BestBlockHashFunc.
 func (mock *NodeClientMock) BestBlockHash(ctx context.Context) (string, error) {
 	if mock.BestBlockHashFunc == nil {
@@ -2525,6 +2670,41 @@ func (mock *NodeClientMock) BlockStatsByHeightCalls() []struct {
 	return calls
 }
 
+// BlockStream calls BlockStreamFunc.
+func (mock *NodeClientMock) BlockStream(ctx context.Context, hash string) *bn.BlockStream {
+	if mock.BlockStreamFunc == nil {
+		panic("NodeClientMock.BlockStreamFunc: method is nil but NodeClient.BlockStream was just called")
+	}
+	callInfo := struct {
+		Ctx  context.Context
+		Hash string
+	}{
+		Ctx:  ctx,
+		Hash: hash,
+	}
+	mock.lockBlockStream.Lock()
+	mock.calls.BlockStream = append(mock.calls.BlockStream, callInfo)
+	mock.lockBlockStream.Unlock()
+	return mock.BlockStreamFunc(ctx, hash)
+}
+
+// BlockStreamCalls gets all the calls that were made to BlockStream.
+// Check the length with:
+//     len(mockedNodeClient.BlockStreamCalls())
+func (mock *NodeClientMock) BlockStreamCalls() []struct {
+	Ctx  context.Context
+	Hash string
+} {
+	var calls []struct {
+		Ctx  context.Context
+		Hash string
+	}
+	mock.lockBlockStream.RLock()
+	calls = mock.calls.BlockStream
+	mock.lockBlockStream.RUnlock()
+	return calls
+}
+
 // BlockTemplate calls BlockTemplateFunc.
 func (mock *NodeClientMock) BlockTemplate(ctx context.Context, opts *models.BlockTemplateRequest) (*models.BlockTemplate, error) {
 	if mock.BlockTemplateFunc == nil {
@@ -2560,6 +2740,32 @@ func (mock *NodeClientMock) BlockTemplateCalls() []struct {
 	return calls
 }
 
+// CacheStats calls CacheStatsFunc.
+func (mock *NodeClientMock) CacheStats() models.CacheStats {
+	if mock.CacheStatsFunc == nil {
+		panic("NodeClientMock.CacheStatsFunc: method is nil but NodeClient.CacheStats was just called")
+	}
+	callInfo := struct {
+	}{}
+	mock.lockCacheStats.Lock()
+	mock.calls.CacheStats = append(mock.calls.CacheStats, callInfo)
+	mock.lockCacheStats.Unlock()
+	return mock.CacheStatsFunc()
+}
+
+// CacheStatsCalls gets all the calls that were made to CacheStats.
+// Check the length with:
+//     len(mockedNodeClient.CacheStatsCalls())
+func (mock *NodeClientMock) CacheStatsCalls() []struct {
+} {
+	var calls []struct {
+	}
+	mock.lockCacheStats.RLock()
+	calls = mock.calls.CacheStats
+	mock.lockCacheStats.RUnlock()
+	return calls
+}
+
 // ChainInfo calls ChainInfoFunc.
 func (mock *NodeClientMock) ChainInfo(ctx context.Context) (*models.ChainInfo, error) {
 	if mock.ChainInfoFunc == nil {
@@ -2688,6 +2894,45 @@ func (mock *NodeClientMock) CheckJournalCalls() []struct {
 	return calls
 }
 
+// CheckOutpoints calls CheckOutpointsFunc.
+func (mock *NodeClientMock) CheckOutpoints(ctx context.Context, outpoints []models.Outpoint, includeMempool bool) ([]*models.OutpointCheck, error) {
+	if mock.CheckOutpointsFunc == nil {
+		panic("NodeClientMock.CheckOutpointsFunc: method is nil but NodeClient.CheckOutpoints was just called")
+	}
+	callInfo := struct {
+		Ctx            context.Context
+		Outpoints      []models.Outpoint
+		IncludeMempool bool
+	}{
+		Ctx:            ctx,
+		Outpoints:      outpoints,
+		IncludeMempool: includeMempool,
+	}
+	mock.lockCheckOutpoints.Lock()
+	mock.calls.CheckOutpoints = append(mock.calls.CheckOutpoints, callInfo)
+	mock.lockCheckOutpoints.Unlock()
+	return mock.CheckOutpointsFunc(ctx, outpoints, includeMempool)
+}
+
+// CheckOutpointsCalls gets all the calls that were made to CheckOutpoints.
+// Check the length with:
+//     len(mockedNodeClient.CheckOutpointsCalls())
+func (mock *NodeClientMock) CheckOutpointsCalls() []struct {
+	Ctx            context.Context
+	Outpoints      []models.Outpoint
+	IncludeMempool bool
+} {
+	var calls []struct {
+		Ctx            context.Context
+		Outpoints      []models.Outpoint
+		IncludeMempool bool
+	}
+	mock.lockCheckOutpoints.RLock()
+	calls = mock.calls.CheckOutpoints
+	mock.lockCheckOutpoints.RUnlock()
+	return calls
+}
+
 // ClearBanned calls ClearBannedFunc.
 func (mock *NodeClientMock) ClearBanned(ctx context.Context) error {
 	if mock.ClearBannedFunc == nil {
@@ -3092,6 +3337,37 @@ func (mock *NodeClientMock) ExcessiveBlockCalls() []struct {
 	return calls
 }
 
+// ForWallet calls ForWalletFunc.
+func (mock *NodeClientMock) ForWallet(name string) bn.WalletClient {
+	if mock.ForWalletFunc == nil {
+		panic("NodeClientMock.ForWalletFunc: method is nil but NodeClient.ForWallet was just called")
+	}
+	callInfo := struct {
+		Name string
+	}{
+		Name: name,
+	}
+	mock.lockForWallet.Lock()
+	mock.calls.ForWallet = append(mock.calls.ForWallet, callInfo)
+	mock.lockForWallet.Unlock()
+	return mock.ForWalletFunc(name)
+}
+
+// ForWalletCalls gets all the calls that were made to ForWallet.
+// Check the length with:
+//     len(mockedNodeClient.ForWalletCalls())
+func (mock *NodeClientMock) ForWalletCalls() []struct {
+	Name string
+} {
+	var calls []struct {
+		Name string
+	}
+	mock.lockForWallet.RLock()
+	calls = mock.calls.ForWallet
+	mock.lockForWallet.RUnlock()
+	return calls
+}
+
 // FundRawTransaction calls FundRawTransactionFunc.
 func (mock *NodeClientMock) FundRawTransaction(ctx context.Context, tx *bt.Tx, opts *models.OptsFundRawTransaction) (*models.FundRawTransaction, error) {
 	if mock.FundRawTransactionFunc == nil {
@@ -4096,6 +4372,41 @@ func (mock *NodeClientMock) MempoolEntryCalls() []struct {
 	return calls
 }
 
+// MempoolGraph calls MempoolGraphFunc.
+func (mock *NodeClientMock) MempoolGraph(ctx context.Context, txID string) (*bn.MempoolGraph, error) {
+	if mock.MempoolGraphFunc == nil {
+		panic("NodeClientMock.MempoolGraphFunc: method is nil but NodeClient.MempoolGraph was just called")
+	}
+	callInfo := struct {
+		Ctx  context.Context
+		TxID string
+	}{
+		Ctx:  ctx,
+		TxID: txID,
+	}
+	mock.lockMempoolGraph.Lock()
+	mock.calls.MempoolGraph = append(mock.calls.MempoolGraph, callInfo)
+	mock.lockMempoolGraph.Unlock()
+	return mock.MempoolGraphFunc(ctx, txID)
+}
+
+// MempoolGraphCalls gets all the calls that were made to MempoolGraph.
+// Check the length with:
+//     len(mockedNodeClient.MempoolGraphCalls())
+func (mock *NodeClientMock) MempoolGraphCalls() []struct {
+	Ctx  context.Context
+	TxID string
+} {
+	var calls []struct {
+		Ctx  context.Context
+		TxID string
+	}
+	mock.lockMempoolGraph.RLock()
+	calls = mock.calls.MempoolGraph
+	mock.lockMempoolGraph.RUnlock()
+	return calls
+}
+
 // MerkleProof calls MerkleProofFunc.
 func (mock *NodeClientMock) MerkleProof(ctx context.Context, blockHash string, txID string, opts *models.OptsMerkleProof) (*bc.MerkleProof, error) {
 	if mock.MerkleProofFunc == nil {
@@ -5843,6 +6154,45 @@ func (mock *NodeClientMock) VerifyChainCalls() []struct {
 	return calls
 }
 
+// VerifyMerkleProof calls VerifyMerkleProofFunc.
+func (mock *NodeClientMock) VerifyMerkleProof(ctx context.Context, proof *bc.MerkleProof, txID string) error {
+	if mock.VerifyMerkleProofFunc == nil {
+		panic("NodeClientMock.VerifyMerkleProofFunc: method is nil but NodeClient.VerifyMerkleProof was just called")
+	}
+	callInfo := struct {
+		Ctx   context.Context
+		Proof *bc.MerkleProof
+		TxID  string
+	}{
+		Ctx:   ctx,
+		Proof: proof,
+		TxID:  txID,
+	}
+	mock.lockVerifyMerkleProof.Lock()
+	mock.calls.VerifyMerkleProof = append(mock.calls.VerifyMerkleProof, callInfo)
+	mock.lockVerifyMerkleProof.Unlock()
+	return mock.VerifyMerkleProofFunc(ctx, proof, txID)
+}
+
+// VerifyMerkleProofCalls gets all the calls that were made to VerifyMerkleProof.
+// Check the length with:
+//     len(mockedNodeClient.VerifyMerkleProofCalls())
+func (mock *NodeClientMock) VerifyMerkleProofCalls() []struct {
+	Ctx   context.Context
+	Proof *bc.MerkleProof
+	TxID  string
+} {
+	var calls []struct {
+		Ctx   context.Context
+		Proof *bc.MerkleProof
+		TxID  string
+	}
+	mock.lockVerifyMerkleProof.RLock()
+	calls = mock.calls.VerifyMerkleProof
+	mock.lockVerifyMerkleProof.RUnlock()
+	return calls
+}
+
 // VerifySignedMessage calls VerifySignedMessageFunc.
 func (mock *NodeClientMock) VerifySignedMessage(ctx context.Context, w *wif.WIF, signature string, message string) (bool, error) {
 	if mock.VerifySignedMessageFunc == nil {
@@ -5886,6 +6236,41 @@ func (mock *NodeClientMock) VerifySignedMessageCalls() []struct {
 	return calls
 }
 
+// WaitReady calls WaitReadyFunc.
+func (mock *NodeClientMock) WaitReady(ctx context.Context, opts *models.OptsWaitReady) error {
+	if mock.WaitReadyFunc == nil {
+		panic("NodeClientMock.WaitReadyFunc: method is nil but NodeClient.WaitReady was just called")
+	}
+	callInfo := struct {
+		Ctx  context.Context
+		Opts *models.OptsWaitReady
+	}{
+		Ctx:  ctx,
+		Opts: opts,
+	}
+	mock.lockWaitReady.Lock()
+	mock.calls.WaitReady = append(mock.calls.WaitReady, callInfo)
+	mock.lockWaitReady.Unlock()
+	return mock.WaitReadyFunc(ctx, opts)
+}
+
+// WaitReadyCalls gets all the calls that were made to WaitReady.
+// Check the length with:
+//     len(mockedNodeClient.WaitReadyCalls())
+func (mock *NodeClientMock) WaitReadyCalls() []struct {
+	Ctx  context.Context
+	Opts *models.OptsWaitReady
+} {
+	var calls []struct {
+		Ctx  context.Context
+		Opts *models.OptsWaitReady
+	}
+	mock.lockWaitReady.RLock()
+	calls = mock.calls.WaitReady
+	mock.lockWaitReady.RUnlock()
+	return calls
+}
+
 // WalletInfo calls WalletInfoFunc.
 func (mock *NodeClientMock) WalletInfo(ctx context.Context) (*models.WalletInfo, error) {
 	if mock.WalletInfoFunc == nil {
@@ -52,6 +52,9 @@ var _ bn.WalletClient = &WalletClientMock{}
 // 			EncryptWalletFunc: func(ctx context.Context, passphrase string) error {
 // 				panic("mock out the EncryptWallet method")
 // 			},
+// 			ForWalletFunc: func(name string) bn.WalletClient {
+// 				panic("mock out the ForWallet method")
+// 			},
 // 			ImportAddressFunc: func(ctx context.Context, address string, opts *models.OptsImportAddress) error {
 // 				panic("mock out the ImportAddress method")
 // 			},
@@ -188,6 +191,9 @@ type WalletClientMock struct {
 	// EncryptWalletFunc mocks the EncryptWallet method.
 	EncryptWalletFunc func(ctx context.Context, passphrase string) error
 
+	// ForWalletFunc mocks the ForWallet method.
+	ForWalletFunc func(name string) bn.WalletClient
+
 	// ImportAddressFunc mocks the ImportAddress method.
 	ImportAddressFunc func(ctx context.Context, address string, opts *models.OptsImportAddress) error
 
@@ -361,6 +367,11 @@ type WalletClientMock struct {
 			// Passphrase is the passphrase argument value.
 			Passphrase string
 		}
+		// ForWallet holds details about calls to the ForWallet method.
+		ForWallet []struct {
+			// Name is the name argument value.
+			Name string
+		}
 		// ImportAddress holds details about calls to the ImportAddress method.
 		ImportAddress []struct {
 			// Ctx is the ctx argument value.
@@ -631,6 +642,7 @@ type WalletClientMock struct {
 	lockDumpPrivateKey          sync.RWMutex
 	lockDumpWallet              sync.RWMutex
 	lockEncryptWallet           sync.RWMutex
+	lockForWallet               sync.RWMutex
 	lockImportAddress           sync.RWMutex
 	lockImportMulti             sync.RWMutex
 	lockImportPrivateKey        sync.RWMutex
@@ -1020,6 +1032,37 @@ func (mock *WalletClientMock) EncryptWalletCalls() []struct {
 	return calls
 }
 
+// ForWallet calls ForWalletFunc.
+func (mock *WalletClientMock) ForWallet(name string) bn.WalletClient {
+	if mock.ForWalletFunc == nil {
+		panic("WalletClientMock.ForWalletFunc: method is nil but WalletClient.ForWallet was just called")
+	}
+	callInfo := struct {
+		Name string
+	}{
+		Name: name,
+	}
+	mock.lockForWallet.Lock()
+	mock.calls.ForWallet = append(mock.calls.ForWallet, callInfo)
+	mock.lockForWallet.Unlock()
+	return mock.ForWalletFunc(name)
+}
+
+// ForWalletCalls gets all the calls that were made to ForWallet.
+// Check the length with:
+//     len(mockedWalletClient.ForWalletCalls())
+func (mock *WalletClientMock) ForWalletCalls() []struct {
+	Name string
+} {
+	var calls []struct {
+		Name string
+	}
+	mock.lockForWallet.RLock()
+	calls = mock.calls.ForWallet
+	mock.lockForWallet.RUnlock()
+	return calls
+}
+
 // ImportAddress calls ImportAddressFunc.
 func (mock *WalletClientMock) ImportAddress(ctx context.Context, address string, opts *models.OptsImportAddress) error {
 	if mock.ImportAddressFunc == nil {
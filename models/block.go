@@ -54,7 +54,7 @@ func (b *Block) UnmarshalJSON(bb []byte) error {
 type BlockHeader struct {
 	*bc.BlockHeader
 	Hash          string `json:"hash"`
-	Confirmations uint64 `json:"confirmations"`
+	Confirmations int64  `json:"confirmations"`
 	Height        uint64 `json:"height"`
 	//Version           uint64  `json:"version"`
 	VersionHex string `json:"versionHex"`
@@ -73,7 +73,7 @@ type BlockHeader struct {
 func (b *BlockHeader) UnmarshalJSON(bb []byte) error {
 	bh := struct {
 		Hash              string  `json:"hash"`
-		Confirmations     uint64  `json:"confirmations"`
+		Confirmations     int64   `json:"confirmations"`
 		Height            uint64  `json:"height"`
 		VersionHex        string  `json:"versionHex"`
 		NumTx             uint64  `json:"num_tx"`
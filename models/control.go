@@ -1,5 +1,7 @@
 package models
 
+import "time"
+
 // ZMQNotification model.
 type ZMQNotification struct {
 	Notification string `json:"notification"`
@@ -79,3 +81,27 @@ type Settings struct {
 	MinConsolidationInputMaturity   uint32  `json:"minconsolidationinputmaturity"`
 	AcceptNonStdConsolidationInput  bool    `json:"acceptnonstdconsolidationinput"`
 }
+
+// OptsWaitReady options.
+type OptsWaitReady struct {
+	// PollInterval how often the node is polled. Defaults to a second.
+	PollInterval time.Duration
+	// MinVerificationProgress the verification progress the node must reach, between
+	// 0 and 1. Defaults to 0.9999.
+	MinVerificationProgress float64
+	// MinConnections the number of peers the node must be connected to.
+	MinConnections uint32
+	// OnProgress is called with the node's progress after each poll.
+	OnProgress func(p *ReadyProgress)
+}
+
+// ReadyProgress model.
+type ReadyProgress struct {
+	Ready                bool
+	Blocks               uint32
+	Headers              uint32
+	VerificationProgress float64
+	Connections          uint32
+	// Err why the node could not be polled, such as it warming up or being unreachable.
+	Err error
+}
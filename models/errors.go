@@ -0,0 +1,123 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+// Error an error returned by the node in response to an rpc call.
+//
+// Compare against the sentinel errors below with errors.Is, which matches on code:
+//
+//	if errors.Is(err, models.ErrNotFound) {}
+//
+// or extract the code and message with errors.As:
+//
+//	var nodeErr *models.Error
+//	if errors.As(err, &nodeErr) {}
+type Error struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+func (e Error) Error() string {
+	return fmt.Sprintf("%d: %s", e.Code, e.Message)
+}
+
+// Is reports whether target is a node error of the same code.
+func (e Error) Is(target error) bool {
+	switch t := target.(type) {
+	case *Error:
+		return t != nil && t.Code == e.Code
+	case Error:
+		return t.Code == e.Code
+	}
+	return false
+}
+
+// Standard JSON-RPC errors.
+var (
+	ErrInvalidRequest = &Error{Code: -32600, Message: "invalid request"}
+	ErrMethodNotFound = &Error{Code: -32601, Message: "method not found"}
+	ErrInvalidParams  = &Error{Code: -32602, Message: "invalid params"}
+	ErrInternal       = &Error{Code: -32603, Message: "internal error"}
+	ErrParse          = &Error{Code: -32700, Message: "parse error"}
+)
+
+// General application errors.
+var (
+	ErrMisc              = &Error{Code: -1, Message: "misc error"}
+	ErrForbiddenSafeMode = &Error{Code: -2, Message: "forbidden by safe mode"}
+	ErrType              = &Error{Code: -3, Message: "unexpected type"}
+	ErrNotFound          = &Error{Code: -5, Message: "not found"}
+	ErrOutOfMemory       = &Error{Code: -7, Message: "out of memory"}
+	ErrInvalidParameter  = &Error{Code: -8, Message: "invalid parameter"}
+	ErrDatabase          = &Error{Code: -20, Message: "database error"}
+	ErrDeserialization   = &Error{Code: -22, Message: "deserialization error"}
+	ErrMissingInputs     = &Error{Code: -25, Message: "missing inputs"}
+	ErrRejected          = &Error{Code: -26, Message: "transaction rejected"}
+	ErrAlreadyInChain    = &Error{Code: -27, Message: "transaction already in chain"}
+	ErrWarmingUp         = &Error{Code: -28, Message: "node warming up"}
+)
+
+// P2P client errors.
+var (
+	ErrClientNotConnected      = &Error{Code: -9, Message: "not connected"}
+	ErrClientInInitialDownload = &Error{Code: -10, Message: "in initial download"}
+	ErrNodeAlreadyAdded        = &Error{Code: -23, Message: "node already added"}
+	ErrNodeNotAdded            = &Error{Code: -24, Message: "node not added"}
+	ErrNodeNotConnected        = &Error{Code: -29, Message: "node not connected"}
+	ErrInvalidIPOrSubnet       = &Error{Code: -30, Message: "invalid ip or subnet"}
+	ErrP2PDisabled             = &Error{Code: -31, Message: "p2p disabled"}
+)
+
+// Wallet errors.
+var (
+	ErrWallet                    = &Error{Code: -4, Message: "wallet error"}
+	ErrInsufficientFunds         = &Error{Code: -6, Message: "insufficient funds"}
+	ErrInvalidAccountName        = &Error{Code: -11, Message: "invalid account name"}
+	ErrKeypoolRanOut             = &Error{Code: -12, Message: "keypool ran out"}
+	ErrWalletLocked              = &Error{Code: -13, Message: "wallet unlock needed"}
+	ErrWalletPassphraseIncorrect = &Error{Code: -14, Message: "wallet passphrase incorrect"}
+	ErrWalletWrongEncState       = &Error{Code: -15, Message: "wallet wrong encryption state"}
+	ErrWalletEncryptionFailed    = &Error{Code: -16, Message: "wallet encryption failed"}
+	ErrWalletAlreadyUnlocked     = &Error{Code: -17, Message: "wallet already unlocked"}
+)
+
+// Client errors, raised by the client rather than returned by the node.
+var (
+	// ErrBatchResponseMissing error when the node does not respond to a call within a batch.
+	ErrBatchResponseMissing = errors.New("no response received for batch call")
+	// ErrInvalidCookie error when the cookie file is not of the form `user:password`.
+	ErrInvalidCookie = errors.New("invalid rpc cookie file")
+)
+
+// TransportError error when the rpc request could not be sent or its response read,
+// such as the node being unreachable or closing the connection.
+type TransportError struct {
+	Err error
+}
+
+func (t *TransportError) Error() string {
+	return fmt.Sprintf("failed to perform rpc query: %s", t.Err)
+}
+
+// Unwrap the underlying network error.
+func (t *TransportError) Unwrap() error {
+	return t.Err
+}
+
+// StatusError error when the node responds with an unsuccessful http status and
+// no JSON-RPC body, such as a 401 for bad credentials or a 503 when the work queue is full.
+type StatusError struct {
+	StatusCode int    `json:"code"`
+	Body       string `json:"body,omitempty"`
+}
+
+func (s *StatusError) Error() string {
+	if s.Body == "" {
+		return fmt.Sprintf("rpc http status %d %s", s.StatusCode, http.StatusText(s.StatusCode))
+	}
+	return fmt.Sprintf("rpc http status %d %s: %s", s.StatusCode, http.StatusText(s.StatusCode), s.Body)
+}
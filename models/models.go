@@ -1,9 +1,5 @@
 package models
 
-import (
-	"fmt"
-)
-
 type blockVerbosity string
 
 // Block verbosity levels.
@@ -23,6 +19,15 @@ const (
 	MerkleProofTargetTypeMerkleRoot merkleProofTargetType = "merkleroot"
 )
 
+type outpointStatus string
+
+// Outpoint statuses.
+const (
+	OutpointStatusUnspent outpointStatus = "unspent"
+	OutpointStatusSpent   outpointStatus = "spent"
+	OutpointStatusUnknown outpointStatus = "unknown"
+)
+
 // Request model.
 type Request struct {
 	ID      string        `json:"id"`
@@ -37,16 +42,6 @@ type Response struct {
 	Error  *Error      `json:"error"`
 }
 
-// Error model.
-type Error struct {
-	Code    int    `json:"code"`
-	Message string `json:"message"`
-}
-
-func (e Error) Error() string {
-	return fmt.Sprintf("%d: %s", e.Code, e.Message)
-}
-
 // OptsChainTxStats options.
 type OptsChainTxStats struct {
 	NumBlocks uint32
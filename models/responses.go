@@ -1,5 +1,7 @@
 package models
 
+import "github.com/libsv/go-bc"
+
 // ChainInfo model.
 type ChainInfo struct {
 	Chain                string  `json:"chain"`
@@ -8,7 +10,7 @@ type ChainInfo struct {
 	BestBlockHash        string  `json:"bestblockhash"`
 	Difficulty           float64 `json:"difficulty"`
 	MedianTime           uint32  `json:"mediantime"`
-	VerificationProgress uint8   `json:"verificationprogress"`
+	VerificationProgress float64 `json:"verificationprogress"`
 	Chainwork            string  `json:"chainwork"`
 	Pruned               bool    `json:"pruned"`
 	SoftForks            []struct {
@@ -75,6 +77,22 @@ type LegacyMerkleProof struct {
 	Nodes  []string    `json:"nodes"`
 }
 
+// legacyMerkleProofComposite the flag set on composite legacy merkle proofs.
+const legacyMerkleProofComposite = 1 << 4
+
+// MerkleProof convert the proof to the TSC form returned by getmerkleproof2, targeting
+// the hash of the block it was made for.
+func (p *LegacyMerkleProof) MerkleProof() *bc.MerkleProof {
+	return &bc.MerkleProof{
+		Index:      uint64(p.Index),
+		TxOrID:     p.TxOrID,
+		Target:     p.Target.Hash,
+		Nodes:      p.Nodes,
+		TargetType: string(MerkleProofTargetTypeHash),
+		Composite:  p.Flags&legacyMerkleProofComposite != 0,
+	}
+}
+
 // MempoolEntry model.
 type MempoolEntry struct {
 	Size        uint32   `json:"size"`
@@ -93,3 +111,32 @@ type JournalStatus struct {
 	Ok     bool    `json:"ok"`
 	Errors *string `json:"errors"`
 }
+
+// CacheStats model.
+type CacheStats struct {
+	Hits      uint64
+	Misses    uint64
+	Evictions uint64
+	Entries   int
+}
+
+// MempoolStats model.
+type MempoolStats struct {
+	Count int
+	// Size the total size of the txs, in bytes.
+	Size uint64
+	// Fees the total fees paid by the txs, in satoshis.
+	Fees uint64
+}
+
+// MempoolPackage model, a tx in the mempool and its unconfirmed ancestors.
+type MempoolPackage struct {
+	// TxIDs the txids of the ancestors, sorted, followed by that of the tx.
+	TxIDs []string
+	// Size the total size of the txs, in bytes.
+	Size uint64
+	// Fees the total fees paid by the txs, in satoshis.
+	Fees uint64
+	// FeeRate the fee rate of the package, in satoshis per byte.
+	FeeRate float64
+}
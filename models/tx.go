@@ -3,6 +3,7 @@ package models
 import (
 	"encoding/json"
 
+	"github.com/libsv/go-bc"
 	"github.com/libsv/go-bn/internal/util"
 	"github.com/libsv/go-bt/v2"
 	"github.com/libsv/go-bt/v2/bscript"
@@ -23,8 +24,13 @@ func (o *Output) NodeJSON() interface{} {
 	return o
 }
 
-// UnmarshalJSON unmarshal response.
+// UnmarshalJSON unmarshal response. A spent or unknown output, given as null, is
+// left empty.
 func (o *Output) UnmarshalJSON(b []byte) error {
+	if string(b) == "null" {
+		return nil
+	}
+
 	oj := struct {
 		BestBlock     string `json:"bestblock"`
 		Confirmations uint32 `json:"confirmations"`
@@ -212,3 +218,64 @@ type SendRawTransactionsResponse struct {
 		} `json:"ancestors"`
 	} `json:"unconfirmed"`
 }
+
+// SPVEnvelope model, a tx and the ancestors needed to verify it by SPV. A confirmed
+// tx carries a merkle proof of its inclusion in a block, while an unconfirmed tx
+// carries its parents, keyed by txid.
+type SPVEnvelope struct {
+	TxID    string                  `json:"txid"`
+	RawTx   string                  `json:"rawTx"`
+	Proof   *bc.MerkleProof         `json:"proof,omitempty"`
+	Parents map[string]*SPVEnvelope `json:"parents,omitempty"`
+}
+
+// MempoolTx model, a tx in the mempool with its entry.
+type MempoolTx struct {
+	Tx    *bt.Tx
+	Entry *MempoolEntry
+}
+
+// Outpoint model, an output of a tx.
+type Outpoint struct {
+	TxID string `json:"txid"`
+	Vout uint32 `json:"n"`
+}
+
+// OutpointCheck model, the status of an outpoint. The output of an unspent outpoint
+// is given, and the txid of the mempool tx spending a spent one, should it be known.
+// Outpoints spent in a block are indistinguishable from those never created, so have
+// an unknown status.
+type OutpointCheck struct {
+	Outpoint
+	Status  outpointStatus
+	Output  *Output
+	SpentBy string
+}
+
+// ScriptOutput model, an output indexed by the hash of its locking script, with the
+// input spending it should it be spent.
+type ScriptOutput struct {
+	Outpoint
+	ScriptHash  string `json:"scripthash"`
+	Satoshis    uint64 `json:"satoshis"`
+	Height      uint32 `json:"height"`
+	SpentTxID   string `json:"spenttxid,omitempty"`
+	SpentVin    uint32 `json:"spentvin,omitempty"`
+	SpentHeight uint32 `json:"spentheight,omitempty"`
+}
+
+// ScriptSpend model, an input spending an indexed output.
+type ScriptSpend struct {
+	Outpoint
+	SpentTxID string `json:"spenttxid"`
+	SpentVin  uint32 `json:"spentvin"`
+}
+
+// ScriptIndexBlock model, the outputs a block creates and spends, as indexed.
+type ScriptIndexBlock struct {
+	Hash     string          `json:"hash"`
+	PrevHash string          `json:"previousblockhash"`
+	Height   uint32          `json:"height"`
+	Outputs  []*ScriptOutput `json:"outputs"`
+	Spends   []*ScriptSpend  `json:"spends"`
+}
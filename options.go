@@ -3,7 +3,10 @@ package bn
 import (
 	"time"
 
-	"github.com/libsv/go-bn/internal/service"
+	"github.com/libsv/go-bn/internal/config"
+	"github.com/libsv/go-bn/metrics"
+	"github.com/libsv/go-bn/rpc"
+	"github.com/libsv/go-bn/zmq"
 )
 
 // BitcoinClientOptFunc for setting bitcoin client options.
@@ -12,11 +15,36 @@ type BitcoinClientOptFunc func(c *clientOpts)
 type clientOpts struct {
 	timeout   time.Duration
 	host      string
-	rpc       service.RPC
+	rpc       rpc.RPC
 	username  string
 	password  string
-	cache     bool
+	cookie    string
+	cache     *config.Cache
 	isMainnet bool
+	retry     *RetryPolicy
+	pool      *Pool
+	limit     *RateLimitPolicy
+	mw        []rpc.Middleware
+	metrics   *metrics.Collector
+}
+
+// RetryPolicy configures the retrying of failed requests. Requests failing due to a
+// connection reset, an http 5xx or the node warming up are retried with exponential
+// backoff and jitter.
+//
+// Only methods which read, such as `getblock`, `getrawtransaction` or `listunspent`,
+// are retried. Any other method, such as `sendtoaddress`, `walletpassphrase` or
+// `submitblock`, is never retried unless listed in RetryMethods.
+type RetryPolicy struct {
+	// MaxAttempts the total number of attempts made, including the first.
+	MaxAttempts int
+	// BaseDelay the delay before the first retry, doubling with each attempt.
+	BaseDelay time.Duration
+	// MaxDelay the upper bound of the delay between attempts.
+	MaxDelay time.Duration
+	// RetryMethods methods which should be retried in addition to the reads, such as
+	// `sendrawtransaction` where resubmitting the same tx is known to be safe.
+	RetryMethods []string
 }
 
 // WithTimeout set the timeout for the http client.
@@ -26,10 +54,156 @@ func WithTimeout(seconds time.Duration) BitcoinClientOptFunc {
 	}
 }
 
-// WithCache enable response caching.
-func WithCache() BitcoinClientOptFunc {
+// WithCache enable response caching. By default up to 10000 results are held,
+// with results of volatile methods such as `getbestblockhash` expiring after a
+// second and those of content addressed methods such as raw `getblock` never
+// expiring. Verbose blocks, headers and txs, including confirmations, are cached as
+// volatile. Wallet calls and calls changing the node's state are never cached.
+func WithCache(oo ...CacheOptFunc) BitcoinClientOptFunc {
+	return func(c *clientOpts) {
+		c.cache = &config.Cache{
+			MaxEntries: 10000,
+			TTL:        time.Second,
+			Methods:    make(map[string]time.Duration),
+		}
+		for _, o := range oo {
+			o(c.cache)
+		}
+	}
+}
+
+// CacheOptFunc for setting cache options.
+type CacheOptFunc func(c *config.Cache)
+
+// CacheMaxEntries set the number of results held before the least recently used are evicted.
+func CacheMaxEntries(n int) CacheOptFunc {
+	return func(c *config.Cache) {
+		c.MaxEntries = n
+	}
+}
+
+// CacheTTL set how long results of volatile methods are cached for.
+func CacheTTL(ttl time.Duration) CacheOptFunc {
+	return func(c *config.Cache) {
+		c.TTL = ttl
+	}
+}
+
+// CacheInvalidateOn evict cached results as the node publishes new blocks and txs
+// leaving its mempool. Results depending upon the chain tip are evicted on each block,
+// while those listing the mempool or concerning a removed tx are evicted on
+// `discardfrommempool` and `removedfrommempoolblock`. Results which never change,
+// such as blocks by hash, are kept.
+//
+// The cache subscribes to `hashblock`, `discardfrommempool` and
+// `removedfrommempoolblock` through zmq.Shared, so may share mq with a ChainFollower
+// or MempoolMirror, though the topics must not be subscribed to on mq directly.
+// Should subscribing fail, every call made by the client returns the error, rather
+// than results going stale unnoticed.
+func CacheInvalidateOn(mq zmq.NodeMQ) CacheOptFunc {
+	return func(c *config.Cache) {
+		c.NodeMQ = mq
+	}
+}
+
+// CacheMethodTTL cache results of the methods for the provided duration.
+func CacheMethodTTL(ttl time.Duration, methods ...string) CacheOptFunc {
+	return func(c *config.Cache) {
+		for _, m := range methods {
+			c.Methods[m] = ttl
+		}
+	}
+}
+
+// CacheMethodImmutable cache results of the methods until evicted.
+func CacheMethodImmutable(methods ...string) CacheOptFunc {
+	return CacheMethodTTL(config.CacheImmutable, methods...)
+}
+
+// CacheMethodNever never cache results of the methods.
+func CacheMethodNever(methods ...string) CacheOptFunc {
+	return CacheMethodTTL(config.CacheNever, methods...)
+}
+
+// PoolNode the connection details of a node within a pool.
+type PoolNode struct {
+	Host       string
+	Username   string
+	Password   string
+	CookieFile string
+}
+
+// Pool configures a pool of nodes. Reads are balanced across nodes which are
+// reachable and whose chain is within MaxLag blocks of the best known tip, failing
+// over to another node should one become unreachable, answer with an http 5xx or be
+// warming up; other errors are returned as they are. Wallet and other stateful
+// calls, `sendrawtransaction` among them, are always sent to the primary, being the
+// first node, and never fail over, so are not sent twice.
+type Pool struct {
+	Nodes []PoolNode
+	// MaxLag the number of blocks a node may be behind the best tip and still serve reads.
+	MaxLag uint32
+	// HealthCheckInterval how often each node's health and height is checked with
+	// `ping` and `getblockchaininfo`. Checks run in the background, each bounded by
+	// the interval. Defaults to 10s.
+	HealthCheckInterval time.Duration
+}
+
+// WithRetry enable retrying failed requests with the provided policy. Unset
+// fields fall back to 3 attempts, with delays between 100ms and 5s.
+func WithRetry(p RetryPolicy) BitcoinClientOptFunc {
+	return func(c *clientOpts) {
+		c.retry = &p
+	}
+}
+
+// MethodClassHeavy the class of methods which are expensive for the node to serve,
+// such as `getblock`, `getrawmempool` and `gettxoutsetinfo`.
+const MethodClassHeavy = "heavy"
+
+// RateLimit a budget of calls. Zero values are unlimited.
+type RateLimit struct {
+	// MaxInFlight the number of calls which may be awaiting a response at once.
+	MaxInFlight int
+	// PerSecond the number of calls which may be started each second.
+	PerSecond float64
+	// Burst the number of calls which may be started at once, should the budget
+	// have gone unused.
+	Burst int
+}
+
+// RateLimitPolicy configures limits on the calls made to the node, so as not to
+// overload its `rpcworkqueue`. Calls queue until within budget, or until their
+// context is done.
+//
+// Should the node answer 503, all calls are paused with exponential backoff until
+// it recovers.
+type RateLimitPolicy struct {
+	// Default the budget of methods whose class has no budget of its own.
+	Default RateLimit
+	// Classes budgets keyed by method class, such as MethodClassHeavy.
+	Classes map[string]RateLimit
+	// MethodClasses assigns methods to classes, overriding the built-in classes.
+	MethodClasses map[string]string
+	// BaseBackoff the pause after the node first answers 503, doubling with each
+	// consecutive 503.
+	BaseBackoff time.Duration
+	// MaxBackoff the upper bound of the pause.
+	MaxBackoff time.Duration
+}
+
+// WithRateLimit limit the calls in flight and started per second.
+func WithRateLimit(p RateLimitPolicy) BitcoinClientOptFunc {
+	return func(c *clientOpts) {
+		c.limit = &p
+	}
+}
+
+// WithPool connect to a pool of nodes rather than a single host. When set, WithHost
+// and WithCreds are ignored.
+func WithPool(p Pool) BitcoinClientOptFunc {
 	return func(c *clientOpts) {
-		c.cache = true
+		c.pool = &p
 	}
 }
 
@@ -48,6 +222,15 @@ func WithCreds(username, password string) BitcoinClientOptFunc {
 	}
 }
 
+// WithCookieFile authenticate using the .cookie file written to the node's datadir
+// when started without an rpcpassword. The cookie is re-read should the node
+// reject it, as happens after a restart. Overrides WithCreds.
+func WithCookieFile(path string) BitcoinClientOptFunc {
+	return func(c *clientOpts) {
+		c.cookie = path
+	}
+}
+
 // WithMainnet set whether or not the node is a mainnet node.
 func WithMainnet() BitcoinClientOptFunc {
 	return func(c *clientOpts) {
@@ -56,8 +239,98 @@ func WithMainnet() BitcoinClientOptFunc {
 }
 
 // WithCustomRPC set a custom RPC client.
-func WithCustomRPC(rpc service.RPC) BitcoinClientOptFunc {
+func WithCustomRPC(r rpc.RPC) BitcoinClientOptFunc {
 	return func(c *clientOpts) {
-		c.rpc = rpc
+		c.rpc = r
+	}
+}
+
+// WithMiddleware wrap every call made by the client with the middlewares, the first
+// being the outermost. Middlewares sit around the http transport, retries and cache,
+// or around the custom RPC client if one is set. Repeated use appends.
+func WithMiddleware(mm ...rpc.Middleware) BitcoinClientOptFunc {
+	return func(c *clientOpts) {
+		c.mw = append(c.mw, mm...)
+	}
+}
+
+// WithMetrics record per-method call counts, errors, latencies and in-flight calls
+// into the collector. The collector is installed around the http transport, or pool,
+// beneath the rate limits, retries and cache, so measures the requests sent to the
+// node: each retry is recorded as a call of its own, while calls served by the cache,
+// counted by CacheStats, and time spent waiting on rate limits are not recorded. With
+// WithCustomRPC, it is installed around the custom RPC client, beneath any
+// middlewares. The collector is also told of calls deduplicated by the http transport.
+func WithMetrics(m *metrics.Collector) BitcoinClientOptFunc {
+	return func(c *clientOpts) {
+		c.metrics = m
+	}
+}
+
+func (p *RetryPolicy) config() *config.Retry {
+	cfg := &config.Retry{
+		MaxAttempts: p.MaxAttempts,
+		BaseDelay:   p.BaseDelay,
+		MaxDelay:    p.MaxDelay,
+		Methods:     make(map[string]bool, len(p.RetryMethods)),
+	}
+	if cfg.MaxAttempts <= 0 {
+		cfg.MaxAttempts = 3
 	}
+	if cfg.BaseDelay <= 0 {
+		cfg.BaseDelay = 100 * time.Millisecond
+	}
+	if cfg.MaxDelay <= 0 {
+		cfg.MaxDelay = 5 * time.Second
+	}
+	for _, m := range p.RetryMethods {
+		cfg.Methods[m] = true
+	}
+
+	return cfg
+}
+
+func (p *Pool) config() *config.Pool {
+	cfg := &config.Pool{
+		Nodes:               make([]*config.RPC, len(p.Nodes)),
+		MaxLag:              p.MaxLag,
+		HealthCheckInterval: p.HealthCheckInterval,
+	}
+	if cfg.HealthCheckInterval <= 0 {
+		cfg.HealthCheckInterval = 10 * time.Second
+	}
+	for i, n := range p.Nodes {
+		cfg.Nodes[i] = &config.RPC{
+			Host:       n.Host,
+			Username:   n.Username,
+			Password:   n.Password,
+			CookieFile: n.CookieFile,
+		}
+	}
+
+	return cfg
+}
+
+func (p *RateLimitPolicy) config() *config.Limit {
+	cfg := &config.Limit{
+		Default:     config.LimitClass(p.Default),
+		Classes:     make(map[string]config.LimitClass, len(p.Classes)),
+		Methods:     make(map[string]string, len(p.MethodClasses)),
+		BaseBackoff: p.BaseBackoff,
+		MaxBackoff:  p.MaxBackoff,
+	}
+	if cfg.BaseBackoff <= 0 {
+		cfg.BaseBackoff = 100 * time.Millisecond
+	}
+	if cfg.MaxBackoff <= 0 {
+		cfg.MaxBackoff = 5 * time.Second
+	}
+	for name, c := range p.Classes {
+		cfg.Classes[name] = config.LimitClass(c)
+	}
+	for m, name := range p.MethodClasses {
+		cfg.Methods[m] = name
+	}
+
+	return cfg
 }
@@ -0,0 +1,174 @@
+package bn
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/libsv/go-bn/internal/util"
+	"github.com/libsv/go-bn/models"
+	"github.com/libsv/go-bt/v2"
+	"github.com/libsv/go-bt/v2/bscript"
+)
+
+// outpointsChunkSize the number of outpoints checked per `gettxouts` call, or batch
+// of `gettxout` calls.
+const outpointsChunkSize = 1000
+
+// txOuts a `gettxouts` response.
+type txOuts struct {
+	TxOuts []struct {
+		ScriptPubKey  string  `json:"scriptPubKey"`
+		Value         float64 `json:"value"`
+		Confirmations uint32  `json:"confirmations"`
+		Error         string  `json:"error"`
+		CollidedWith  *struct {
+			TxID string `json:"txid"`
+		} `json:"collidedWith"`
+	} `json:"txouts"`
+}
+
+// CheckOutpoints check whether each of the outpoints is unspent, including spends by
+// mempool txs should includeMempool be set, returning the checks in the order of the
+// outpoints. The outpoints are checked in bulk by `gettxouts`, or batches of
+// `gettxout` on nodes without it. Outputs from `gettxouts` have no best block nor
+// coinbase flag, which it does not return.
+func (c *client) CheckOutpoints(ctx context.Context, outpoints []models.Outpoint,
+	includeMempool bool) ([]*models.OutpointCheck, error) {
+	checks := make([]*models.OutpointCheck, 0, len(outpoints))
+	bulk := true
+	for len(outpoints) > 0 {
+		n := len(outpoints)
+		if n > outpointsChunkSize {
+			n = outpointsChunkSize
+		}
+
+		var cc []*models.OutpointCheck
+		var err error
+		if bulk {
+			cc, err = c.txOuts(ctx, outpoints[:n], includeMempool)
+			if errors.Is(err, models.ErrMethodNotFound) {
+				bulk = false
+				continue
+			}
+		} else {
+			cc, err = c.txOutsBatch(ctx, outpoints[:n], includeMempool)
+		}
+		if err != nil {
+			return nil, err
+		}
+		checks = append(checks, cc...)
+		outpoints = outpoints[n:]
+	}
+	return checks, nil
+}
+
+// txOuts check the outpoints with `gettxouts`.
+func (c *client) txOuts(ctx context.Context, outpoints []models.Outpoint,
+	includeMempool bool) ([]*models.OutpointCheck, error) {
+	var resp txOuts
+	fields := []string{"scriptPubKey", "value", "confirmations"}
+	if err := c.rpc.Do(ctx, "gettxouts", &resp, outpoints, fields, includeMempool); err != nil {
+		return nil, err
+	}
+	if len(resp.TxOuts) != len(outpoints) {
+		return nil, fmt.Errorf("gettxouts returned %d outputs for %d outpoints", len(resp.TxOuts), len(outpoints))
+	}
+
+	checks := make([]*models.OutpointCheck, len(outpoints))
+	for i, out := range resp.TxOuts {
+		check := &models.OutpointCheck{Outpoint: outpoints[i], Status: models.OutpointStatusUnknown}
+		switch out.Error {
+		case "":
+			script, err := bscript.NewFromHexString(out.ScriptPubKey)
+			if err != nil {
+				return nil, err
+			}
+			check.Status = models.OutpointStatusUnspent
+			check.Output = &models.Output{
+				Confirmations: out.Confirmations,
+				Output: &bt.Output{
+					Satoshis:      util.BSVToSatoshis(out.Value),
+					LockingScript: script,
+				},
+			}
+		case "spent":
+			check.Status = models.OutpointStatusSpent
+			if out.CollidedWith != nil {
+				check.SpentBy = out.CollidedWith.TxID
+			}
+		}
+		checks[i] = check
+	}
+	return checks, nil
+}
+
+// txOutsBatch check the outpoints with a batch of `gettxout` calls. Those not unspent
+// including the mempool are checked again excluding it, any then unspent having been
+// spent by a mempool tx.
+func (c *client) txOutsBatch(ctx context.Context, outpoints []models.Outpoint,
+	includeMempool bool) ([]*models.OutpointCheck, error) {
+	checks := make([]*models.OutpointCheck, len(outpoints))
+	outs, err := c.txOutBatch(ctx, outpoints, includeMempool)
+	if err != nil {
+		return nil, err
+	}
+	var missing []int
+	for i, out := range outs {
+		checks[i] = &models.OutpointCheck{Outpoint: outpoints[i], Status: models.OutpointStatusUnknown}
+		if out != nil {
+			checks[i].Status = models.OutpointStatusUnspent
+			checks[i].Output = out
+			continue
+		}
+		missing = append(missing, i)
+	}
+	if !includeMempool || len(missing) == 0 {
+		return checks, nil
+	}
+
+	unspent := make([]models.Outpoint, len(missing))
+	for i, m := range missing {
+		unspent[i] = outpoints[m]
+	}
+	if outs, err = c.txOutBatch(ctx, unspent, false); err != nil {
+		return nil, err
+	}
+	for i, out := range outs {
+		if out != nil {
+			checks[missing[i]].Status = models.OutpointStatusSpent
+		}
+	}
+	return checks, nil
+}
+
+// txOutBatch the output of each outpoint, or nil should it not be unspent.
+func (c *client) txOutBatch(ctx context.Context, outpoints []models.Outpoint,
+	includeMempool bool) ([]*models.Output, error) {
+	b := NewBatch()
+	raws := make([]json.RawMessage, len(outpoints))
+	calls := make([]*BatchCall, len(outpoints))
+	for i, op := range outpoints {
+		calls[i] = b.Call("gettxout", &raws[i], op.TxID, op.Vout, includeMempool)
+	}
+	if err := c.Batch(ctx, b); err != nil {
+		return nil, err
+	}
+
+	outs := make([]*models.Output, len(outpoints))
+	for i, call := range calls {
+		if err := call.Err(); err != nil {
+			return nil, fmt.Errorf("outpoint %s:%d: %w", outpoints[i].TxID, outpoints[i].Vout, err)
+		}
+		if len(raws[i]) == 0 || string(raws[i]) == "null" {
+			continue
+		}
+		out := models.Output{Output: &bt.Output{}}
+		if err := json.Unmarshal(raws[i], &out); err != nil {
+			return nil, err
+		}
+		outs[i] = &out
+	}
+	return outs, nil
+}
@@ -0,0 +1,101 @@
+package bn_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/libsv/go-bn"
+	"github.com/libsv/go-bn/fakenode"
+	"github.com/libsv/go-bn/internal/mocks"
+	"github.com/libsv/go-bn/internal/service"
+	"github.com/libsv/go-bn/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBlockChainClient_CheckOutpoints(t *testing.T) {
+	t.Parallel()
+	const resp = `{"txouts": [
+		{"scriptPubKey": "76a914316230517501a16e2837465ec28c157fa61cabec88ac", "value": 0.0001, "confirmations": 3},
+		{"error": "spent", "collidedWith": {"txid": "beef", "size": 191, "hex": "01"}},
+		{"error": "missing"}
+	]}`
+	outpoints := []models.Outpoint{{TxID: "aa", Vout: 0}, {TxID: "aa", Vout: 1}, {TxID: "bb", Vout: 0}}
+	c := bn.NewNodeClient(bn.WithCustomRPC(&mocks.MockRPC{
+		DoFunc: func(ctx context.Context, method string, out interface{}, args ...interface{}) error {
+			assert.Equal(t, "gettxouts", method)
+			assert.Equal(t, []interface{}{
+				outpoints, []string{"scriptPubKey", "value", "confirmations"}, true,
+			}, args)
+			return service.DecodeResult([]byte(resp), out)
+		},
+	}))
+
+	checks, err := c.CheckOutpoints(context.Background(), outpoints, true)
+	assert.NoError(t, err)
+	assert.Len(t, checks, 3)
+	assert.Equal(t, outpoints[0], checks[0].Outpoint)
+	assert.Equal(t, models.OutpointStatusUnspent, checks[0].Status)
+	assert.Equal(t, uint64(10000), checks[0].Output.Satoshis)
+	assert.Equal(t, uint32(3), checks[0].Output.Confirmations)
+	assert.Equal(t, "76a914316230517501a16e2837465ec28c157fa61cabec88ac", checks[0].Output.LockingScript.String())
+	assert.Equal(t, &models.OutpointCheck{
+		Outpoint: outpoints[1],
+		Status:   models.OutpointStatusSpent,
+		SpentBy:  "beef",
+	}, checks[1])
+	assert.Equal(t, &models.OutpointCheck{Outpoint: outpoints[2], Status: models.OutpointStatusUnknown}, checks[2])
+}
+
+func TestBlockChainClient_CheckOutpoints_GetTxOut(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	c := bn.NewNodeClient(bn.WithCustomRPC(fakenode.New()))
+	addr, err := c.NewAddress(ctx, nil)
+	assert.NoError(t, err)
+	_, err = c.GenerateToAddress(ctx, 101, addr, nil)
+	assert.NoError(t, err)
+	tx := send(t, c, addr)
+
+	outpoints := []models.Outpoint{
+		{TxID: tx.Inputs[0].PreviousTxIDStr(), Vout: tx.Inputs[0].PreviousTxOutIndex},
+		{TxID: tx.TxID(), Vout: 0},
+		{TxID: tx.TxID(), Vout: 9},
+	}
+	tests := map[string]struct {
+		includeMempool bool
+		expStatuses    []interface{}
+	}{
+		"including mempool": {
+			includeMempool: true,
+			expStatuses: []interface{}{
+				models.OutpointStatusSpent, models.OutpointStatusUnspent, models.OutpointStatusUnknown,
+			},
+		},
+		"excluding mempool": {
+			expStatuses: []interface{}{
+				models.OutpointStatusUnspent, models.OutpointStatusUnknown, models.OutpointStatusUnknown,
+			},
+		},
+	}
+
+	for name, test := range tests {
+		test := test
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			checks, err := c.CheckOutpoints(ctx, outpoints, test.includeMempool)
+			assert.NoError(t, err)
+			var statuses []interface{}
+			for i, check := range checks {
+				assert.Equal(t, outpoints[i], check.Outpoint)
+				statuses = append(statuses, check.Status)
+				if check.Status == models.OutpointStatusUnspent {
+					assert.NotZero(t, check.Output.Satoshis)
+					assert.NotEmpty(t, check.Output.BestBlock)
+				} else {
+					assert.Nil(t, check.Output)
+				}
+			}
+			assert.Equal(t, test.expStatuses, statuses)
+		})
+	}
+}
@@ -0,0 +1,101 @@
+package bn
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/libsv/go-bn/models"
+)
+
+const (
+	defaultReadyPollInterval = time.Second
+	defaultReadyProgress     = 0.9999
+)
+
+// WaitReady block until the node has finished warming up and caught up with the
+// chain, polling it until it answers pings, has validated as many blocks as it has
+// headers, has passed opts.MinVerificationProgress and has opts.MinConnections
+// peers. Whilst the node warms up or is unreachable, such as during a restart, it
+// is polled again, any other error being returned. ctx bounds the wait:
+//
+//	ctx, cancel := context.WithTimeout(ctx, 10*time.Minute)
+//	defer cancel()
+//	err := c.WaitReady(ctx, &models.OptsWaitReady{
+//		OnProgress: func(p *models.ReadyProgress) {
+//			log.Printf("%d/%d blocks", p.Blocks, p.Headers)
+//		},
+//	})
+func (c *client) WaitReady(ctx context.Context, opts *models.OptsWaitReady) error {
+	if opts == nil {
+		opts = &models.OptsWaitReady{}
+	}
+	interval := opts.PollInterval
+	if interval <= 0 {
+		interval = defaultReadyPollInterval
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		p, err := c.readiness(ctx, opts)
+		if err != nil {
+			return err
+		}
+		if opts.OnProgress != nil {
+			opts.OnProgress(p)
+		}
+		if p.Ready {
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// readiness poll the node once for its progress.
+func (c *client) readiness(ctx context.Context, opts *models.OptsWaitReady) (*models.ReadyProgress, error) {
+	var p models.ReadyProgress
+	if err := c.Ping(ctx); err != nil {
+		return notReady(ctx, &p, err)
+	}
+	chain, err := c.ChainInfo(ctx)
+	if err != nil {
+		return notReady(ctx, &p, err)
+	}
+	info, err := c.Info(ctx)
+	if err != nil {
+		return notReady(ctx, &p, err)
+	}
+
+	minProgress := opts.MinVerificationProgress
+	if minProgress <= 0 {
+		minProgress = defaultReadyProgress
+	}
+	p.Blocks = chain.Blocks
+	p.Headers = chain.Headers
+	p.VerificationProgress = chain.VerificationProgress
+	p.Connections = info.Connections
+	p.Ready = p.Blocks == p.Headers &&
+		p.VerificationProgress >= minProgress &&
+		p.Connections >= opts.MinConnections
+	return &p, nil
+}
+
+// notReady record err on p should it be due to the node warming up or being
+// unreachable, returning it otherwise.
+func notReady(ctx context.Context, p *models.ReadyProgress, err error) (*models.ReadyProgress, error) {
+	if ctx.Err() != nil {
+		return nil, ctx.Err()
+	}
+	var transportErr *models.TransportError
+	if !errors.Is(err, models.ErrWarmingUp) && !errors.As(err, &transportErr) {
+		return nil, err
+	}
+	p.Err = err
+	return p, nil
+}
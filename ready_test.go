@@ -0,0 +1,145 @@
+package bn_test
+
+import (
+	"context"
+	"errors"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/libsv/go-bn"
+	"github.com/libsv/go-bn/internal/mocks"
+	"github.com/libsv/go-bn/models"
+	"github.com/stretchr/testify/assert"
+)
+
+// readyPoll the node's state at one poll.
+type readyPoll struct {
+	err         error
+	chain       models.ChainInfo
+	connections uint32
+}
+
+func TestNodeClient_WaitReady(t *testing.T) {
+	t.Parallel()
+	warmingUp := &models.Error{Code: models.ErrWarmingUp.Code, Message: "Loading block index..."}
+	unreachable := &models.TransportError{Err: &net.OpError{Op: "dial", Err: errors.New("connection refused")}}
+	synced := models.ChainInfo{Blocks: 100, Headers: 100, VerificationProgress: 1}
+
+	tests := map[string]struct {
+		polls       []readyPoll
+		opts        *models.OptsWaitReady
+		expPolls    int
+		expProgress []models.ReadyProgress
+		expErr      error
+	}{
+		"ready node": {
+			polls:    []readyPoll{{chain: synced}},
+			expPolls: 1,
+			expProgress: []models.ReadyProgress{
+				{Ready: true, Blocks: 100, Headers: 100, VerificationProgress: 1},
+			},
+		},
+		"waits for warm up and sync": {
+			polls: []readyPoll{
+				{err: unreachable},
+				{err: warmingUp},
+				{chain: models.ChainInfo{Blocks: 50, Headers: 100, VerificationProgress: 0.5}},
+				{chain: models.ChainInfo{Blocks: 100, Headers: 100, VerificationProgress: 0.99}},
+				{chain: synced},
+			},
+			expPolls: 5,
+			expProgress: []models.ReadyProgress{
+				{Err: unreachable},
+				{Err: warmingUp},
+				{Blocks: 50, Headers: 100, VerificationProgress: 0.5},
+				{Blocks: 100, Headers: 100, VerificationProgress: 0.99},
+				{Ready: true, Blocks: 100, Headers: 100, VerificationProgress: 1},
+			},
+		},
+		"verification progress threshold is configurable": {
+			polls: []readyPoll{
+				{chain: models.ChainInfo{Blocks: 100, Headers: 100, VerificationProgress: 0.99}},
+			},
+			opts:     &models.OptsWaitReady{MinVerificationProgress: 0.95},
+			expPolls: 1,
+			expProgress: []models.ReadyProgress{
+				{Ready: true, Blocks: 100, Headers: 100, VerificationProgress: 0.99},
+			},
+		},
+		"waits for peers": {
+			polls: []readyPoll{
+				{chain: synced},
+				{chain: synced, connections: 2},
+				{chain: synced, connections: 3},
+			},
+			opts:     &models.OptsWaitReady{MinConnections: 3},
+			expPolls: 3,
+			expProgress: []models.ReadyProgress{
+				{Blocks: 100, Headers: 100, VerificationProgress: 1},
+				{Blocks: 100, Headers: 100, VerificationProgress: 1, Connections: 2},
+				{Ready: true, Blocks: 100, Headers: 100, VerificationProgress: 1, Connections: 3},
+			},
+		},
+		"other errors are returned": {
+			polls:       []readyPoll{{err: warmingUp}, {err: &models.StatusError{StatusCode: 401}}},
+			expPolls:    2,
+			expProgress: []models.ReadyProgress{{Err: warmingUp}},
+			expErr:      &models.StatusError{StatusCode: 401},
+		},
+	}
+
+	for name, test := range tests {
+		test := test
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			var polls int
+			c := bn.NewNodeClient(bn.WithCustomRPC(&mocks.MockRPC{
+				DoFunc: func(ctx context.Context, method string, out interface{}, args ...interface{}) error {
+					if method == "ping" {
+						polls++
+					}
+					poll := test.polls[polls-1]
+					switch method {
+					case "ping":
+						return poll.err
+					case "getblockchaininfo":
+						*(out.(*models.ChainInfo)) = poll.chain
+					case "getinfo":
+						*(out.(*models.Info)) = models.Info{Connections: poll.connections}
+					}
+					return nil
+				},
+			}))
+
+			opts := test.opts
+			if opts == nil {
+				opts = &models.OptsWaitReady{}
+			}
+			opts.PollInterval = time.Millisecond
+			var progress []models.ReadyProgress
+			opts.OnProgress = func(p *models.ReadyProgress) {
+				progress = append(progress, *p)
+			}
+
+			err := c.WaitReady(context.Background(), opts)
+			assert.Equal(t, test.expErr, err)
+			assert.Equal(t, test.expPolls, polls)
+			assert.Equal(t, test.expProgress, progress)
+		})
+	}
+}
+
+func TestNodeClient_WaitReady_Deadline(t *testing.T) {
+	t.Parallel()
+	c := bn.NewNodeClient(bn.WithCustomRPC(&mocks.MockRPC{
+		DoFunc: func(ctx context.Context, method string, out interface{}, args ...interface{}) error {
+			return &models.Error{Code: models.ErrWarmingUp.Code, Message: "Loading block index..."}
+		},
+	}))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	err := c.WaitReady(ctx, &models.OptsWaitReady{PollInterval: time.Millisecond})
+	assert.True(t, errors.Is(err, context.DeadlineExceeded))
+}
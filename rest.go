@@ -0,0 +1,170 @@
+package bn
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/libsv/go-bc"
+	"github.com/libsv/go-bk/crypto"
+	"github.com/libsv/go-bn/internal/service"
+	"github.com/libsv/go-bn/models"
+	"github.com/libsv/go-bt/v2"
+)
+
+// mempoolHeight the height the REST interface gives outputs of mempool txs.
+const mempoolHeight = 0x7fffffff
+
+// RESTClient interfaces the read only block and transaction lookups served by a
+// node's REST interface, enabled with `-rest`. Blocks, headers and txs are fetched
+// in binary, far cheaper for the node to serve than hex within JSON-RPC, and decoded
+// into the same types as returned by the NodeClient. Binary blocks and headers carry
+// none of the chain details, such as Height and Confirmations, which are left zero.
+type RESTClient interface {
+	BlockHex(ctx context.Context, hash string) (string, error)
+	Block(ctx context.Context, hash string) (*models.Block, error)
+	BlockHeader(ctx context.Context, hash string) (*models.BlockHeader, error)
+	RawTransaction(ctx context.Context, txID string) (*bt.Tx, error)
+	Output(ctx context.Context, txID string, n int, opts *models.OptsOutput) (*models.Output, error)
+	ChainInfo(ctx context.Context) (*models.ChainInfo, error)
+}
+
+type restClient struct {
+	rest service.REST
+}
+
+// NewRESTClient returns a client of the REST interface of the node set with WithHost.
+// The interface is unauthenticated, so only WithHost and WithTimeout apply.
+func NewRESTClient(oo ...BitcoinClientOptFunc) RESTClient {
+	opts := &clientOpts{
+		timeout: 30 * time.Second,
+		host:    "http://localhost:8332",
+	}
+	for _, o := range oo {
+		o(opts)
+	}
+
+	return &restClient{
+		rest: service.NewREST(opts.host, &http.Client{Timeout: opts.timeout}),
+	}
+}
+
+func (c *restClient) BlockHex(ctx context.Context, hash string) (string, error) {
+	bb, err := c.rest.Get(ctx, "block/"+hash+".bin")
+	if err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(bb), nil
+}
+
+// Block the binary block carries none of the header's chain details, so Height,
+// Confirmations and the like are left zero.
+func (c *restClient) Block(ctx context.Context, hash string) (*models.Block, error) {
+	bb, err := c.rest.Get(ctx, "block/"+hash+".bin")
+	if err != nil {
+		return nil, err
+	}
+	b, err := bc.NewBlockFromBytes(bb)
+	if err != nil {
+		return nil, err
+	}
+
+	header := restHeader(b.BlockHeader)
+	header.NumTx = uint64(len(b.Txs))
+	return &models.Block{
+		Txs:         b.Txs,
+		BlockHeader: header,
+	}, nil
+}
+
+// BlockHeader as with Block, the header's chain details are left zero.
+func (c *restClient) BlockHeader(ctx context.Context, hash string) (*models.BlockHeader, error) {
+	bb, err := c.rest.Get(ctx, "headers/1/"+hash+".bin")
+	if err != nil {
+		return nil, err
+	}
+	// An unknown block has no headers, rather than a 404.
+	if len(bb) == 0 {
+		return nil, &models.Error{Code: models.ErrNotFound.Code, Message: hash + " not found"}
+	}
+
+	bh, err := bc.NewBlockHeaderFromBytes(bb)
+	if err != nil {
+		return nil, err
+	}
+	header := restHeader(bh)
+	return &header, nil
+}
+
+func (c *restClient) RawTransaction(ctx context.Context, txID string) (*bt.Tx, error) {
+	bb, err := c.rest.Get(ctx, "tx/"+txID+".bin")
+	if err != nil {
+		return nil, err
+	}
+	return bt.NewTxFromBytes(bb)
+}
+
+// Output as with the NodeClient, a spent or unknown output is returned empty, with
+// no BestBlock or LockingScript. Whether it was created by a coinbase is not served
+// over REST, so Coinbase is always false.
+func (c *restClient) Output(ctx context.Context, txID string, n int,
+	opts *models.OptsOutput) (*models.Output, error) {
+	path := "getutxos/"
+	if opts != nil && opts.IncludeMempool {
+		path += "checkmempool/"
+	}
+	bb, err := c.rest.Get(ctx, fmt.Sprintf("%s%s-%d.json", path, txID, n))
+	if err != nil {
+		return nil, err
+	}
+
+	var utxos struct {
+		ChainHeight  uint32            `json:"chainHeight"`
+		ChainTipHash string            `json:"chaintipHash"`
+		UTXOs        []json.RawMessage `json:"utxos"`
+	}
+	if err = json.Unmarshal(bb, &utxos); err != nil {
+		return nil, err
+	}
+	if len(utxos.UTXOs) == 0 {
+		return &models.Output{Output: &bt.Output{}}, nil
+	}
+
+	var utxo struct {
+		Height uint32 `json:"height"`
+	}
+	if err = json.Unmarshal(utxos.UTXOs[0], &utxo); err != nil {
+		return nil, err
+	}
+	resp := models.Output{
+		BestBlock: utxos.ChainTipHash,
+		Output:    &bt.Output{},
+	}
+	if err = json.Unmarshal(utxos.UTXOs[0], resp.Output.NodeJSON()); err != nil {
+		return nil, err
+	}
+	if utxo.Height != mempoolHeight && utxo.Height <= utxos.ChainHeight {
+		resp.Confirmations = utxos.ChainHeight - utxo.Height + 1
+	}
+	return &resp, nil
+}
+
+func (c *restClient) ChainInfo(ctx context.Context) (*models.ChainInfo, error) {
+	bb, err := c.rest.Get(ctx, "chaininfo.json")
+	if err != nil {
+		return nil, err
+	}
+	var resp models.ChainInfo
+	return &resp, json.Unmarshal(bb, &resp)
+}
+
+// restHeader the header as served in binary, identified by its hash.
+func restHeader(bh *bc.BlockHeader) models.BlockHeader {
+	return models.BlockHeader{
+		BlockHeader: bh,
+		Hash:        hex.EncodeToString(bt.ReverseBytes(crypto.Sha256d(bh.Bytes()))),
+	}
+}
@@ -0,0 +1,205 @@
+package bn_test
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/libsv/go-bk/crypto"
+	"github.com/libsv/go-bn"
+	"github.com/libsv/go-bn/models"
+	"github.com/libsv/go-bt/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+// The REST client serves the same lookups as the rpc client.
+var _ bn.RESTClient = bn.NewNodeClient()
+
+const (
+	restBlockHash = "0000000000000000000000000000000000000000000000000000000000000001"
+	restTxID      = "c98f2b1187c569d98e32f69cff4f09c8548208b0281661742f68af3ac877b8fb"
+	restUTXOs     = `{"chainHeight":102,"chaintipHash":"` + restBlockHash + `","bitmap":"1","utxos":[{"height":100,` +
+		`"value":48.99999808,"scriptPubKey":{"asm":"","hex":"76a914316230517501a16e2837465ec28c157fa61cabec88ac",` +
+		`"reqSigs":1,"type":"pubkeyhash"}}]}`
+	restMempoolUTXOs = `{"chainHeight":102,"chaintipHash":"` + restBlockHash + `","bitmap":"1","utxos":[{` +
+		`"height":2147483647,"value":1,"scriptPubKey":{"hex":"76a914316230517501a16e2837465ec28c157fa61cabec88ac"}}]}`
+)
+
+func restBlock(t *testing.T) []byte {
+	bb, err := ioutil.ReadFile("./testing/data/getblock_raw.json")
+	assert.NoError(t, err)
+	var resp models.Response
+	assert.NoError(t, json.Unmarshal(bb, &resp))
+	block, err := hex.DecodeString(resp.Result.(string))
+	assert.NoError(t, err)
+	return block
+}
+
+func restBlockHashOf(block []byte) string {
+	return hex.EncodeToString(bt.ReverseBytes(crypto.Sha256d(block[:80])))
+}
+
+func restServer(t *testing.T, routes map[string]string) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodGet, r.Method)
+		body, ok := routes[r.URL.Path]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(r.URL.Path[len("/rest/"):] + " not found\r\n"))
+			return
+		}
+		_, _ = w.Write([]byte(body))
+	}))
+}
+
+func TestRESTClient_Block(t *testing.T) {
+	t.Parallel()
+	block := restBlock(t)
+	svr := restServer(t, map[string]string{
+		"/rest/block/" + restBlockHash + ".bin":     string(block),
+		"/rest/headers/1/" + restBlockHash + ".bin": string(block[:80]),
+		"/rest/headers/1/" + restTxID + ".bin":      "",
+	})
+	defer svr.Close()
+	c := bn.NewRESTClient(bn.WithHost(svr.URL))
+	ctx := context.Background()
+
+	blockHex, err := c.BlockHex(ctx, restBlockHash)
+	assert.NoError(t, err)
+	assert.Equal(t, hex.EncodeToString(block), blockHex)
+
+	b, err := c.Block(ctx, restBlockHash)
+	assert.NoError(t, err)
+	assert.Equal(t, restBlockHashOf(block), b.Hash)
+	assert.Equal(t, uint64(2), b.NumTx)
+	assert.Equal(t, "1d00ffff", b.BitsStr())
+	assert.Len(t, b.Txs, 2)
+	assert.Equal(t, restTxID, b.Txs[0].TxID())
+
+	header, err := c.BlockHeader(ctx, restBlockHash)
+	assert.NoError(t, err)
+	assert.Equal(t, restBlockHashOf(block), header.Hash)
+	assert.Equal(t, b.HashPrevBlockStr(), header.HashPrevBlockStr())
+	assert.Equal(t, b.HashMerkleRootStr(), header.HashMerkleRootStr())
+
+	_, err = c.BlockHeader(ctx, restTxID)
+	assert.True(t, errors.Is(err, models.ErrNotFound))
+	_, err = c.Block(ctx, restTxID)
+	assert.True(t, errors.Is(err, models.ErrNotFound))
+}
+
+func TestRESTClient_Output(t *testing.T) {
+	t.Parallel()
+	tests := map[string]struct {
+		opts             *models.OptsOutput
+		path             string
+		body             string
+		expEmpty         bool
+		expSatoshis      uint64
+		expConfirmations uint32
+	}{
+		"confirmed output": {
+			path:             "/rest/getutxos/" + restTxID + "-0.json",
+			body:             restUTXOs,
+			expSatoshis:      4899999808,
+			expConfirmations: 3,
+		},
+		"mempool output": {
+			opts:        &models.OptsOutput{IncludeMempool: true},
+			path:        "/rest/getutxos/checkmempool/" + restTxID + "-0.json",
+			body:        restMempoolUTXOs,
+			expSatoshis: 100000000,
+		},
+		"spent output": {
+			path:     "/rest/getutxos/" + restTxID + "-0.json",
+			body:     `{"chainHeight":102,"chaintipHash":"` + restBlockHash + `","bitmap":"0","utxos":[]}`,
+			expEmpty: true,
+		},
+	}
+
+	for name, test := range tests {
+		test := test
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			svr := restServer(t, map[string]string{test.path: test.body})
+			defer svr.Close()
+
+			out, err := bn.NewRESTClient(bn.WithHost(svr.URL)).Output(context.Background(), restTxID, 0, test.opts)
+			assert.NoError(t, err)
+			if test.expEmpty {
+				assert.Equal(t, &models.Output{Output: &bt.Output{}}, out)
+				return
+			}
+			assert.Equal(t, restBlockHash, out.BestBlock)
+			assert.Equal(t, test.expSatoshis, out.Satoshis)
+			assert.Equal(t, test.expConfirmations, out.Confirmations)
+			assert.Equal(t, "76a914316230517501a16e2837465ec28c157fa61cabec88ac", out.LockingScriptHexString())
+		})
+	}
+}
+
+func TestRESTClient_Errors(t *testing.T) {
+	t.Parallel()
+	tests := map[string]struct {
+		status int
+		expIs  error
+		expAs  interface{}
+	}{
+		"not found is a node not found error": {
+			status: http.StatusNotFound,
+			expIs:  models.ErrNotFound,
+			expAs:  new(*models.Error),
+		},
+		"bad request is a node invalid parameter error": {
+			status: http.StatusBadRequest,
+			expIs:  models.ErrInvalidParameter,
+			expAs:  new(*models.Error),
+		},
+		"other statuses are status errors": {
+			status: http.StatusServiceUnavailable,
+			expAs:  new(*models.StatusError),
+		},
+	}
+
+	for name, test := range tests {
+		test := test
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			svr := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				assert.Equal(t, "/rest/tx/"+restTxID+".bin", r.URL.Path)
+				w.WriteHeader(test.status)
+				_, _ = w.Write([]byte("oh no"))
+			}))
+			defer svr.Close()
+
+			_, err := bn.NewRESTClient(bn.WithHost(svr.URL)).RawTransaction(context.Background(), restTxID)
+			assert.Error(t, err)
+			if test.expIs != nil {
+				assert.True(t, errors.Is(err, test.expIs))
+			}
+			assert.True(t, errors.As(err, test.expAs))
+		})
+	}
+}
+
+func TestRESTClient_ChainInfo(t *testing.T) {
+	t.Parallel()
+	svr := restServer(t, map[string]string{
+		"/rest/chaininfo.json": `{"chain":"regtest","blocks":102,"headers":102,"bestblockhash":"` + restBlockHash + `"}`,
+	})
+	defer svr.Close()
+
+	info, err := bn.NewRESTClient(bn.WithHost(svr.URL)).ChainInfo(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, &models.ChainInfo{
+		Chain:         "regtest",
+		Blocks:        102,
+		Headers:       102,
+		BestBlockHash: restBlockHash,
+	}, info)
+}
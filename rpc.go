@@ -1,16 +1,22 @@
 package bn
 
 import (
+	"context"
+	"fmt"
 	"net/http"
 	"reflect"
 	"time"
 
 	"github.com/libsv/go-bn/internal/config"
 	"github.com/libsv/go-bn/internal/service"
+	"github.com/libsv/go-bn/models"
+	"github.com/libsv/go-bn/rpc"
+	"github.com/libsv/go-bn/zmq"
 )
 
 // NodeClient interfaces interacting with all commands on a bitcoin node.
 type NodeClient interface {
+	BatchClient
 	BlockChainClient
 	ControlClient
 	MiningClient
@@ -18,6 +24,9 @@ type NodeClient interface {
 	TransactionClient
 	UtilClient
 	WalletClient
+
+	CacheStats() models.CacheStats
+	WaitReady(ctx context.Context, opts *models.OptsWaitReady) error
 }
 
 type positionalOptionalArgs interface {
@@ -25,10 +34,19 @@ type positionalOptionalArgs interface {
 }
 
 type client struct {
-	rpc       service.RPC
+	rpc       rpc.RPC
+	cache     cacheStats
 	isMainnet bool
 }
 
+type cacheStats interface {
+	Stats() models.CacheStats
+}
+
+type cacheSubscriber interface {
+	Subscribe(mq zmq.NodeMQ) error
+}
+
 // NewNodeClient returns a node client, built from the provided option funcs.
 // This client is used for interfacing with the bitcoin node across all subcategories.
 func NewNodeClient(oo ...BitcoinClientOptFunc) NodeClient {
@@ -43,27 +61,85 @@ func NewNodeClient(oo ...BitcoinClientOptFunc) NodeClient {
 	}
 
 	if opts.rpc != nil {
+		r := opts.rpc
+		if opts.metrics != nil {
+			r = opts.metrics.Middleware(r)
+		}
 		return &client{
-			rpc:       opts.rpc,
+			rpc:       rpc.Chain(r, opts.mw...),
 			isMainnet: opts.isMainnet,
 		}
 	}
 
-	rpc := service.NewRPC(&config.RPC{
-		Username: opts.username,
-		Password: opts.password,
-		Host:     opts.host,
-	}, &http.Client{Timeout: opts.timeout})
-	if opts.cache {
-		rpc = service.NewCache(rpc)
-	}
+	r, cache := newRPC(opts)
 
 	return &client{
-		rpc:       rpc,
+		rpc:       rpc.Chain(r, opts.mw...),
+		cache:     cache,
 		isMainnet: opts.isMainnet,
 	}
 }
 
+// newRPC build the RPC service from the client options, layering metrics, rate
+// limits, retries and caching on top of the http transport where enabled.
+func newRPC(opts *clientOpts) (rpc.RPC, cacheStats) {
+	c := &http.Client{Timeout: opts.timeout}
+	var onDedupe func(method string)
+	if opts.metrics != nil {
+		onDedupe = opts.metrics.Dedupe
+	}
+	var r rpc.RPC
+	if opts.pool != nil {
+		cfg := opts.pool.config()
+		for _, n := range cfg.Nodes {
+			n.OnDedupe = onDedupe
+		}
+		r = service.NewPool(cfg, c)
+	} else {
+		r = service.NewRPC(&config.RPC{
+			Username:   opts.username,
+			Password:   opts.password,
+			Host:       opts.host,
+			CookieFile: opts.cookie,
+			OnDedupe:   onDedupe,
+		}, c)
+	}
+	if opts.metrics != nil {
+		// Record the requests sent, each retry its own, not those served by the cache.
+		r = opts.metrics.Middleware(r)
+	}
+	if opts.limit != nil {
+		r = service.NewLimiter(r, opts.limit.config())
+	}
+	if opts.retry != nil {
+		r = service.NewRetry(r, opts.retry.config())
+	}
+	var cache cacheStats
+	if opts.cache != nil {
+		r = service.NewCache(r, opts.cache)
+		cache = r.(cacheStats)
+		if opts.cache.NodeMQ != nil {
+			if err := r.(cacheSubscriber).Subscribe(opts.cache.NodeMQ); err != nil {
+				err = fmt.Errorf("failed to subscribe cache invalidation: %w", err)
+				r = rpc.Func(func(context.Context, string, interface{}, ...interface{}) error {
+					return err
+				})
+			}
+		}
+	}
+
+	return r, cache
+}
+
+// CacheStats the response cache's hit, miss and eviction counts. Zero when
+// caching is not enabled.
+func (c *client) CacheStats() models.CacheStats {
+	if c.cache == nil {
+		return models.CacheStats{}
+	}
+	return c.cache.Stats()
+}
+
 func (c *client) argsFor(p positionalOptionalArgs, args ...interface{}) []interface{} {
 	if reflect.ValueOf(p).IsNil() {
 		return args
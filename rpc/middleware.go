@@ -0,0 +1,27 @@
+package rpc
+
+// Middleware wraps an RPC, returning an RPC which may act upon each call before
+// and after passing it to next, such as to log, trace or inject faults:
+//
+//	func logging(next rpc.RPC) rpc.RPC {
+//		return rpc.Func(func(ctx context.Context, method string, out interface{}, args ...interface{}) error {
+//			err := next.Do(ctx, method, out, args...)
+//			log.Println(method, err)
+//			return err
+//		})
+//	}
+//
+// Batches sent through an RPC which does not implement BatchRPC are split into
+// individual calls, and streamed results are buffered where it does not implement
+// StreamRPC, so a middleware should also implement DoBatch and DoStream should it
+// wish to preserve either.
+type Middleware func(next RPC) RPC
+
+// Chain wrap r with the middlewares, the first being the outermost, and so the
+// first to see each call.
+func Chain(r RPC, mm ...Middleware) RPC {
+	for i := len(mm) - 1; i >= 0; i-- {
+		r = mm[i](r)
+	}
+	return r
+}
@@ -0,0 +1,90 @@
+package rpc_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/libsv/go-bn/rpc"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestChain(t *testing.T) {
+	t.Parallel()
+
+	errFault := errors.New("injected fault")
+	tag := func(name string, trail *[]string) rpc.Middleware {
+		return func(next rpc.RPC) rpc.RPC {
+			return rpc.Func(func(ctx context.Context, method string, out interface{}, args ...interface{}) error {
+				*trail = append(*trail, name+":"+method)
+				return next.Do(ctx, method, out, args...)
+			})
+		}
+	}
+	fault := func(method string) rpc.Middleware {
+		return func(next rpc.RPC) rpc.RPC {
+			return rpc.Func(func(ctx context.Context, m string, out interface{}, args ...interface{}) error {
+				if m == method {
+					return errFault
+				}
+				return next.Do(ctx, m, out, args...)
+			})
+		}
+	}
+
+	tests := map[string]struct {
+		mw       func(trail *[]string) []rpc.Middleware
+		calls    []string
+		expTrail []string
+		expErrs  []error
+	}{
+		"no middleware calls rpc directly": {
+			mw:       func(trail *[]string) []rpc.Middleware { return nil },
+			calls:    []string{"getinfo"},
+			expTrail: []string{"rpc:getinfo"},
+			expErrs:  []error{nil},
+		},
+		"first middleware is outermost": {
+			mw: func(trail *[]string) []rpc.Middleware {
+				return []rpc.Middleware{tag("a", trail), tag("b", trail)}
+			},
+			calls: []string{"getinfo", "getblockcount"},
+			expTrail: []string{
+				"a:getinfo", "b:getinfo", "rpc:getinfo",
+				"a:getblockcount", "b:getblockcount", "rpc:getblockcount",
+			},
+			expErrs: []error{nil, nil},
+		},
+		"middleware can short circuit": {
+			mw: func(trail *[]string) []rpc.Middleware {
+				return []rpc.Middleware{tag("a", trail), fault("getblockcount")}
+			},
+			calls:    []string{"getinfo", "getblockcount"},
+			expTrail: []string{"a:getinfo", "rpc:getinfo", "a:getblockcount"},
+			expErrs:  []error{nil, errFault},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			var trail []string
+			r := rpc.Chain(rpc.Func(func(ctx context.Context, method string, out interface{}, args ...interface{}) error {
+				trail = append(trail, "rpc:"+method)
+				return nil
+			}), test.mw(&trail)...)
+
+			calls := make([]*rpc.Call, len(test.calls))
+			for i, m := range test.calls {
+				calls[i] = &rpc.Call{Method: m}
+			}
+			assert.NoError(t, rpc.DoBatch(context.TODO(), r, calls...))
+
+			errs := make([]error, len(calls))
+			for i, c := range calls {
+				errs[i] = c.Err
+			}
+			assert.Equal(t, test.expTrail, trail)
+			assert.Equal(t, test.expErrs, errs)
+		})
+	}
+}
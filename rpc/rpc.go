@@ -0,0 +1,54 @@
+// Package rpc defines the interface through which the node client sends calls to a
+// bitcoin node, allowing it to be implemented or decorated outside of this module.
+package rpc
+
+import (
+	"context"
+)
+
+// RPC interface with an rpc server.
+type RPC interface {
+	Do(ctx context.Context, method string, out interface{}, args ...interface{}) error
+}
+
+// BatchRPC interface with an rpc server capable of serving many calls in a single request.
+type BatchRPC interface {
+	DoBatch(ctx context.Context, calls ...*Call) error
+}
+
+// Call a single rpc call made as part of a batch. Out and Err are populated
+// once the batch has been sent.
+type Call struct {
+	Method string
+	Args   []interface{}
+	Out    interface{}
+	Err    error
+}
+
+// Func an adapter allowing an ordinary function to be used as an RPC.
+type Func func(ctx context.Context, method string, out interface{}, args ...interface{}) error
+
+// Do call f(ctx, method, out, args...).
+func (f Func) Do(ctx context.Context, method string, out interface{}, args ...interface{}) error {
+	return f(ctx, method, out, args...)
+}
+
+// DoBatch send the calls as a single batch if the RPC supports it, otherwise
+// send each call individually.
+func DoBatch(ctx context.Context, rpc RPC, calls ...*Call) error {
+	if len(calls) == 0 {
+		return nil
+	}
+	if b, ok := rpc.(BatchRPC); ok {
+		return b.DoBatch(ctx, calls...)
+	}
+
+	for _, c := range calls {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		c.Err = rpc.Do(ctx, c.Method, c.Out, c.Args...)
+	}
+
+	return nil
+}
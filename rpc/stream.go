@@ -0,0 +1,33 @@
+package rpc
+
+import (
+	"context"
+	"encoding/hex"
+	"io"
+	"strings"
+)
+
+// StreamRPC interface with an rpc server capable of streaming a hex encoded result,
+// such as a raw block, without holding the whole response in memory.
+type StreamRPC interface {
+	DoStream(ctx context.Context, method string, fn StreamFunc, args ...interface{}) error
+}
+
+// StreamFunc reads a result as it is received from the node. The reader yields the
+// result already decoded from hex, and is only valid until the func returns.
+type StreamFunc func(r io.Reader) error
+
+// DoStream stream the hex encoded result of the call to fn if the RPC supports it,
+// otherwise buffer the result and pass it to fn once received.
+func DoStream(ctx context.Context, rpc RPC, method string, fn StreamFunc, args ...interface{}) error {
+	if s, ok := rpc.(StreamRPC); ok {
+		return s.DoStream(ctx, method, fn, args...)
+	}
+
+	var result string
+	if err := rpc.Do(ctx, method, &result, args...); err != nil {
+		return err
+	}
+
+	return fn(hex.NewDecoder(strings.NewReader(result)))
+}
@@ -0,0 +1,19 @@
+package rpc
+
+import "context"
+
+type walletKey struct{}
+
+// WithWallet returns a context whose calls are served by the named wallet, for
+// nodes with several wallets loaded. The http transport sends them to the node's
+// `/wallet/<name>` endpoint.
+func WithWallet(ctx context.Context, name string) context.Context {
+	return context.WithValue(ctx, walletKey{}, name)
+}
+
+// Wallet the wallet calls made with ctx are served by, empty for the node's
+// default wallet.
+func Wallet(ctx context.Context) string {
+	name, _ := ctx.Value(walletKey{}).(string)
+	return name
+}
@@ -0,0 +1,64 @@
+package bn_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/libsv/go-bn"
+	"github.com/libsv/go-bn/metrics"
+	bnmocks "github.com/libsv/go-bn/mocks"
+	"github.com/libsv/go-bn/zmq"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewNodeClient_CacheInvalidationFailed(t *testing.T) {
+	t.Parallel()
+	mq := &bnmocks.NodeMQMock{
+		SubscribeFunc: func(topic zmq.Topic, fn zmq.MessageFunc) error {
+			return zmq.ErrInvalidTopic
+		},
+		UnsubscribeFunc: func(topic zmq.Topic) error {
+			return nil
+		},
+	}
+	c := bn.NewNodeClient(bn.WithCache(bn.CacheInvalidateOn(mq)))
+
+	_, err := c.BestBlockHash(context.Background())
+	assert.ErrorIs(t, err, zmq.ErrInvalidTopic)
+}
+
+func TestNewNodeClient_Metrics(t *testing.T) {
+	t.Parallel()
+	var calls int32
+	svr := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&calls, 1) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		_, _ = w.Write([]byte(`{"result":101,"error":null,"id":"go-bn"}`))
+	}))
+	defer svr.Close()
+
+	m := metrics.NewCollector()
+	c := bn.NewNodeClient(
+		bn.WithHost(svr.URL),
+		bn.WithMetrics(m),
+		bn.WithRetry(bn.RetryPolicy{BaseDelay: time.Millisecond}),
+		bn.WithCache(),
+	)
+	for i := 0; i < 2; i++ {
+		n, err := c.BlockCount(context.Background())
+		assert.NoError(t, err)
+		assert.Equal(t, uint32(101), n)
+	}
+
+	// Each attempt is recorded, the call served by the cache is not.
+	stats := m.Snapshot().Methods["getblockcount"]
+	assert.Equal(t, uint64(2), stats.Calls)
+	assert.Equal(t, uint64(1), stats.Errors)
+	assert.Equal(t, uint64(1), c.CacheStats().Hits)
+}
@@ -0,0 +1,272 @@
+package bn
+
+import (
+	"context"
+	"encoding/hex"
+	"errors"
+	"sort"
+
+	"github.com/libsv/go-bk/crypto"
+	"github.com/libsv/go-bn/models"
+	"github.com/libsv/go-bt/v2"
+	"github.com/libsv/go-bt/v2/bscript"
+)
+
+// errIndexerReorg stops an indexer's catch up walk upon reaching a block not building
+// on the last indexed, leaving the reorg to be followed.
+var errIndexerReorg = errors.New("reorg during catch up")
+
+// ErrScriptIndexUnbounded error when a ScriptIndexer, with a BoundedScriptIndexStore
+// such as one holding the whole index in memory, is run without IndexerFilter or
+// IndexerStart bounding the index.
+var ErrScriptIndexUnbounded = errors.New("script index held in memory needs a filter or start height")
+
+// ScriptHash the hash by which outputs with the locking script are indexed, its
+// sha256 in reverse byte order, as used by electrum servers.
+func ScriptHash(s *bscript.Script) string {
+	return hex.EncodeToString(bt.ReverseBytes(crypto.Sha256(*s)))
+}
+
+// AddressScriptHash the script hash of the p2pkh locking script paying addr.
+func AddressScriptHash(addr string) (string, error) {
+	s, err := bscript.NewP2PKHFromAddress(addr)
+	if err != nil {
+		return "", err
+	}
+	return ScriptHash(s), nil
+}
+
+// ScriptIndexer indexes the outputs of the node's best chain by the hash of their
+// locking script, with the inputs spending them, answering what the node, having no
+// address index, cannot:
+//
+//	store, err := bn.NewFileScriptIndexStore("scripts.idx")
+//	x := bn.NewScriptIndexer(c, store, bn.IndexerFilter(isWalletScript),
+//		bn.IndexerFollower(bn.FollowerNodeMQ(mq)))
+//	go x.Run(ctx)
+//	hash, err := bn.AddressScriptHash(addr)
+//	balance, err := x.Balance(ctx, hash)
+//
+// Running, it catches up from the store's tip to the node's best block, then follows
+// the chain, unindexing the blocks reorged out. Only confirmed outputs and spends are
+// indexed.
+type ScriptIndexer struct {
+	c        BlockChainClient
+	store    ScriptIndexStore
+	start    int
+	filter   func(s *bscript.Script) bool
+	walker   []BlockWalkerOptFunc
+	follower []ChainFollowerOptFunc
+}
+
+// ScriptIndexerOptFunc for setting script indexer options.
+type ScriptIndexerOptFunc func(x *ScriptIndexer)
+
+// IndexerStart set the height from which an empty store is indexed. Defaults to 0,
+// the genesis block.
+func IndexerStart(height int) ScriptIndexerOptFunc {
+	return func(x *ScriptIndexer) {
+		x.start = height
+	}
+}
+
+// IndexerFilter only index the outputs with locking scripts fn returns true for, such
+// as those of a wallet, rather than every output.
+func IndexerFilter(fn func(s *bscript.Script) bool) ScriptIndexerOptFunc {
+	return func(x *ScriptIndexer) {
+		x.filter = fn
+	}
+}
+
+// IndexerWalker set the options of the block walker catching up with the node.
+func IndexerWalker(oo ...BlockWalkerOptFunc) ScriptIndexerOptFunc {
+	return func(x *ScriptIndexer) {
+		x.walker = oo
+	}
+}
+
+// IndexerFollower set the options of the chain follower following the node once
+// caught up, such as FollowerNodeMQ.
+func IndexerFollower(oo ...ChainFollowerOptFunc) ScriptIndexerOptFunc {
+	return func(x *ScriptIndexer) {
+		x.follower = oo
+	}
+}
+
+// NewScriptIndexer returns an indexer of the chain of the node c is a client of,
+// keeping its index in store.
+func NewScriptIndexer(c BlockChainClient, store ScriptIndexStore, oo ...ScriptIndexerOptFunc) *ScriptIndexer {
+	x := &ScriptIndexer{c: c, store: store}
+	for _, o := range oo {
+		o(x)
+	}
+	return x
+}
+
+// Run index the chain until ctx is done or an error is returned by the node or store.
+// Should it return an error, calling Run again resumes from the store's tip. Run
+// returns ErrScriptIndexUnbounded should the store be a BoundedScriptIndexStore, as
+// the stores of this package are, and neither IndexerFilter nor IndexerStart be set.
+func (x *ScriptIndexer) Run(ctx context.Context) error {
+	if b, ok := x.store.(BoundedScriptIndexStore); ok && b.Bounded() && x.filter == nil && x.start == 0 {
+		return ErrScriptIndexUnbounded
+	}
+	hash, height, err := x.store.Tip(ctx)
+	if err != nil {
+		return err
+	}
+	from := x.start
+	if hash != "" {
+		from = int(height) + 1
+	}
+	best, err := x.c.BlockCount(ctx)
+	if err != nil {
+		return err
+	}
+
+	err = NewBlockWalker(x.c, x.walker...).Walk(ctx, from, int(best), func(b *models.Block) error {
+		if hash != "" && b.HashPrevBlockStr() != hash {
+			return errIndexerReorg
+		}
+		if err := x.connect(ctx, b); err != nil {
+			return err
+		}
+		hash = b.Hash
+		return nil
+	})
+	if err != nil && !errors.Is(err, errIndexerReorg) {
+		return err
+	}
+
+	oo := x.follower
+	if hash != "" {
+		oo = append(append([]ChainFollowerOptFunc{}, oo...), FollowerFrom(hash))
+	}
+	return NewChainFollower(x.c, oo...).Run(ctx, func(e ChainEvent) error {
+		switch e := e.(type) {
+		case BlockConnected:
+			if int(e.Header.Height) < x.start {
+				return nil
+			}
+			b, err := x.c.Block(ctx, e.Header.Hash)
+			if err != nil {
+				return err
+			}
+			return x.connect(ctx, b)
+		case BlockDisconnected:
+			return x.disconnect(ctx, e.Header.Hash)
+		}
+		return nil
+	})
+}
+
+// History the outputs paying the script hash, spent or not, in the order mined.
+func (x *ScriptIndexer) History(ctx context.Context, scriptHash string) ([]*models.ScriptOutput, error) {
+	outs, err := x.store.Outputs(ctx, scriptHash)
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(outs, func(i, j int) bool {
+		if outs[i].Height != outs[j].Height {
+			return outs[i].Height < outs[j].Height
+		}
+		if outs[i].TxID != outs[j].TxID {
+			return outs[i].TxID < outs[j].TxID
+		}
+		return outs[i].Vout < outs[j].Vout
+	})
+	return outs, nil
+}
+
+// UTXOs the unspent outputs paying the script hash, in the order mined.
+func (x *ScriptIndexer) UTXOs(ctx context.Context, scriptHash string) ([]*models.ScriptOutput, error) {
+	outs, err := x.History(ctx, scriptHash)
+	if err != nil {
+		return nil, err
+	}
+	utxos := make([]*models.ScriptOutput, 0, len(outs))
+	for _, out := range outs {
+		if out.SpentTxID == "" {
+			utxos = append(utxos, out)
+		}
+	}
+	return utxos, nil
+}
+
+// Balance the satoshis of the unspent outputs paying the script hash.
+func (x *ScriptIndexer) Balance(ctx context.Context, scriptHash string) (uint64, error) {
+	utxos, err := x.UTXOs(ctx, scriptHash)
+	if err != nil {
+		return 0, err
+	}
+	var balance uint64
+	for _, out := range utxos {
+		balance += out.Satoshis
+	}
+	return balance, nil
+}
+
+func (x *ScriptIndexer) connect(ctx context.Context, b *models.Block) error {
+	ib, err := x.indexBlock(ctx, b)
+	if err != nil {
+		return err
+	}
+	return x.store.ConnectBlock(ctx, ib)
+}
+
+// disconnect the block with hash, should it be the store's tip, from the undo data
+// the store kept, the stale block possibly being unavailable from the node. Blocks
+// below the start height are never indexed, so have nothing to disconnect.
+func (x *ScriptIndexer) disconnect(ctx context.Context, hash string) error {
+	tip, _, err := x.store.Tip(ctx)
+	if err != nil {
+		return err
+	}
+	if tip != hash {
+		return nil
+	}
+	return x.store.DisconnectBlock(ctx, hash)
+}
+
+// indexBlock the outputs the block creates, and spends, which are indexed.
+func (x *ScriptIndexer) indexBlock(ctx context.Context, b *models.Block) (*models.ScriptIndexBlock, error) {
+	ib := &models.ScriptIndexBlock{Hash: b.Hash, PrevHash: b.HashPrevBlockStr(), Height: uint32(b.Height)}
+	created := make(map[models.Outpoint]bool)
+	for _, tx := range b.Txs {
+		txID := tx.TxID()
+		for i, out := range tx.Outputs {
+			if x.filter != nil && !x.filter(out.LockingScript) {
+				continue
+			}
+			op := models.Outpoint{TxID: txID, Vout: uint32(i)}
+			created[op] = true
+			ib.Outputs = append(ib.Outputs, &models.ScriptOutput{
+				Outpoint:   op,
+				ScriptHash: ScriptHash(out.LockingScript),
+				Satoshis:   out.Satoshis,
+				Height:     ib.Height,
+			})
+		}
+	}
+
+	for _, tx := range b.Txs {
+		if tx.IsCoinbase() {
+			continue
+		}
+		txID := tx.TxID()
+		for i, in := range tx.Inputs {
+			op := models.Outpoint{TxID: in.PreviousTxIDStr(), Vout: in.PreviousTxOutIndex}
+			if !created[op] {
+				out, err := x.store.Output(ctx, op)
+				if err != nil {
+					return nil, err
+				}
+				if out == nil {
+					continue
+				}
+			}
+			ib.Spends = append(ib.Spends, &models.ScriptSpend{Outpoint: op, SpentTxID: txID, SpentVin: uint32(i)})
+		}
+	}
+	return ib, nil
+}
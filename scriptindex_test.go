@@ -0,0 +1,428 @@
+package bn_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/libsv/go-bc"
+	"github.com/libsv/go-bn"
+	"github.com/libsv/go-bn/fakenode"
+	bnmocks "github.com/libsv/go-bn/mocks"
+	"github.com/libsv/go-bn/models"
+	"github.com/libsv/go-bt/v2"
+	"github.com/libsv/go-bt/v2/bscript"
+	"github.com/stretchr/testify/assert"
+)
+
+// indexed wait until the store's tip is at height.
+func indexed(t *testing.T, store bn.ScriptIndexStore, height uint32) {
+	assert.Eventually(t, func() bool {
+		_, h, err := store.Tip(context.Background())
+		return err == nil && h == height
+	}, 2*time.Second, time.Millisecond)
+}
+
+func TestScriptIndexer(t *testing.T) {
+	t.Parallel()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	c := bn.NewNodeClient(bn.WithCustomRPC(fakenode.New()))
+	miner, err := c.NewAddress(ctx, nil)
+	assert.NoError(t, err)
+	payee, err := c.NewAddress(ctx, nil)
+	assert.NoError(t, err)
+	_, err = c.GenerateToAddress(ctx, 101, miner, nil)
+	assert.NoError(t, err)
+	tx := send(t, c, payee)
+	_, err = c.Generate(ctx, 1, nil)
+	assert.NoError(t, err)
+
+	path := filepath.Join(t.TempDir(), "scripts.idx")
+	store, err := bn.NewFileScriptIndexStore(path)
+	assert.NoError(t, err)
+	x := bn.NewScriptIndexer(c, store, bn.IndexerStart(1),
+		bn.IndexerFollower(bn.FollowerPollInterval(time.Millisecond)))
+	errs := make(chan error, 1)
+	go func() {
+		errs <- x.Run(ctx)
+	}()
+	indexed(t, store, 102)
+
+	minerHash, err := bn.AddressScriptHash(miner)
+	assert.NoError(t, err)
+	history, err := x.History(ctx, minerHash)
+	assert.NoError(t, err)
+	assert.Len(t, history, 101)
+	assert.Equal(t, uint32(1), history[0].Height)
+	assert.Equal(t, tx.TxID(), history[0].SpentTxID)
+	assert.Equal(t, uint32(102), history[0].SpentHeight)
+	utxos, err := x.UTXOs(ctx, minerHash)
+	assert.NoError(t, err)
+	assert.Len(t, utxos, 100)
+	assert.Equal(t, history[1:], utxos)
+	balance, err := x.Balance(ctx, minerHash)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(100*50*1e8), balance)
+
+	payeeHash, err := bn.AddressScriptHash(payee)
+	assert.NoError(t, err)
+	assert.Equal(t, bn.ScriptHash(tx.Outputs[0].LockingScript), payeeHash)
+	history, err = x.History(ctx, payeeHash)
+	assert.NoError(t, err)
+	assert.Equal(t, []*models.ScriptOutput{{
+		Outpoint:   models.Outpoint{TxID: tx.TxID(), Vout: 0},
+		ScriptHash: payeeHash,
+		Satoshis:   1000,
+		Height:     102,
+	}}, history)
+
+	// Blocks mined once caught up are followed.
+	send(t, c, payee)
+	_, err = c.Generate(ctx, 1, nil)
+	assert.NoError(t, err)
+	indexed(t, store, 103)
+	balance, err = x.Balance(ctx, payeeHash)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(2000), balance)
+
+	cancel()
+	assert.ErrorIs(t, <-errs, context.Canceled)
+	assert.NoError(t, store.Close())
+
+	reopened, err := bn.NewFileScriptIndexStore(path)
+	assert.NoError(t, err)
+	defer reopened.Close()
+	hash, height, err := reopened.Tip(context.Background())
+	assert.NoError(t, err)
+	best, err := c.BestBlockHash(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, best, hash)
+	assert.Equal(t, uint32(103), height)
+	outs, err := reopened.Outputs(context.Background(), payeeHash)
+	assert.NoError(t, err)
+	assert.Len(t, outs, 2)
+}
+
+func TestScriptIndexer_FakeNodeReorg(t *testing.T) {
+	t.Parallel()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	node := fakenode.New()
+	c := bn.NewNodeClient(bn.WithCustomRPC(node))
+	miner, err := c.NewAddress(ctx, nil)
+	assert.NoError(t, err)
+	payee, err := c.NewAddress(ctx, nil)
+	assert.NoError(t, err)
+	_, err = c.GenerateToAddress(ctx, 101, miner, nil)
+	assert.NoError(t, err)
+	send(t, c, payee)
+	stale, err := c.Generate(ctx, 1, nil)
+	assert.NoError(t, err)
+
+	store := bn.NewMemoryScriptIndexStore()
+	x := bn.NewScriptIndexer(c, store, bn.IndexerStart(1),
+		bn.IndexerFollower(bn.FollowerPollInterval(time.Millisecond)))
+	errs := make(chan error, 1)
+	go func() {
+		errs <- x.Run(ctx)
+	}()
+	indexed(t, store, 102)
+	payeeHash, err := bn.AddressScriptHash(payee)
+	assert.NoError(t, err)
+	balance, err := x.Balance(ctx, payeeHash)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(1000), balance)
+
+	// The block paying the payee is reorged out, the node reporting it stale, and the
+	// tx is not mined again.
+	assert.NoError(t, node.Do(ctx, "invalidateblock", nil, stale[0]))
+	hashes, err := c.Generate(ctx, 2, nil)
+	assert.NoError(t, err)
+	indexed(t, store, 103)
+	hash, _, err := store.Tip(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, hashes[1], hash)
+
+	history, err := x.History(ctx, payeeHash)
+	assert.NoError(t, err)
+	assert.Empty(t, history)
+	minerHash, err := bn.AddressScriptHash(miner)
+	assert.NoError(t, err)
+	utxos, err := x.UTXOs(ctx, minerHash)
+	assert.NoError(t, err)
+	assert.Len(t, utxos, 101)
+
+	cancel()
+	assert.ErrorIs(t, <-errs, context.Canceled)
+}
+
+func TestScriptIndexer_Unbounded(t *testing.T) {
+	t.Parallel()
+	c := bn.NewNodeClient(bn.WithCustomRPC(fakenode.New()))
+	x := bn.NewScriptIndexer(c, bn.NewMemoryScriptIndexStore())
+	assert.ErrorIs(t, x.Run(context.Background()), bn.ErrScriptIndexUnbounded)
+}
+
+func TestScriptIndexer_UnboundedStore(t *testing.T) {
+	t.Parallel()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	c := bn.NewNodeClient(bn.WithCustomRPC(fakenode.New()))
+	_, err := c.Generate(ctx, 3, nil)
+	assert.NoError(t, err)
+
+	// A store not reporting itself Bounded, as one kept on disk would not, may index
+	// the whole chain.
+	store := struct{ bn.ScriptIndexStore }{bn.NewMemoryScriptIndexStore()}
+	x := bn.NewScriptIndexer(c, store, bn.IndexerFollower(bn.FollowerPollInterval(time.Millisecond)))
+	errs := make(chan error, 1)
+	go func() {
+		errs <- x.Run(ctx)
+	}()
+	indexed(t, store, 3)
+
+	cancel()
+	assert.ErrorIs(t, <-errs, context.Canceled)
+}
+
+// testScriptChain blocks a1 and a2, and b2 and b3 forking from a1, where a1 pays
+// script 51, a2 spends that output paying script 52, b2 pays script 53, and the
+// coinbases of a2 and b3 pay only data.
+type testScriptChain struct {
+	mu     sync.Mutex
+	blocks map[string]*models.Block
+	best   string
+}
+
+func newTestScriptChain(t *testing.T) *testScriptChain {
+	c := &testScriptChain{blocks: make(map[string]*models.Block)}
+	a1 := c.add(t, "a1", "", 1, "51")
+	c.add(t, "a2", a1.Hash, 2, "6a", a1.Txs[0].TxID())
+	b2 := c.add(t, "b2", a1.Hash, 2, "53")
+	c.add(t, "b3", b2.Hash, 3, "6a")
+	c.best = c.hash("a2")
+	return c
+}
+
+// add a block, whose coinbase pays script, and which has a tx spending output 0 of
+// each of the spends, paying script 52.
+func (c *testScriptChain) add(t *testing.T, name, prev string, height int, script string,
+	spends ...string) *models.Block {
+	coinbase := bt.NewTx()
+	in := &bt.Input{PreviousTxOutIndex: 0xffffffff, UnlockingScript: bscript.NewFromBytes([]byte(name))}
+	assert.NoError(t, in.PreviousTxIDAdd(make([]byte, 32)))
+	coinbase.Inputs = append(coinbase.Inputs, in)
+	s, err := bscript.NewFromHexString(script)
+	assert.NoError(t, err)
+	coinbase.AddOutput(&bt.Output{Satoshis: 50 * 1e8, LockingScript: s})
+	txs := []*bt.Tx{coinbase}
+
+	for _, txID := range spends {
+		tx := bt.NewTx()
+		assert.NoError(t, tx.From(txID, 0, "51", 50*1e8))
+		s, err := bscript.NewFromHexString("52")
+		assert.NoError(t, err)
+		tx.AddOutput(&bt.Output{Satoshis: 50 * 1e8, LockingScript: s})
+		txs = append(txs, tx)
+	}
+
+	hash := fmt.Sprintf("%x%060x", name, height)
+	prevBytes, err := hex.DecodeString(prev)
+	assert.NoError(t, err)
+	b := &models.Block{
+		Txs: txs,
+		BlockHeader: models.BlockHeader{
+			BlockHeader: &bc.BlockHeader{HashPrevBlock: prevBytes},
+			Hash:        hash,
+			Height:      uint64(height),
+		},
+	}
+	c.blocks[hash] = b
+	return b
+}
+
+func (c *testScriptChain) hash(name string) string {
+	for hash := range c.blocks {
+		if hash[:4] == hex.EncodeToString([]byte(name)) {
+			return hash
+		}
+	}
+	return ""
+}
+
+func (c *testScriptChain) reorg(name string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.best = c.hash(name)
+}
+
+func (c *testScriptChain) client() *bnmocks.BlockChainClientMock {
+	notFound := &models.Error{Code: models.ErrNotFound.Code, Message: "Block not found"}
+	return &bnmocks.BlockChainClientMock{
+		BlockCountFunc: func(ctx context.Context) (uint32, error) {
+			return 2, nil
+		},
+		BlockHashFunc: func(ctx context.Context, height int) (string, error) {
+			return c.hash(fmt.Sprintf("a%d", height)), nil
+		},
+		BestBlockHashFunc: func(ctx context.Context) (string, error) {
+			c.mu.Lock()
+			defer c.mu.Unlock()
+			return c.best, nil
+		},
+		BlockFunc: func(ctx context.Context, hash string) (*models.Block, error) {
+			b, ok := c.blocks[hash]
+			if !ok {
+				return nil, notFound
+			}
+			return b, nil
+		},
+		BlockHeaderFunc: func(ctx context.Context, hash string) (*models.BlockHeader, error) {
+			b, ok := c.blocks[hash]
+			if !ok {
+				return nil, notFound
+			}
+			return &b.BlockHeader, nil
+		},
+	}
+}
+
+func TestScriptIndexer_Reorg(t *testing.T) {
+	t.Parallel()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	chain := newTestScriptChain(t)
+	store := bn.NewMemoryScriptIndexStore()
+	x := bn.NewScriptIndexer(chain.client(), store, bn.IndexerStart(1),
+		bn.IndexerFollower(bn.FollowerPollInterval(time.Millisecond)),
+		bn.IndexerFilter(func(s *bscript.Script) bool {
+			return !s.IsData()
+		}))
+	go func() {
+		_ = x.Run(ctx)
+	}()
+	indexed(t, store, 2)
+
+	scriptHash := func(script string) string {
+		s, err := bscript.NewFromHexString(script)
+		assert.NoError(t, err)
+		return bn.ScriptHash(s)
+	}
+	utxos, err := x.UTXOs(ctx, scriptHash("51"))
+	assert.NoError(t, err)
+	assert.Empty(t, utxos)
+	utxos, err = x.UTXOs(ctx, scriptHash("52"))
+	assert.NoError(t, err)
+	assert.Len(t, utxos, 1)
+
+	chain.reorg("b3")
+	indexed(t, store, 3)
+	hash, _, err := store.Tip(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, chain.hash("b3"), hash)
+
+	utxos, err = x.UTXOs(ctx, scriptHash("51"))
+	assert.NoError(t, err)
+	assert.Len(t, utxos, 1)
+	history, err := x.History(ctx, scriptHash("52"))
+	assert.NoError(t, err)
+	assert.Empty(t, history)
+	history, err = x.History(ctx, scriptHash("53"))
+	assert.NoError(t, err)
+	assert.Len(t, history, 1)
+	history, err = x.History(ctx, scriptHash("6a"))
+	assert.NoError(t, err)
+	assert.Empty(t, history)
+}
+
+func TestFileScriptIndexStore_IncompleteRecord(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "scripts.idx")
+	store, err := bn.NewFileScriptIndexStore(path)
+	assert.NoError(t, err)
+	out := &models.ScriptOutput{Outpoint: models.Outpoint{TxID: "aa"}, ScriptHash: "s", Satoshis: 1, Height: 1}
+	assert.NoError(t, store.ConnectBlock(ctx, &models.ScriptIndexBlock{
+		Hash:    "b1",
+		Height:  1,
+		Outputs: []*models.ScriptOutput{out},
+	}))
+	err = store.ConnectBlock(ctx, &models.ScriptIndexBlock{Hash: "b3", PrevHash: "b2", Height: 3})
+	assert.ErrorIs(t, err, bn.ErrScriptIndexTip)
+	assert.NoError(t, store.Close())
+
+	// A crash whilst journaling the next block.
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0)
+	assert.NoError(t, err)
+	_, err = f.WriteString(`{"connect":{"hash":"b2","previousblockh`)
+	assert.NoError(t, err)
+	assert.NoError(t, f.Close())
+
+	store, err = bn.NewFileScriptIndexStore(path)
+	assert.NoError(t, err)
+	hash, height, err := store.Tip(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, "b1", hash)
+	assert.Equal(t, uint32(1), height)
+	assert.NoError(t, store.ConnectBlock(ctx, &models.ScriptIndexBlock{
+		Hash:     "b2",
+		PrevHash: "b1",
+		Height:   2,
+		Spends:   []*models.ScriptSpend{{Outpoint: out.Outpoint, SpentTxID: "bb"}},
+	}))
+	assert.NoError(t, store.Close())
+
+	store, err = bn.NewFileScriptIndexStore(path)
+	assert.NoError(t, err)
+	defer store.Close()
+	hash, _, err = store.Tip(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, "b2", hash)
+	got, err := store.Output(ctx, out.Outpoint)
+	assert.NoError(t, err)
+	assert.Equal(t, "bb", got.SpentTxID)
+	assert.Equal(t, uint32(2), got.SpentHeight)
+
+	// Opening compacted the journal to a snapshot.
+	bb, err := os.ReadFile(path)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, bytes.Count(bb, []byte("\n")))
+	assert.True(t, bytes.HasPrefix(bb, []byte(`{"snapshot":`)))
+	assert.NoError(t, store.DisconnectBlock(ctx, "b2"))
+	got, err = store.Output(ctx, out.Outpoint)
+	assert.NoError(t, err)
+	assert.Empty(t, got.SpentTxID)
+}
+
+func TestMemoryScriptIndexStore_UndoDepth(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	store := bn.NewMemoryScriptIndexStore()
+	var prev string
+	for height := 1; height <= bn.ScriptIndexUndoDepth+1; height++ {
+		hash := fmt.Sprintf("%064x", height)
+		assert.NoError(t, store.ConnectBlock(ctx, &models.ScriptIndexBlock{
+			Hash:     hash,
+			PrevHash: prev,
+			Height:   uint32(height),
+		}))
+		prev = hash
+	}
+
+	assert.ErrorIs(t, store.DisconnectBlock(ctx, fmt.Sprintf("%064x", 1)), bn.ErrScriptIndexTip)
+	for height := bn.ScriptIndexUndoDepth + 1; height > 1; height-- {
+		assert.NoError(t, store.DisconnectBlock(ctx, fmt.Sprintf("%064x", height)))
+	}
+	hash, height, err := store.Tip(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, fmt.Sprintf("%064x", 1), hash)
+	assert.Equal(t, uint32(1), height)
+	assert.ErrorIs(t, store.DisconnectBlock(ctx, hash), bn.ErrScriptIndexUndo)
+}
@@ -0,0 +1,430 @@
+package bn
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+
+	"github.com/libsv/go-bn/models"
+)
+
+// Script index store errors.
+var (
+	// ErrScriptIndexTip error when a block connected to a script index does not
+	// build on its tip, or a block disconnected from it is not its tip.
+	ErrScriptIndexTip = errors.New("block is not at the script index tip")
+	// ErrScriptIndexUndo error when a block disconnected from a script index is
+	// deeper than the ScriptIndexUndoDepth blocks it keeps the undo data of.
+	ErrScriptIndexUndo = errors.New("no undo data for block")
+)
+
+// ScriptIndexUndoDepth the number of blocks below its tip, inclusive, a script index
+// store held in memory keeps the undo data of, so can disconnect in a reorg.
+const ScriptIndexUndoDepth = 100
+
+// scriptIndexCompactEvery the number of blocks journaled by a FileScriptIndexStore
+// between compactions.
+const scriptIndexCompactEvery = 1000
+
+// ScriptIndexStore storage for a ScriptIndexer, holding the outputs it indexes by
+// script hash. Blocks are connected to and disconnected from the store's tip, each
+// atomically, the store ignoring spends of outputs it does not hold. A store must be
+// safe for concurrent use, being queried whilst the indexer runs.
+type ScriptIndexStore interface {
+	// Tip the hash and height of the last block connected, or an empty hash should
+	// none have been.
+	Tip(ctx context.Context) (string, uint32, error)
+	// Output the output at the outpoint, or nil should it not be held.
+	Output(ctx context.Context, op models.Outpoint) (*models.ScriptOutput, error)
+	// Outputs the outputs with the script hash, spent or not, in any order.
+	Outputs(ctx context.Context, scriptHash string) ([]*models.ScriptOutput, error)
+	// ConnectBlock connect the block to the tip, keeping it as undo data with
+	// which to disconnect the block later.
+	ConnectBlock(ctx context.Context, b *models.ScriptIndexBlock) error
+	// DisconnectBlock disconnect the tip, with hash, using the undo data kept when
+	// it was connected, the block itself being stale so possibly unavailable from
+	// the node.
+	DisconnectBlock(ctx context.Context, hash string) error
+}
+
+// BoundedScriptIndexStore a ScriptIndexStore which may be able to hold only a bounded
+// index, such as one held in memory. A ScriptIndexer refuses to run with a store
+// reporting itself Bounded unless the index is bounded with IndexerFilter or
+// IndexerStart. Stores kept on disk, able to index every output of the chain, need
+// not implement it.
+type BoundedScriptIndexStore interface {
+	ScriptIndexStore
+	// Bounded reports whether the store can hold only a bounded index.
+	Bounded() bool
+}
+
+// MemoryScriptIndexStore a ScriptIndexStore held in memory, keeping the undo data of
+// the last ScriptIndexUndoDepth blocks connected.
+type MemoryScriptIndexStore struct {
+	mu        sync.RWMutex
+	tipHash   string
+	tipHeight uint32
+	outputs   map[models.Outpoint]*models.ScriptOutput
+	scripts   map[string]map[models.Outpoint]struct{}
+	undo      []*models.ScriptIndexBlock
+}
+
+// NewMemoryScriptIndexStore returns an empty in-memory script index store.
+func NewMemoryScriptIndexStore() *MemoryScriptIndexStore {
+	return &MemoryScriptIndexStore{
+		outputs: make(map[models.Outpoint]*models.ScriptOutput),
+		scripts: make(map[string]map[models.Outpoint]struct{}),
+	}
+}
+
+// Tip the hash and height of the last block connected.
+func (s *MemoryScriptIndexStore) Tip(ctx context.Context) (string, uint32, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.tipHash, s.tipHeight, nil
+}
+
+// Bounded reports true, the whole index being held in memory.
+func (s *MemoryScriptIndexStore) Bounded() bool {
+	return true
+}
+
+// Output the output at the outpoint, or nil should it not be held.
+func (s *MemoryScriptIndexStore) Output(ctx context.Context, op models.Outpoint) (*models.ScriptOutput, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	out, ok := s.outputs[op]
+	if !ok {
+		return nil, nil
+	}
+	o := *out
+	return &o, nil
+}
+
+// Outputs the outputs with the script hash.
+func (s *MemoryScriptIndexStore) Outputs(ctx context.Context, scriptHash string) ([]*models.ScriptOutput, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	outs := make([]*models.ScriptOutput, 0, len(s.scripts[scriptHash]))
+	for op := range s.scripts[scriptHash] {
+		o := *s.outputs[op]
+		outs = append(outs, &o)
+	}
+	return outs, nil
+}
+
+// ConnectBlock add the outputs the block creates and mark those it spends spent.
+func (s *MemoryScriptIndexStore) ConnectBlock(ctx context.Context, b *models.ScriptIndexBlock) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.checkConnect(b); err != nil {
+		return err
+	}
+	s.connect(b)
+	return nil
+}
+
+// DisconnectBlock remove the outputs the tip created and mark those it spent unspent.
+func (s *MemoryScriptIndexStore) DisconnectBlock(ctx context.Context, hash string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.checkDisconnect(hash); err != nil {
+		return err
+	}
+	s.disconnect()
+	return nil
+}
+
+// checkConnect check the block builds on the tip. The store must be locked.
+func (s *MemoryScriptIndexStore) checkConnect(b *models.ScriptIndexBlock) error {
+	if s.tipHash != "" && b.PrevHash != s.tipHash {
+		return fmt.Errorf("%w: block %s builds on %s, tip is %s", ErrScriptIndexTip, b.Hash, b.PrevHash, s.tipHash)
+	}
+	return nil
+}
+
+// checkDisconnect check the block with hash is the tip, and its undo data is kept.
+// The store must be locked.
+func (s *MemoryScriptIndexStore) checkDisconnect(hash string) error {
+	if hash != s.tipHash {
+		return fmt.Errorf("%w: block %s, tip is %s", ErrScriptIndexTip, hash, s.tipHash)
+	}
+	if len(s.undo) == 0 {
+		return fmt.Errorf("%w %s", ErrScriptIndexUndo, hash)
+	}
+	return nil
+}
+
+// connect the block. The store must be locked.
+func (s *MemoryScriptIndexStore) connect(b *models.ScriptIndexBlock) {
+	for _, out := range b.Outputs {
+		o := *out
+		s.outputs[o.Outpoint] = &o
+		ops, ok := s.scripts[o.ScriptHash]
+		if !ok {
+			ops = make(map[models.Outpoint]struct{})
+			s.scripts[o.ScriptHash] = ops
+		}
+		ops[o.Outpoint] = struct{}{}
+	}
+	for _, sp := range b.Spends {
+		if out, ok := s.outputs[sp.Outpoint]; ok {
+			out.SpentTxID, out.SpentVin, out.SpentHeight = sp.SpentTxID, sp.SpentVin, b.Height
+		}
+	}
+	s.tipHash, s.tipHeight = b.Hash, b.Height
+
+	s.undo = append(s.undo, b)
+	if len(s.undo) > ScriptIndexUndoDepth {
+		s.undo[0] = nil
+		s.undo = s.undo[1:]
+	}
+}
+
+// disconnect the tip, whose undo data is kept. The store must be locked.
+func (s *MemoryScriptIndexStore) disconnect() {
+	b := s.undo[len(s.undo)-1]
+	s.undo[len(s.undo)-1] = nil
+	s.undo = s.undo[:len(s.undo)-1]
+
+	for _, sp := range b.Spends {
+		if out, ok := s.outputs[sp.Outpoint]; ok && out.SpentTxID == sp.SpentTxID {
+			out.SpentTxID, out.SpentVin, out.SpentHeight = "", 0, 0
+		}
+	}
+	for _, out := range b.Outputs {
+		delete(s.outputs, out.Outpoint)
+		if ops, ok := s.scripts[out.ScriptHash]; ok {
+			delete(ops, out.Outpoint)
+			if len(ops) == 0 {
+				delete(s.scripts, out.ScriptHash)
+			}
+		}
+	}
+	s.tipHash, s.tipHeight = b.PrevHash, b.Height-1
+}
+
+// scriptIndexSnapshot the state of a MemoryScriptIndexStore, as written when a
+// FileScriptIndexStore is compacted.
+type scriptIndexSnapshot struct {
+	TipHash   string                     `json:"tiphash"`
+	TipHeight uint32                     `json:"tipheight"`
+	Outputs   []*models.ScriptOutput     `json:"outputs"`
+	Undo      []*models.ScriptIndexBlock `json:"undo"`
+}
+
+// snapshot the store's state. The store must be locked.
+func (s *MemoryScriptIndexStore) snapshot() *scriptIndexSnapshot {
+	ss := &scriptIndexSnapshot{
+		TipHash:   s.tipHash,
+		TipHeight: s.tipHeight,
+		Outputs:   make([]*models.ScriptOutput, 0, len(s.outputs)),
+		Undo:      s.undo,
+	}
+	for _, out := range s.outputs {
+		ss.Outputs = append(ss.Outputs, out)
+	}
+	return ss
+}
+
+// restore the store's state from the snapshot. The store must be locked and empty.
+func (s *MemoryScriptIndexStore) restore(ss *scriptIndexSnapshot) {
+	for _, out := range ss.Outputs {
+		s.outputs[out.Outpoint] = out
+		ops, ok := s.scripts[out.ScriptHash]
+		if !ok {
+			ops = make(map[models.Outpoint]struct{})
+			s.scripts[out.ScriptHash] = ops
+		}
+		ops[out.Outpoint] = struct{}{}
+	}
+	s.tipHash, s.tipHeight, s.undo = ss.TipHash, ss.TipHeight, ss.Undo
+}
+
+// scriptIndexRecord a record of the journal of a FileScriptIndexStore, the first of
+// which may be a snapshot compacting those before it.
+type scriptIndexRecord struct {
+	Snapshot   *scriptIndexSnapshot     `json:"snapshot,omitempty"`
+	Connect    *models.ScriptIndexBlock `json:"connect,omitempty"`
+	Disconnect string                   `json:"disconnect,omitempty"`
+}
+
+// FileScriptIndexStore a ScriptIndexStore held in memory and persisted to a journal
+// file. As the whole index is held in memory, the store suits only an index bounded
+// by IndexerFilter, to the scripts of interest, or by IndexerStart, to recent
+// blocks, reporting itself Bounded so a ScriptIndexer refuses to run with it
+// otherwise; indexing every output of the chain needs a ScriptIndexStore kept on
+// disk, such as one over an embedded database.
+//
+// Each block connected or disconnected is appended to the journal and synced before
+// being applied. Every 1000 blocks, and upon opening, the journal is compacted,
+// replaced by a snapshot of the index, so opening replays only a snapshot and the
+// blocks since. Opening discards a final record left incomplete by a crash.
+type FileScriptIndexStore struct {
+	*MemoryScriptIndexStore
+	path    string
+	f       *os.File
+	enc     *json.Encoder
+	records int
+}
+
+// NewFileScriptIndexStore returns the script index store journaled to the file at
+// path, creating it should it not exist.
+func NewFileScriptIndexStore(path string) (*FileScriptIndexStore, error) {
+	f, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0o600) // nolint:gosec // path given by caller
+	if err != nil {
+		return nil, err
+	}
+	s := &FileScriptIndexStore{MemoryScriptIndexStore: NewMemoryScriptIndexStore(), path: path, f: f}
+	if err = s.replay(); err != nil {
+		_ = s.f.Close()
+		return nil, err
+	}
+	if s.records > 0 {
+		if err = s.compact(); err != nil {
+			_ = s.f.Close()
+			return nil, err
+		}
+	}
+	s.enc = json.NewEncoder(s.f)
+	return s, nil
+}
+
+// ConnectBlock journal the block then add the outputs it creates and mark those it
+// spends spent.
+func (s *FileScriptIndexStore) ConnectBlock(ctx context.Context, b *models.ScriptIndexBlock) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.checkConnect(b); err != nil {
+		return err
+	}
+	if err := s.write(scriptIndexRecord{Connect: b}); err != nil {
+		return err
+	}
+	s.connect(b)
+	return s.maybeCompact()
+}
+
+// DisconnectBlock journal the disconnection of the tip then remove the outputs it
+// created and mark those it spent unspent.
+func (s *FileScriptIndexStore) DisconnectBlock(ctx context.Context, hash string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.checkDisconnect(hash); err != nil {
+		return err
+	}
+	if err := s.write(scriptIndexRecord{Disconnect: hash}); err != nil {
+		return err
+	}
+	s.disconnect()
+	return s.maybeCompact()
+}
+
+// Close the journal file.
+func (s *FileScriptIndexStore) Close() error {
+	return s.f.Close()
+}
+
+// write append the record to the journal and sync it. The store must be locked.
+func (s *FileScriptIndexStore) write(r scriptIndexRecord) error {
+	if err := s.enc.Encode(r); err != nil {
+		return err
+	}
+	if err := s.f.Sync(); err != nil {
+		return err
+	}
+	s.records++
+	return nil
+}
+
+// maybeCompact compact the journal should enough blocks have been journaled since it
+// last was. The store must be locked.
+func (s *FileScriptIndexStore) maybeCompact() error {
+	if s.records < scriptIndexCompactEvery {
+		return nil
+	}
+	if err := s.compact(); err != nil {
+		return err
+	}
+	s.enc = json.NewEncoder(s.f)
+	return nil
+}
+
+// compact replace the journal with a snapshot of the index, written to a temporary
+// file renamed over the journal once synced. The store must be locked.
+func (s *FileScriptIndexStore) compact() error {
+	tmp := s.path + ".tmp"
+	f, err := os.OpenFile(tmp, os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0o600) // nolint:gosec // path given by caller
+	if err != nil {
+		return err
+	}
+	if err = json.NewEncoder(f).Encode(scriptIndexRecord{Snapshot: s.snapshot()}); err == nil {
+		err = f.Sync()
+	}
+	if err == nil {
+		err = os.Rename(tmp, s.path)
+	}
+	if err != nil {
+		_ = f.Close()
+		_ = os.Remove(tmp)
+		return err
+	}
+	_ = s.f.Close()
+	s.f, s.records = f, 0
+	return nil
+}
+
+// replay the journal, truncating an incomplete final record.
+func (s *FileScriptIndexStore) replay() error {
+	dec := json.NewDecoder(s.f)
+	var offset int64
+	for {
+		var r scriptIndexRecord
+		err := dec.Decode(&r)
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if errors.Is(err, io.ErrUnexpectedEOF) {
+			if err = s.f.Truncate(offset); err != nil {
+				return err
+			}
+			break
+		}
+		if err != nil {
+			return fmt.Errorf("script index journal at %d: %w", offset, err)
+		}
+		if err = s.apply(r, offset == 0); err != nil {
+			return fmt.Errorf("script index journal at %d: %w", offset, err)
+		}
+		offset = dec.InputOffset()
+	}
+	_, err := s.f.Seek(0, io.SeekEnd)
+	return err
+}
+
+// apply the journal record, only the first of which may be a snapshot.
+func (s *FileScriptIndexStore) apply(r scriptIndexRecord, first bool) error {
+	switch {
+	case r.Snapshot != nil && first:
+		s.restore(r.Snapshot)
+		return nil
+	case r.Connect != nil:
+		if err := s.checkConnect(r.Connect); err != nil {
+			return err
+		}
+		s.connect(r.Connect)
+	case r.Disconnect != "":
+		if err := s.checkDisconnect(r.Disconnect); err != nil {
+			return err
+		}
+		s.disconnect()
+	default:
+		return errors.New("invalid record")
+	}
+	s.records++
+	return nil
+}
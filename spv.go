@@ -0,0 +1,342 @@
+package bn
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/libsv/go-bc"
+	"github.com/libsv/go-bn/models"
+	"github.com/libsv/go-bt/v2"
+)
+
+// ErrSPVDepthExceeded error when a tx has more generations of unconfirmed ancestors
+// than an SPVBuilder allows.
+var ErrSPVDepthExceeded = errors.New("unconfirmed ancestors exceed max depth")
+
+// beefVersion the BRC-62 BEEF version, 0100BEEF when written little endian.
+const beefVersion uint32 = 4022206465
+
+// BUMP leaf flags, per BRC-74.
+const (
+	bumpLeafHash      byte = 0
+	bumpLeafDuplicate byte = 1
+	bumpLeafTxID      byte = 2
+)
+
+// SPVBuilder assembles a tx with the ancestors its recipient needs to verify it by
+// SPV, walking back through its unconfirmed ancestors, found in the node's mempool,
+// to their confirmed parents, each of which is proven by a merkle proof:
+//
+//	b := bn.NewSPVBuilder(c)
+//	beef, err := b.BEEF(ctx, txID)
+//
+// An ancestor shared by several descendants is fetched and included once.
+type SPVBuilder struct {
+	c        NodeClient
+	maxDepth int
+}
+
+// SPVBuilderOptFunc for setting spv builder options.
+type SPVBuilderOptFunc func(b *SPVBuilder)
+
+// SPVMaxDepth set the number of generations of unconfirmed ancestors a tx may have,
+// beyond which building fails with ErrSPVDepthExceeded. Defaults to 100.
+func SPVMaxDepth(n int) SPVBuilderOptFunc {
+	return func(b *SPVBuilder) {
+		b.maxDepth = n
+	}
+}
+
+// NewSPVBuilder returns a builder of spv envelopes for txs known to the node c is a
+// client of. The node must be able to look up confirmed txs, such as with `-txindex`.
+func NewSPVBuilder(c NodeClient, oo ...SPVBuilderOptFunc) *SPVBuilder {
+	b := &SPVBuilder{c: c, maxDepth: 100}
+	for _, o := range oo {
+		o(b)
+	}
+	return b
+}
+
+// spvTx a tx within an spv graph, either confirmed with a proof or unconfirmed
+// with parents.
+type spvTx struct {
+	tx      *bt.Tx
+	proof   *bc.MerkleProof
+	height  uint64
+	parents []string
+}
+
+// spvGraph a tx and its ancestors, back to its confirmed parents.
+type spvGraph struct {
+	txID string
+	txs  map[string]*spvTx
+}
+
+// Envelope the spv envelope of the tx with txID.
+func (b *SPVBuilder) Envelope(ctx context.Context, txID string) (*models.SPVEnvelope, error) {
+	g, err := b.collect(ctx, txID)
+	if err != nil {
+		return nil, err
+	}
+	return g.envelope(txID, make(map[string]*models.SPVEnvelope)), nil
+}
+
+// BEEF the tx with txID and its ancestors in the BRC-62 Background Evaluation
+// Extended Format, each of its confirmed ancestors being proven by a BRC-74 BUMP
+// shared by those of the same block.
+func (b *SPVBuilder) BEEF(ctx context.Context, txID string) ([]byte, error) {
+	g, err := b.collect(ctx, txID)
+	if err != nil {
+		return nil, err
+	}
+	return g.beef()
+}
+
+// collect walk from the tx back through its unconfirmed ancestors, collecting each
+// and its confirmed parents.
+func (b *SPVBuilder) collect(ctx context.Context, txID string) (*spvGraph, error) {
+	g := &spvGraph{txID: txID, txs: make(map[string]*spvTx)}
+	unconfirmed, err := b.unconfirmed(ctx, txID)
+	if err != nil {
+		return nil, err
+	}
+	if !unconfirmed[txID] {
+		return g, b.addConfirmed(ctx, g, txID)
+	}
+
+	type walk struct {
+		txID  string
+		depth int
+	}
+	queue := []walk{{txID: txID}}
+	for ; len(queue) > 0; queue = queue[1:] {
+		w := queue[0]
+		if _, ok := g.txs[w.txID]; ok {
+			continue
+		}
+		if w.depth > b.maxDepth {
+			return nil, fmt.Errorf("%w of %d", ErrSPVDepthExceeded, b.maxDepth)
+		}
+
+		tx, err := b.c.RawTransaction(ctx, w.txID)
+		if err != nil {
+			return nil, err
+		}
+		t := &spvTx{tx: tx}
+		g.txs[w.txID] = t
+		for _, in := range tx.Inputs {
+			parent := in.PreviousTxIDStr()
+			if contains(t.parents, parent) {
+				continue
+			}
+			t.parents = append(t.parents, parent)
+			if unconfirmed[parent] {
+				queue = append(queue, walk{txID: parent, depth: w.depth + 1})
+				continue
+			}
+			if err = b.addConfirmed(ctx, g, parent); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return g, nil
+}
+
+// unconfirmed the txids of the tx and its ancestors should it be in the mempool.
+func (b *SPVBuilder) unconfirmed(ctx context.Context, txID string) (map[string]bool, error) {
+	txIDs := make(map[string]bool)
+	if _, err := b.c.MempoolEntry(ctx, txID); err != nil {
+		if errors.Is(err, models.ErrNotFound) {
+			return txIDs, nil
+		}
+		return nil, err
+	}
+
+	ancestors, err := b.c.MempoolAncestorIDs(ctx, txID)
+	if err != nil {
+		return nil, err
+	}
+	txIDs[txID] = true
+	for _, id := range ancestors {
+		txIDs[id] = true
+	}
+	return txIDs, nil
+}
+
+// addConfirmed add the confirmed tx with txID and its proof to the graph.
+func (b *SPVBuilder) addConfirmed(ctx context.Context, g *spvGraph, txID string) error {
+	if _, ok := g.txs[txID]; ok {
+		return nil
+	}
+	tx, err := b.c.RawTransaction(ctx, txID)
+	if err != nil {
+		return err
+	}
+	proof, err := b.c.MerkleProof(ctx, "", txID, nil)
+	if err != nil {
+		return err
+	}
+	header, err := b.c.BlockHeader(ctx, proof.Target)
+	if err != nil {
+		return err
+	}
+	g.txs[txID] = &spvTx{tx: tx, proof: proof, height: header.Height}
+	return nil
+}
+
+// envelope the envelope of the tx with txID, sharing those of common ancestors.
+func (g *spvGraph) envelope(txID string, seen map[string]*models.SPVEnvelope) *models.SPVEnvelope {
+	if e, ok := seen[txID]; ok {
+		return e
+	}
+	t := g.txs[txID]
+	e := &models.SPVEnvelope{
+		TxID:  txID,
+		RawTx: t.tx.String(),
+		Proof: t.proof,
+	}
+	seen[txID] = e
+	if t.proof == nil {
+		e.Parents = make(map[string]*models.SPVEnvelope, len(t.parents))
+		for _, p := range t.parents {
+			e.Parents[p] = g.envelope(p, seen)
+		}
+	}
+	return e
+}
+
+// order the txids of the graph, each after its parents.
+func (g *spvGraph) order() []string {
+	var txIDs []string
+	seen := make(map[string]bool)
+	var visit func(txID string)
+	visit = func(txID string) {
+		if seen[txID] {
+			return
+		}
+		seen[txID] = true
+		for _, p := range g.txs[txID].parents {
+			visit(p)
+		}
+		txIDs = append(txIDs, txID)
+	}
+	visit(g.txID)
+	return txIDs
+}
+
+func (g *spvGraph) beef() ([]byte, error) {
+	txIDs := g.order()
+	var bumps []*bump
+	index := make(map[string]int)
+	for _, txID := range txIDs {
+		t := g.txs[txID]
+		if t.proof == nil {
+			continue
+		}
+		i, ok := index[t.proof.Target]
+		if !ok {
+			i = len(bumps)
+			index[t.proof.Target] = i
+			bumps = append(bumps, &bump{height: t.height})
+		}
+		if err := bumps[i].add(t.proof, txID); err != nil {
+			return nil, err
+		}
+	}
+
+	var buf bytes.Buffer
+	_ = binary.Write(&buf, binary.LittleEndian, beefVersion)
+	buf.Write(bt.VarInt(uint64(len(bumps))).Bytes())
+	for _, b := range bumps {
+		buf.Write(b.bytes())
+	}
+	buf.Write(bt.VarInt(uint64(len(txIDs))).Bytes())
+	for _, txID := range txIDs {
+		t := g.txs[txID]
+		buf.Write(t.tx.Bytes())
+		if t.proof == nil {
+			buf.WriteByte(0)
+			continue
+		}
+		buf.WriteByte(1)
+		buf.Write(bt.VarInt(uint64(index[t.proof.Target])).Bytes())
+	}
+	return buf.Bytes(), nil
+}
+
+// bump a BRC-74 BSV Unified Merkle Path, proving the txs of one block.
+type bump struct {
+	height uint64
+	levels []map[uint64]bumpLeaf
+}
+
+type bumpLeaf struct {
+	flags byte
+	hash  []byte
+}
+
+// add the path of the proof of the tx with txID. A block of just the one tx has
+// a single level, holding the tx.
+func (b *bump) add(proof *bc.MerkleProof, txID string) error {
+	for len(b.levels) < len(proof.Nodes) || len(b.levels) == 0 {
+		b.levels = append(b.levels, make(map[uint64]bumpLeaf))
+	}
+	hash, err := hex.DecodeString(txID)
+	if err != nil {
+		return err
+	}
+	b.levels[0][proof.Index] = bumpLeaf{flags: bumpLeafTxID, hash: bt.ReverseBytes(hash)}
+
+	for i, node := range proof.Nodes {
+		offset := (proof.Index >> uint(i)) ^ 1
+		if _, ok := b.levels[i][offset]; ok {
+			continue
+		}
+		if node == "*" {
+			b.levels[i][offset] = bumpLeaf{flags: bumpLeafDuplicate}
+			continue
+		}
+		hash, err := hex.DecodeString(node)
+		if err != nil {
+			return err
+		}
+		b.levels[i][offset] = bumpLeaf{flags: bumpLeafHash, hash: bt.ReverseBytes(hash)}
+	}
+	return nil
+}
+
+func (b *bump) bytes() []byte {
+	var buf bytes.Buffer
+	buf.Write(bt.VarInt(b.height).Bytes())
+	buf.WriteByte(byte(len(b.levels)))
+	for _, level := range b.levels {
+		offsets := make([]uint64, 0, len(level))
+		for offset := range level {
+			offsets = append(offsets, offset)
+		}
+		sort.Slice(offsets, func(i, j int) bool { return offsets[i] < offsets[j] })
+
+		buf.Write(bt.VarInt(uint64(len(offsets))).Bytes())
+		for _, offset := range offsets {
+			leaf := level[offset]
+			buf.Write(bt.VarInt(offset).Bytes())
+			buf.WriteByte(leaf.flags)
+			buf.Write(leaf.hash)
+		}
+	}
+	return buf.Bytes()
+}
+
+func contains(ss []string, s string) bool {
+	for _, v := range ss {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
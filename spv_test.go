@@ -0,0 +1,293 @@
+package bn_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"errors"
+	"testing"
+
+	"github.com/libsv/go-bc"
+	"github.com/libsv/go-bn"
+	bnmocks "github.com/libsv/go-bn/mocks"
+	"github.com/libsv/go-bn/models"
+	"github.com/libsv/go-bt/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+// spvChain txs across two blocks and the mempool:
+//
+//	block 100: cbA, p1, p2
+//	block 101: cbB, p3
+//	mempool:   u1 (p1, p2), u2 (u1, p1), tx (u1, u2, p3)
+type spvChain struct {
+	txs     map[string]*bt.Tx
+	names   map[string]string
+	mempool map[string][]string
+	proofs  map[string]*bc.MerkleProof
+	blocks  map[string]uint64
+	roots   map[uint64]string
+	calls   map[string]int
+}
+
+func newSPVChain(t *testing.T) *spvChain {
+	c := &spvChain{
+		txs:     make(map[string]*bt.Tx),
+		names:   make(map[string]string),
+		mempool: make(map[string][]string),
+		proofs:  make(map[string]*bc.MerkleProof),
+		blocks:  make(map[string]uint64),
+		roots:   make(map[uint64]string),
+		calls:   make(map[string]int),
+	}
+	cbA := c.tx(t, "cbA")
+	p1 := c.tx(t, "p1", cbA+":0")
+	p2 := c.tx(t, "p2", cbA+":1")
+	c.block(t, 100, cbA, p1, p2)
+	cbB := c.tx(t, "cbB")
+	p3 := c.tx(t, "p3", cbB+":0")
+	c.block(t, 101, cbB, p3)
+
+	u1 := c.tx(t, "u1", p1+":0", p2+":0")
+	u2 := c.tx(t, "u2", u1+":0", p1+":1")
+	tx := c.tx(t, "tx", u1+":1", u2+":0", p3+":0")
+	c.mempool[u1] = []string{}
+	c.mempool[u2] = []string{u1}
+	c.mempool[tx] = []string{u1, u2}
+	return c
+}
+
+// tx add a tx named name, spending the outpoints, formatted txid:vout.
+func (c *spvChain) tx(t *testing.T, name string, outpoints ...string) string {
+	tx := bt.NewTx()
+	for _, op := range outpoints {
+		err := tx.From(op[:64], uint32(op[65]-'0'), "76a914316230517501a16e2837465ec28c157fa61cabec88ac", 1000)
+		assert.NoError(t, err)
+	}
+	assert.NoError(t, tx.AddOpReturnOutput([]byte(name)))
+	assert.NoError(t, tx.AddOpReturnOutput([]byte(name)))
+	c.txs[tx.TxID()] = tx
+	c.names[tx.TxID()] = name
+	return tx.TxID()
+}
+
+func (c *spvChain) block(t *testing.T, height uint64, txIDs ...string) {
+	proofs, root := merkleTree(t, txIDs)
+	hash := hex.EncodeToString(bytes.Repeat([]byte{byte(height)}, 32))
+	for i, p := range proofs {
+		p.Target = hash
+		c.proofs[txIDs[i]] = p
+	}
+	c.blocks[hash] = height
+	c.roots[height] = root
+}
+
+func (c *spvChain) client() *bnmocks.NodeClientMock {
+	notFound := &models.Error{Code: models.ErrNotFound.Code, Message: "Transaction not in mempool"}
+	return &bnmocks.NodeClientMock{
+		RawTransactionFunc: func(ctx context.Context, txID string) (*bt.Tx, error) {
+			c.calls["getrawtransaction "+c.names[txID]]++
+			return c.txs[txID], nil
+		},
+		MempoolEntryFunc: func(ctx context.Context, txID string) (*models.MempoolEntry, error) {
+			if _, ok := c.mempool[txID]; !ok {
+				return nil, notFound
+			}
+			return &models.MempoolEntry{}, nil
+		},
+		MempoolAncestorIDsFunc: func(ctx context.Context, txID string) ([]string, error) {
+			return c.mempool[txID], nil
+		},
+		MerkleProofFunc: func(ctx context.Context, blockHash, txID string,
+			opts *models.OptsMerkleProof) (*bc.MerkleProof, error) {
+			c.calls["getmerkleproof2 "+c.names[txID]]++
+			return c.proofs[txID], nil
+		},
+		BlockHeaderFunc: func(ctx context.Context, hash string) (*models.BlockHeader, error) {
+			return &models.BlockHeader{Hash: hash, Height: c.blocks[hash]}, nil
+		},
+	}
+}
+
+func (c *spvChain) hash(name string) string {
+	for txID, n := range c.names {
+		if n == name {
+			return txID
+		}
+	}
+	return ""
+}
+
+func TestSPVBuilder_Envelope(t *testing.T) {
+	t.Parallel()
+	chain := newSPVChain(t)
+	env, err := bn.NewSPVBuilder(chain.client()).Envelope(context.Background(), chain.hash("tx"))
+	assert.NoError(t, err)
+
+	u1, u2, p1, p3 := chain.hash("u1"), chain.hash("u2"), chain.hash("p1"), chain.hash("p3")
+	assert.Equal(t, chain.txs[chain.hash("tx")].String(), env.RawTx)
+	assert.Nil(t, env.Proof)
+	assert.Len(t, env.Parents, 3)
+	assert.Same(t, env.Parents[u1], env.Parents[u2].Parents[u1])
+	assert.Same(t, env.Parents[u1].Parents[p1], env.Parents[u2].Parents[p1])
+	assert.Equal(t, chain.proofs[p1], env.Parents[u1].Parents[p1].Proof)
+	assert.Nil(t, env.Parents[u1].Parents[p1].Parents)
+	assert.Equal(t, chain.proofs[p3], env.Parents[p3].Proof)
+
+	for name, n := range chain.calls {
+		assert.Equal(t, 1, n, name)
+	}
+	assert.Len(t, chain.calls, 9)
+}
+
+func TestSPVBuilder_BEEF(t *testing.T) {
+	t.Parallel()
+	tests := map[string]struct {
+		txID      string
+		expTxs    []string
+		expBUMPs  []uint64
+		expProven map[string]int
+	}{
+		"unconfirmed tx": {
+			txID:     "tx",
+			expTxs:   []string{"p1", "p2", "u1", "u2", "p3", "tx"},
+			expBUMPs: []uint64{100, 101},
+			expProven: map[string]int{
+				"p1": 0,
+				"p2": 0,
+				"p3": 1,
+			},
+		},
+		"confirmed tx": {
+			txID:      "p3",
+			expTxs:    []string{"p3"},
+			expBUMPs:  []uint64{101},
+			expProven: map[string]int{"p3": 0},
+		},
+	}
+
+	for name, test := range tests {
+		test := test
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			chain := newSPVChain(t)
+			beef, err := bn.NewSPVBuilder(chain.client()).BEEF(context.Background(), chain.hash(test.txID))
+			assert.NoError(t, err)
+
+			bumps, txs, bumpOf := readBEEF(t, beef)
+			var names []string
+			for i, tx := range txs {
+				names = append(names, chain.names[tx.TxID()])
+				if bumpOf[i] < 0 {
+					continue
+				}
+				assert.Equal(t, test.expProven[chain.names[tx.TxID()]], bumpOf[i])
+				b := bumps[bumpOf[i]]
+				assert.Equal(t, chain.roots[b.height], b.root(t, tx.TxID()))
+			}
+			assert.Equal(t, test.expTxs, names)
+
+			var heights []uint64
+			for _, b := range bumps {
+				heights = append(heights, b.height)
+			}
+			assert.Equal(t, test.expBUMPs, heights)
+		})
+	}
+}
+
+func TestSPVBuilder_MaxDepth(t *testing.T) {
+	t.Parallel()
+	chain := newSPVChain(t)
+	_, err := bn.NewSPVBuilder(chain.client(), bn.SPVMaxDepth(1)).BEEF(context.Background(), chain.hash("tx"))
+	assert.NoError(t, err)
+	_, err = bn.NewSPVBuilder(chain.client(), bn.SPVMaxDepth(0)).BEEF(context.Background(), chain.hash("tx"))
+	assert.True(t, errors.Is(err, bn.ErrSPVDepthExceeded))
+}
+
+type testBUMP struct {
+	height uint64
+	levels []map[uint64][]byte
+}
+
+// root the merkle root computed from the bump's path of the tx with txID.
+func (b *testBUMP) root(t *testing.T, txID string) string {
+	var offset uint64
+	for o, hash := range b.levels[0] {
+		if hex.EncodeToString(bt.ReverseBytes(hash)) == txID {
+			offset = o
+		}
+	}
+	root := txID
+	for i, level := range b.levels {
+		sibling, ok := level[(offset>>uint(i))^1]
+		assert.True(t, ok, "level %d has no sibling", i)
+		node := root
+		if sibling != nil {
+			node = hex.EncodeToString(bt.ReverseBytes(sibling))
+		}
+		var err error
+		if (offset>>uint(i))%2 == 0 {
+			root, err = bc.MerkleTreeParentStr(root, node)
+		} else {
+			root, err = bc.MerkleTreeParentStr(node, root)
+		}
+		assert.NoError(t, err)
+	}
+	return root
+}
+
+// readBEEF read the bumps and txs of the BEEF, with the index of each tx's bump, or
+// -1 should it have none.
+func readBEEF(t *testing.T, beef []byte) ([]*testBUMP, []*bt.Tx, []int) {
+	// The version, 4022206465, written little endian.
+	assert.Equal(t, []byte{0x01, 0x00, 0xbe, 0xef}, beef[:4])
+	r := bytes.NewReader(beef[4:])
+	varInt := func() uint64 {
+		var n bt.VarInt
+		_, err := n.ReadFrom(r)
+		assert.NoError(t, err)
+		return uint64(n)
+	}
+	readByte := func() byte {
+		b, err := r.ReadByte()
+		assert.NoError(t, err)
+		return b
+	}
+
+	bumps := make([]*testBUMP, varInt())
+	for i := range bumps {
+		b := &testBUMP{height: varInt()}
+		b.levels = make([]map[uint64][]byte, readByte())
+		for l := range b.levels {
+			b.levels[l] = make(map[uint64][]byte)
+			for n := varInt(); n > 0; n-- {
+				offset := varInt()
+				b.levels[l][offset] = nil
+				if readByte() != 1 {
+					hash := make([]byte, 32)
+					_, err := r.Read(hash)
+					assert.NoError(t, err)
+					b.levels[l][offset] = hash
+				}
+			}
+		}
+		bumps[i] = b
+	}
+
+	txs := make([]*bt.Tx, varInt())
+	bumpOf := make([]int, len(txs))
+	for i := range txs {
+		rest := beef[len(beef)-r.Len():]
+		tx, size, err := bt.NewTxFromStream(rest)
+		assert.NoError(t, err)
+		_, _ = r.Seek(int64(size), 1)
+		txs[i] = tx
+		bumpOf[i] = -1
+		if readByte() == 1 {
+			bumpOf[i] = int(varInt())
+		}
+	}
+	assert.Zero(t, r.Len())
+	return bumps, txs, bumpOf
+}
@@ -21,7 +21,6 @@ func NewUtilClient(oo ...BitcoinClientOptFunc) UtilClient {
 	return NewNodeClient(oo...)
 }
 
-// TODO: should not be cached
 func (c *client) ClearInvalidTransactions(ctx context.Context) (uint64, error) {
 	var resp uint64
 	return resp, c.rpc.Do(ctx, "clearinvalidtransactions", &resp)
@@ -7,6 +7,7 @@ import (
 	imodels "github.com/libsv/go-bn/internal/models"
 	"github.com/libsv/go-bn/internal/util"
 	"github.com/libsv/go-bn/models"
+	"github.com/libsv/go-bn/rpc"
 	"github.com/libsv/go-bt/v2"
 )
 
@@ -56,6 +57,11 @@ type WalletClient interface {
 	WalletPhassphrase(ctx context.Context, passphrase string, timeout int) error
 	WalletPhassphraseChange(ctx context.Context, oldPassphrase, newPassphrase string) error
 	WalletLock(ctx context.Context) error
+
+	// ForWallet returns a client whose calls are served by the named wallet, for
+	// nodes with several wallets loaded. It shares this client's connection, pool
+	// and cache, with cached results kept apart per wallet.
+	ForWallet(name string) WalletClient
 }
 
 // NewWalletClient returns a client only capable of interfacing with the wallet sub commands on a bitcoin node.
@@ -63,6 +69,36 @@ func NewWalletClient(oo ...BitcoinClientOptFunc) WalletClient {
 	return NewNodeClient(oo...)
 }
 
+func (c *client) ForWallet(name string) WalletClient {
+	r := c.rpc
+	if w, ok := r.(*walletRPC); ok {
+		r = w.rpc
+	}
+	return &client{
+		rpc:       &walletRPC{rpc: r, name: name},
+		cache:     c.cache,
+		isMainnet: c.isMainnet,
+	}
+}
+
+// walletRPC sends each call to the wallet name.
+type walletRPC struct {
+	rpc  rpc.RPC
+	name string
+}
+
+func (w *walletRPC) Do(ctx context.Context, method string, out interface{}, args ...interface{}) error {
+	return w.rpc.Do(rpc.WithWallet(ctx, w.name), method, out, args...)
+}
+
+func (w *walletRPC) DoBatch(ctx context.Context, calls ...*rpc.Call) error {
+	return rpc.DoBatch(rpc.WithWallet(ctx, w.name), w.rpc, calls...)
+}
+
+func (w *walletRPC) DoStream(ctx context.Context, method string, fn rpc.StreamFunc, args ...interface{}) error {
+	return rpc.DoStream(rpc.WithWallet(ctx, w.name), w.rpc, method, fn, args...)
+}
+
 func (c *client) AbandonTransaction(ctx context.Context, txID string) error {
 	return c.rpc.Do(ctx, "abandontransaction", nil, txID)
 }
@@ -81,7 +117,6 @@ func (c *client) DumpPrivateKey(ctx context.Context, address string) (*wif.WIF,
 	return resp.WIF, c.rpc.Do(ctx, "dumpprivkey", &resp, address)
 }
 
-// TODO: do not cache
 func (c *client) DumpWallet(ctx context.Context, dest string) (*models.DumpWallet, error) {
 	var resp models.DumpWallet
 	return &resp, c.rpc.Do(ctx, "dumpwallet", &resp, dest)
@@ -102,33 +137,28 @@ func (c *client) AccountAddresses(ctx context.Context, account string) ([]string
 	return resp, c.rpc.Do(ctx, "getaddressesbyaccount", &resp, account)
 }
 
-// TODO: do not cache
 func (c *client) Balance(ctx context.Context, opts *models.OptsBalance) (uint64, error) {
 	var resp float64
 	err := c.rpc.Do(ctx, "getbalance", &resp, c.argsFor(opts)...)
 	return util.BSVToSatoshis(resp), err
 }
 
-// TODO: do not cache
 func (c *client) UnconfirmedBalance(ctx context.Context) (uint64, error) {
 	var resp float64
 	err := c.rpc.Do(ctx, "getunconfirmedbalance", &resp)
 	return util.BSVToSatoshis(resp), err
 }
 
-// TODO: do not cache
 func (c *client) NewAddress(ctx context.Context, opts *models.OptsNewAddress) (string, error) {
 	var resp string
 	return resp, c.rpc.Do(ctx, "getnewaddress", &resp, c.argsFor(opts)...)
 }
 
-// TODO: do not cache
 func (c *client) RawChangeAddress(ctx context.Context) (string, error) {
 	var resp string
 	return resp, c.rpc.Do(ctx, "getrawchangeaddress", &resp)
 }
 
-// TODO: do not cache
 func (c *client) ReceivedByAddress(ctx context.Context, address string) (uint64, error) {
 	var resp float64
 	err := c.rpc.Do(ctx, "getreceivedbyaddress", &resp, address)
@@ -163,7 +193,7 @@ func (c *client) ImportPublicKey(ctx context.Context, publicKey string, opts *mo
 	return c.rpc.Do(ctx, "importpubkey", nil, c.argsFor(opts, publicKey)...)
 }
 
-// TODO: don't cache. test.
+// TODO: test.
 func (c *client) ImportPrivateKey(ctx context.Context, w *wif.WIF, opts *models.OptsImportPrivateKey) error {
 	return c.rpc.Do(ctx, "importprivkey", nil, c.argsFor(opts, w.String())...)
 }
@@ -220,7 +250,6 @@ func (c *client) ListWallets(ctx context.Context) ([]string, error) {
 	return resp, c.rpc.Do(ctx, "listwallets", &resp)
 }
 
-// TODO: do not cache
 func (c *client) LockUnspent(ctx context.Context, lock bool, opts *models.OptsLockUnspent) (bool, error) {
 	var resp bool
 	return resp, c.rpc.Do(ctx, "lockunspent", &resp, c.argsFor(opts, lock)...)
@@ -3,6 +3,7 @@ package bn_test
 import (
 	"context"
 	"net/http"
+	"net/http/httptest"
 	"testing"
 
 	"github.com/libsv/go-bk/wif"
@@ -495,3 +496,53 @@ func TestWalletClient_Move(t *testing.T) {
 		})
 	}
 }
+
+func TestWalletClient_ForWallet(t *testing.T) {
+	t.Parallel()
+	tests := map[string]struct {
+		client  func(c bn.WalletClient) bn.WalletClient
+		expPath string
+	}{
+		"default wallet": {
+			client: func(c bn.WalletClient) bn.WalletClient {
+				return c
+			},
+			expPath: "/",
+		},
+		"named wallet": {
+			client: func(c bn.WalletClient) bn.WalletClient {
+				return c.ForWallet("alice")
+			},
+			expPath: "/wallet/alice",
+		},
+		"name is escaped": {
+			client: func(c bn.WalletClient) bn.WalletClient {
+				return c.ForWallet("alice/bob")
+			},
+			expPath: "/wallet/alice%2Fbob",
+		},
+		"wallet is replaced rather than nested": {
+			client: func(c bn.WalletClient) bn.WalletClient {
+				return c.ForWallet("alice").ForWallet("bob")
+			},
+			expPath: "/wallet/bob",
+		},
+	}
+
+	for name, test := range tests {
+		test := test
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			svr := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				assert.Equal(t, test.expPath, r.URL.EscapedPath())
+				_, _ = w.Write([]byte(`{"result":1.5,"error":null,"id":"go-bn"}`))
+			}))
+			defer svr.Close()
+
+			c := test.client(bn.NewWalletClient(bn.WithHost(svr.URL)))
+			balance, err := c.Balance(context.Background(), nil)
+			assert.NoError(t, err)
+			assert.Equal(t, uint64(150000000), balance)
+		})
+	}
+}
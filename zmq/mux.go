@@ -0,0 +1,217 @@
+package zmq
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"sync"
+
+	"github.com/libsv/go-bc"
+	"github.com/libsv/go-bt/v2"
+)
+
+// muxes the mux of each NodeMQ shared with Shared, held only whilst it has
+// subscriptions, so a NodeMQ and its subscribers are not kept alive once all have
+// unsubscribed.
+var (
+	muxesMu sync.Mutex
+	muxes   = make(map[NodeMQ]*mux)
+)
+
+// mux fans the messages of each topic of a NodeMQ, which allows but one subscription
+// per topic, out to every subscriber subscribed to it.
+type mux struct {
+	mq NodeMQ
+
+	mu   sync.RWMutex
+	subs map[Topic]map[*subscriber]MessageFunc
+}
+
+// Shared returns a NodeMQ subscribing through mq alongside every other returned by
+// Shared for the same mq, so many subscribers may share a topic. Its Unsubscribe
+// removes only its own subscription, mq being unsubscribed from a topic once none
+// are left. Connect connects mq.
+//
+// Topics subscribed to through Shared must not also be subscribed to on mq directly.
+func Shared(mq NodeMQ) NodeMQ {
+	if s, ok := mq.(*subscriber); ok {
+		return &subscriber{mq: s.mq}
+	}
+	return &subscriber{mq: mq}
+}
+
+// subscribe the subscriber to the topic through the mux of mq, registering the mux
+// should mq have none.
+func subscribe(s *subscriber, topic Topic, fn MessageFunc) error {
+	muxesMu.Lock()
+	defer muxesMu.Unlock()
+	m, ok := muxes[s.mq]
+	if !ok {
+		m = &mux{mq: s.mq, subs: make(map[Topic]map[*subscriber]MessageFunc)}
+	}
+	if err := m.subscribe(s, topic, fn); err != nil {
+		return err
+	}
+	muxes[s.mq] = m
+	return nil
+}
+
+// unsubscribe the subscriber from the topic, releasing the mux of mq once it has no
+// subscriptions left.
+func unsubscribe(s *subscriber, topic Topic) error {
+	muxesMu.Lock()
+	defer muxesMu.Unlock()
+	m, ok := muxes[s.mq]
+	if !ok {
+		return nil
+	}
+	err := m.unsubscribe(s, topic)
+	if m.empty() {
+		delete(muxes, s.mq)
+	}
+	return err
+}
+
+func (m *mux) subscribe(s *subscriber, topic Topic, fn MessageFunc) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	subs, ok := m.subs[topic]
+	if !ok {
+		if err := m.mq.Subscribe(topic, func(ctx context.Context, bb [][]byte) {
+			m.dispatch(ctx, topic, bb)
+		}); err != nil {
+			return err
+		}
+		subs = make(map[*subscriber]MessageFunc)
+		m.subs[topic] = subs
+	}
+	subs[s] = fn
+	return nil
+}
+
+func (m *mux) unsubscribe(s *subscriber, topic Topic) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	subs, ok := m.subs[topic]
+	if !ok {
+		return nil
+	}
+	if _, ok = subs[s]; !ok {
+		return nil
+	}
+	delete(subs, s)
+	if len(subs) > 0 {
+		return nil
+	}
+	delete(m.subs, topic)
+	return m.mq.Unsubscribe(topic)
+}
+
+func (m *mux) empty() bool {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return len(m.subs) == 0
+}
+
+// dispatch the message to each subscriber of the topic.
+func (m *mux) dispatch(ctx context.Context, topic Topic, bb [][]byte) {
+	m.mu.RLock()
+	fns := make([]MessageFunc, 0, len(m.subs[topic]))
+	for _, fn := range m.subs[topic] {
+		fns = append(fns, fn)
+	}
+	m.mu.RUnlock()
+	for _, fn := range fns {
+		fn(ctx, bb)
+	}
+}
+
+// subscriber a NodeMQ subscribing through the mux of mq.
+type subscriber struct {
+	mq NodeMQ
+}
+
+// Connect the shared NodeMQ.
+func (s *subscriber) Connect() error {
+	return s.mq.Connect()
+}
+
+// Subscribe to a topic alongside the other subscribers sharing the NodeMQ. Subscribing
+// again to the topic replaces the subscriber's previous subscription.
+func (s *subscriber) Subscribe(topic Topic, fn MessageFunc) error {
+	return subscribe(s, topic, fn)
+}
+
+// SubscribeHashTx subscribe to `hashtx` and receive its messages parsed.
+func (s *subscriber) SubscribeHashTx(fn HashFunc) error {
+	return s.Subscribe(TopicHashTx, func(ctx context.Context, bb [][]byte) {
+		fn(ctx, hex.EncodeToString(bb[1]))
+	})
+}
+
+// SubscribeHashBlock subscribe to `hashblock` and receive its messages parsed.
+func (s *subscriber) SubscribeHashBlock(fn HashFunc) error {
+	return s.Subscribe(TopicHashBlock, func(ctx context.Context, bb [][]byte) {
+		fn(ctx, hex.EncodeToString(bb[1]))
+	})
+}
+
+// SubscribeDiscardFromMempool subscribe to `discardfrommempool` and receive its messages parsed.
+func (s *subscriber) SubscribeDiscardFromMempool(fn DiscardFunc) error {
+	return s.Subscribe(TopicDiscardFromMempool, s.discard(fn))
+}
+
+// SubscribeRemovedFromMempoolBlock subscribe to `removedfrommempoolblock` and receive its messages parsed.
+func (s *subscriber) SubscribeRemovedFromMempoolBlock(fn DiscardFunc) error {
+	return s.Subscribe(TopicRemovedFromMempoolBlock, s.discard(fn))
+}
+
+// SubscribeRawTx subscribe to `rawtx` and receive its messages parsed.
+func (s *subscriber) SubscribeRawTx(fn RawTxFunc) error {
+	return s.Subscribe(TopicRawTx, func(ctx context.Context, bb [][]byte) {
+		tx, err := bt.NewTxFromBytes(bb[1])
+		if err != nil {
+			s.onErr(ctx, err)
+			return
+		}
+		fn(ctx, tx)
+	})
+}
+
+// SubscribeRawBlock subscribe to `rawblock` and receive its messages parsed.
+func (s *subscriber) SubscribeRawBlock(fn RawBlockFunc) error {
+	return s.Subscribe(TopicRawBlock, func(ctx context.Context, bb [][]byte) {
+		blk, err := bc.NewBlockFromBytes(bb[1])
+		if err != nil {
+			s.onErr(ctx, err)
+			return
+		}
+		fn(ctx, blk)
+	})
+}
+
+// Unsubscribe remove the subscriber's subscription to the topic, leaving those of
+// the other subscribers sharing the NodeMQ.
+func (s *subscriber) Unsubscribe(topic Topic) error {
+	return unsubscribe(s, topic)
+}
+
+func (s *subscriber) discard(fn DiscardFunc) MessageFunc {
+	return func(ctx context.Context, bb [][]byte) {
+		var d MempoolDiscard
+		if err := json.Unmarshal(bb[1], &d); err != nil {
+			s.onErr(ctx, err)
+			return
+		}
+		fn(ctx, &d)
+	}
+}
+
+// onErr report a message failing to parse to the error handler of mq.
+func (s *subscriber) onErr(ctx context.Context, err error) {
+	if n, ok := s.mq.(*nodeMq); ok {
+		n.onErrFn(ctx, err)
+		return
+	}
+	defaultOnError(ctx, err)
+}
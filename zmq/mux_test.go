@@ -0,0 +1,113 @@
+package zmq_test
+
+import (
+	"context"
+	"encoding/hex"
+	"runtime"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/libsv/go-bn/mocks"
+	"github.com/libsv/go-bn/zmq"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestShared(t *testing.T) {
+	t.Parallel()
+
+	var mu sync.Mutex
+	subs := make(map[zmq.Topic]zmq.MessageFunc)
+	var unsubscribed []zmq.Topic
+	mq := &mocks.NodeMQMock{
+		SubscribeFunc: func(topic zmq.Topic, fn zmq.MessageFunc) error {
+			mu.Lock()
+			defer mu.Unlock()
+			if _, ok := subs[topic]; ok {
+				return zmq.ErrAlreadySubscribed
+			}
+			subs[topic] = fn
+			return nil
+		},
+		UnsubscribeFunc: func(topic zmq.Topic) error {
+			mu.Lock()
+			defer mu.Unlock()
+			delete(subs, topic)
+			unsubscribed = append(unsubscribed, topic)
+			return nil
+		},
+	}
+	publish := func(topic zmq.Topic, body []byte) {
+		mu.Lock()
+		fn := subs[topic]
+		mu.Unlock()
+		fn(context.Background(), [][]byte{[]byte(topic), body})
+	}
+
+	var got []string
+	a, b := zmq.Shared(mq), zmq.Shared(mq)
+	assert.NoError(t, a.SubscribeHashBlock(func(_ context.Context, hash string) {
+		got = append(got, "a:"+hash)
+	}))
+	assert.NoError(t, b.SubscribeHashBlock(func(_ context.Context, hash string) {
+		got = append(got, "b:"+hash)
+	}))
+	assert.NoError(t, zmq.Shared(b).Subscribe(zmq.TopicHashTx, func(context.Context, [][]byte) {}))
+	hash, err := hex.DecodeString("0102")
+	assert.NoError(t, err)
+	publish(zmq.TopicHashBlock, hash)
+	assert.ElementsMatch(t, []string{"a:0102", "b:0102"}, got)
+
+	// Unsubscribing leaves the others' subscriptions, mq being unsubscribed once
+	// none are left.
+	got = nil
+	assert.NoError(t, a.Unsubscribe(zmq.TopicHashBlock))
+	assert.NoError(t, a.Unsubscribe(zmq.TopicHashBlock))
+	assert.Empty(t, unsubscribed)
+	publish(zmq.TopicHashBlock, hash)
+	assert.Equal(t, []string{"b:0102"}, got)
+	assert.NoError(t, b.Unsubscribe(zmq.TopicHashBlock))
+	assert.Equal(t, []zmq.Topic{zmq.TopicHashBlock}, unsubscribed)
+
+	// A topic subscribed to on mq directly cannot be shared.
+	mu.Lock()
+	subs[zmq.TopicInvalidTx] = func(context.Context, [][]byte) {}
+	mu.Unlock()
+	assert.ErrorIs(t, a.Subscribe(zmq.TopicInvalidTx, func(context.Context, [][]byte) {}), zmq.ErrAlreadySubscribed)
+}
+
+func TestShared_Released(t *testing.T) {
+	t.Parallel()
+
+	// Once unsubscribed from every topic, nothing holds mq.
+	released := make(chan struct{})
+	func() {
+		// Held only by mq, so released with it.
+		state := new(int)
+		runtime.SetFinalizer(state, func(*int) {
+			close(released)
+		})
+		mq := &mocks.NodeMQMock{
+			SubscribeFunc: func(topic zmq.Topic, fn zmq.MessageFunc) error {
+				*state++
+				return nil
+			},
+			UnsubscribeFunc: func(topic zmq.Topic) error {
+				return nil
+			},
+		}
+		s := zmq.Shared(mq)
+		assert.NoError(t, s.SubscribeHashBlock(func(context.Context, string) {}))
+		assert.NoError(t, s.Unsubscribe(zmq.TopicHashBlock))
+	}()
+
+	assert.Eventually(t, func() bool {
+		runtime.GC()
+		select {
+		case <-released:
+			return true
+		default:
+			return false
+		}
+	}, time.Second, 10*time.Millisecond)
+}